@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/data"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var (
+	// flagconf is the config flag.
+	flagconf string
+	// flagsteps is the number of migrations to apply or revert.
+	flagsteps int
+	// flagversion is the migration to force.
+	flagversion int64
+	// flagapplied forces the migration as applied or not.
+	flagapplied bool
+)
+
+func init() {
+	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
+	flag.IntVar(&flagsteps, "steps", 0, "number of migrations to apply or revert, up applies all and down reverts one by default")
+	flag.Int64Var(&flagversion, "version", 0, "migration version to force, eg: -version 20230601000000")
+	flag.BoolVar(&flagapplied, "applied", true, "force the migration as applied, or as not applied with -applied=false")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] up|down|status|force\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	logger := log.With(log.NewStdLogger(os.Stdout),
+		"ts", log.DefaultTimestamp,
+	)
+	c := config.New(
+		config.WithSource(
+			file.NewSource(flagconf),
+		),
+	)
+	defer c.Close()
+
+	if err := c.Load(); err != nil {
+		panic(err)
+	}
+
+	var bc conf.Bootstrap
+	if err := c.Scan(&bc); err != nil {
+		panic(err)
+	}
+
+	migrator, cleanup, err := data.NewMigrator(bc.Data, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "up":
+		migrations, err := migrator.Up(ctx, flagsteps)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("applied %d migration(s)\n", len(migrations))
+	case "down":
+		migrations, err := migrator.Down(ctx, flagsteps)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("reverted %d migration(s)\n", len(migrations))
+	case "status":
+		migrations, err := migrator.Status(ctx)
+		if err != nil {
+			panic(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, m := range migrations {
+			appliedAt := "pending"
+			if m.Applied {
+				appliedAt = m.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if m.Dirty {
+				appliedAt += " (dirty)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, m.Name, appliedAt)
+		}
+		w.Flush()
+	case "force":
+		if flagversion == 0 {
+			panic("missing migration version, eg: -version 20230601000000 force")
+		}
+		if err := migrator.Force(ctx, flagversion, flagapplied); err != nil {
+			panic(err)
+		}
+		fmt.Printf("forced migration %d, applied: %t\n", flagversion, flagapplied)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/conf"
 	"github.com/adshao/ordinals-indexer/internal/data"
+	"github.com/adshao/ordinals-indexer/internal/ord"
 	"github.com/adshao/ordinals-indexer/internal/ord/page"
 	"github.com/adshao/ordinals-indexer/internal/server"
 	"github.com/adshao/ordinals-indexer/internal/service"
@@ -24,25 +25,68 @@ import (
 // Injectors from wire.go:
 
 // wireApp init kratos application.
-func wireApp(confServer *conf.Server, confData *conf.Data, ord *conf.Ord, logger log.Logger) (*kratos.App, func(), error) {
-	pageParser := page.NewPageParser(ord)
-	dataData, cleanup, err := data.NewData(confData, logger)
+func wireApp(confServer *conf.Server, confData *conf.Data, confOrd *conf.Ord, logger log.Logger) (*kratos.App, func(), error) {
+	pageParser := page.NewPageParser(confOrd)
+	network, err := data.NewNetwork(confOrd)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataData, cleanup, err := data.NewData(confData, network, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 	collectionRepo := data.NewCollectionRepo(dataData, logger)
 	collectionUsecase := biz.NewCollectionUsecase(collectionRepo, logger)
-	collectionService := service.NewCollectionService(pageParser, collectionUsecase, logger)
+	traitRepo := data.NewTraitRepo(dataData, logger)
 	tokenRepo := data.NewTokenRepo(dataData, logger)
+	traitUsecase := biz.NewTraitUsecase(traitRepo, tokenRepo, logger)
+	collectionService := service.NewCollectionService(pageParser, collectionUsecase, traitUsecase, logger)
 	tokenUsecase := biz.NewTokenUsecase(tokenRepo, logger)
-	tokenService := service.NewTokenService(pageParser, tokenUsecase, logger)
 	inscriptionRepo := data.NewInscriptionRepo(dataData, logger)
 	inscriptionUsecase := biz.NewInscriptionUsecase(inscriptionRepo, logger)
-	inscriptionService := service.NewInscriptionService(pageParser, inscriptionUsecase, logger)
-	grpcServer := server.NewGRPCServer(confServer, collectionService, tokenService, inscriptionService, logger)
-	httpServer := server.NewHTTPServer(confServer, collectionService, tokenService, inscriptionService, logger)
-	app := newApp(logger, grpcServer, httpServer)
+	snapshotRepo := data.NewSnapshotRepo(dataData, logger)
+	snapshotUsecase := biz.NewSnapshotUsecase(snapshotRepo, logger)
+	checkpointRepo := data.NewCheckpointRepo(dataData, logger)
+	checkpointUsecase := biz.NewCheckpointUsecase(checkpointRepo, logger)
+	leaseRepo := data.NewLeaseRepo(dataData, logger)
+	leaderUsecase := biz.NewLeaderUsecase(leaseRepo, logger)
+	syncer, cleanup2, err := ord.NewSyncer(confOrd, dataData, collectionUsecase, inscriptionUsecase, tokenUsecase, traitUsecase, snapshotUsecase, checkpointUsecase, leaderUsecase, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	tokenService := service.NewTokenService(pageParser, tokenUsecase, traitUsecase, collectionUsecase, syncer, logger)
+	contentRepo, err := data.NewContentRepo(confData, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	contentSource := page.NewContentSource(confOrd)
+	contentUsecase := biz.NewContentUsecase(contentRepo, contentSource, logger)
+	inscriptionService := service.NewInscriptionService(pageParser, network, inscriptionUsecase, contentUsecase, logger)
+	adminService := service.NewAdminService(syncer, logger)
+	grpcServer, err := server.NewGRPCServer(confServer, collectionService, tokenService, inscriptionService, adminService, network, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	httpServer, err := server.NewHTTPServer(confServer, collectionService, tokenService, inscriptionService, adminService, network, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	syncServer, err := server.NewSyncServer(confServer, confOrd, syncer, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	app := newApp(logger, grpcServer, httpServer, syncServer)
 	return app, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord"
+)
+
+// runCommand runs a one-off sub command instead of the syncer loop,
+// eg: sync -conf config.yaml reprocess -uid <inscription_uid>
+func runCommand(ctx context.Context, syncer *ord.Syncer, name string, args []string) error {
+	switch name {
+	case "reprocess":
+		return reprocess(ctx, syncer, args)
+	case "traits":
+		return refreshTraits(ctx, syncer, args)
+	case "rollback":
+		return rollback(ctx, syncer, args)
+	case "verify":
+		return verify(ctx, syncer, args)
+	case "snapshot":
+		return snapshot(ctx, syncer, args)
+	case "holders":
+		return holders(ctx, syncer, args)
+	default:
+		return fmt.Errorf("unknown command: %s", name)
+	}
+}
+
+func reprocess(ctx context.Context, syncer *ord.Syncer, args []string) error {
+	fs := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	uid := fs.String("uid", "", "inscription uid to re-process, eg: -uid <txid>i0")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *uid == "" {
+		return fmt.Errorf("missing inscription uid, eg: reprocess -uid <txid>i0")
+	}
+	res, err := syncer.Reprocess(ctx, *uid)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+func refreshTraits(ctx context.Context, syncer *ord.Syncer, args []string) error {
+	fs := flag.NewFlagSet("traits", flag.ExitOnError)
+	tick := fs.String("tick", "", "collection tick to refresh the token traits, eg: -tick ordinals")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *tick == "" {
+		return fmt.Errorf("missing collection tick, eg: traits -tick ordinals")
+	}
+	count, failed, err := syncer.RefreshTraits(ctx, *tick)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("refreshed traits of %d token(s), %d token(s) failed\n", count, failed)
+	return nil
+}
+
+func rollback(ctx context.Context, syncer *ord.Syncer, args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	from := fs.Int64("from", -1, "inscription id to rollback from, inclusive, eg: -from 100000")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from < 0 {
+		return fmt.Errorf("missing inscription id, eg: rollback -from 100000")
+	}
+	if err := syncer.Rollback(ctx, *from); err != nil {
+		return err
+	}
+	fmt.Printf("rolled back protocols %s from inscription %d\n", strings.Join(ord.Protocols(), ", "), *from)
+	return nil
+}
+
+func verify(ctx context.Context, syncer *ord.Syncer, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	refURL := fs.String("ref", "", "api url of the reference indexer, eg: -ref http://127.0.0.1:8000")
+	snapshot := fs.String("snapshot", "", "exported snapshot file of the reference indexer")
+	tick := fs.String("tick", "", "collection tick to verify, all the collections by default")
+	format := fs.String("format", "diff", "report format: diff or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var ref ord.Reference
+	switch {
+	case *refURL != "":
+		ref = ord.NewAPIReference(*refURL)
+	case *snapshot != "":
+		f, err := os.Open(*snapshot)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if ref, err = ord.NewSnapshotReference(f); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("missing reference, eg: verify -ref http://127.0.0.1:8000 or verify -snapshot <file>")
+	}
+	report, err := syncer.Verify(ctx, ref, *tick)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteDiff(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if len(report.Diffs) > 0 {
+		return fmt.Errorf("found %d mismatch(es) with the reference", len(report.Diffs))
+	}
+	return nil
+}
+
+func snapshot(ctx context.Context, syncer *ord.Syncer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing snapshot command, eg: snapshot export -o <file> or snapshot import -i <file>")
+	}
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet("snapshot export", flag.ExitOnError)
+		output := fs.String("o", "", "snapshot file to write, eg: -o snapshot.jsonl.gz")
+		height := fs.Uint64("height", 0, "block height of the state, the latest state by default")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *output == "" {
+			return fmt.Errorf("missing snapshot file, eg: snapshot export -o snapshot.jsonl.gz")
+		}
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		trailer, err := syncer.ExportSnapshot(ctx, f, *height)
+		if err != nil {
+			f.Close()
+			os.Remove(*output)
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("exported %d collection(s), %d token(s) and %d inscription(s) to %s, checksum %s\n",
+			trailer.Collections, trailer.Tokens, trailer.Inscriptions, *output, trailer.Checksum)
+		return nil
+	case "import":
+		fs := flag.NewFlagSet("snapshot import", flag.ExitOnError)
+		input := fs.String("i", "", "snapshot file to import into the empty database, eg: -i snapshot.jsonl.gz")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *input == "" {
+			return fmt.Errorf("missing snapshot file, eg: snapshot import -i snapshot.jsonl.gz")
+		}
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		header, err := syncer.ImportSnapshot(ctx, f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported snapshot at height %d, syncing continues from inscription %d\n", header.Height, header.Checkpoint)
+		return nil
+	default:
+		return fmt.Errorf("unknown snapshot command: %s", args[0])
+	}
+}
+
+func holders(ctx context.Context, syncer *ord.Syncer, args []string) error {
+	fs := flag.NewFlagSet("holders", flag.ExitOnError)
+	tick := fs.String("tick", "", "collection tick of the holders, all the collections by default")
+	height := fs.Uint64("height", 0, "block height of the ownership, the latest by default")
+	minCount := fs.Int("min", 1, "minimum number of the tokens held")
+	format := fs.String("format", ord.HoldersFormatCSV, "output format: csv or json")
+	output := fs.String("o", "", "file to write, stdout by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	w := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	opt := &biz.HolderListOption{
+		P:        biz.ProtocolTypeBRC721,
+		Tick:     *tick,
+		Height:   *height,
+		MinCount: *minCount,
+	}
+	count, err := syncer.ExportHolders(ctx, w, opt, *format)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		fmt.Printf("exported %d holder(s) to %s\n", count, *output)
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/conf"
@@ -36,8 +39,8 @@ func init() {
 	flag.BoolVar(&debug, "debug", false, "debug mode")
 }
 
-func newApp(c *conf.Ord, data *data.Data, collectionUc *biz.CollectionUsecase, tokenUc *biz.TokenUsecase, logger log.Logger) (*ord.Syncer, func(), error) {
-	return ord.NewSyncer(c, data, collectionUc, tokenUc, logger)
+func newApp(c *conf.Ord, data *data.Data, collectionUc *biz.CollectionUsecase, inscriptionUc *biz.InscriptionUsecase, tokenUc *biz.TokenUsecase, traitUc *biz.TraitUsecase, snapshotUc *biz.SnapshotUsecase, checkpointUc *biz.CheckpointUsecase, leaderUc *biz.LeaderUsecase, logger log.Logger) (*ord.Syncer, func(), error) {
+	return ord.NewSyncer(c, data, collectionUc, inscriptionUc, tokenUc, traitUc, snapshotUc, checkpointUc, leaderUc, logger)
 }
 
 func main() {
@@ -80,8 +83,18 @@ func main() {
 	}
 	defer cleanup()
 
-	// start and wait for stop signal
-	if err := app.Run(); err != nil {
+	// the syncer and the commands are stopped by the signals.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if flag.NArg() > 0 {
+		if err := runCommand(ctx, app, flag.Arg(0), flag.Args()[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if err := app.Run(ctx); err != nil {
 		panic(err)
 	}
 }
@@ -22,15 +22,29 @@ import (
 
 // wireApp init kratos application.
 func wireApp(confOrd *conf.Ord, confData *conf.Data, logger log.Logger) (*ord.Syncer, func(), error) {
-	dataData, cleanup, err := data.NewData(confData, logger)
+	network, err := data.NewNetwork(confOrd)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataData, cleanup, err := data.NewData(confData, network, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 	collectionRepo := data.NewCollectionRepo(dataData, logger)
 	collectionUsecase := biz.NewCollectionUsecase(collectionRepo, logger)
+	inscriptionRepo := data.NewInscriptionRepo(dataData, logger)
+	inscriptionUsecase := biz.NewInscriptionUsecase(inscriptionRepo, logger)
 	tokenRepo := data.NewTokenRepo(dataData, logger)
 	tokenUsecase := biz.NewTokenUsecase(tokenRepo, logger)
-	syncer, cleanup2, err := newApp(confOrd, dataData, collectionUsecase, tokenUsecase, logger)
+	traitRepo := data.NewTraitRepo(dataData, logger)
+	traitUsecase := biz.NewTraitUsecase(traitRepo, tokenRepo, logger)
+	snapshotRepo := data.NewSnapshotRepo(dataData, logger)
+	snapshotUsecase := biz.NewSnapshotUsecase(snapshotRepo, logger)
+	checkpointRepo := data.NewCheckpointRepo(dataData, logger)
+	checkpointUsecase := biz.NewCheckpointUsecase(checkpointRepo, logger)
+	leaseRepo := data.NewLeaseRepo(dataData, logger)
+	leaderUsecase := biz.NewLeaderUsecase(leaseRepo, logger)
+	syncer, cleanup2, err := newApp(confOrd, dataData, collectionUsecase, inscriptionUsecase, tokenUsecase, traitUsecase, snapshotUsecase, checkpointUsecase, leaderUsecase, logger)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
@@ -7,9 +7,24 @@ import (
 )
 
 // ProviderSet is biz providers.
-var ProviderSet = wire.NewSet(NewCollectionUsecase, NewTokenUsecase, NewInscriptionUsecase)
+var ProviderSet = wire.NewSet(NewCollectionUsecase, NewTokenUsecase, NewInscriptionUsecase, NewTraitUsecase, NewSnapshotUsecase, NewContentUsecase, NewLeaderUsecase, NewCheckpointUsecase)
 
 type RedisRepo interface {
 	GetLastInscriptionId(ctx context.Context) (int64, error)
 	SetLastInscriptionId(ctx context.Context, id int64) error
 }
+
+type noCacheKey struct{}
+
+// NewNoCacheContext returns a new Context reading the records from the database only.
+// The read-modify-writes of the syncer must not see the stale records of the cache,
+// eg: a collection cached by the API after its supply was updated.
+func NewNoCacheContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// NoCacheFromContext reports whether the Context bypasses the cache.
+func NoCacheFromContext(ctx context.Context) bool {
+	noCache, _ := ctx.Value(noCacheKey{}).(bool)
+	return noCache
+}
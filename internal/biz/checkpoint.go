@@ -0,0 +1,40 @@
+package biz
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// CheckpointRepo is a Checkpoint repo, the checkpoints are kept by name in the
+// network of the context.
+type CheckpointRepo interface {
+	// Get returns the value of the checkpoint, empty if there is none.
+	Get(ctx context.Context, name string) (string, error)
+	// Save creates or updates the value of the checkpoint.
+	Save(ctx context.Context, name, value string) error
+}
+
+// CheckpointUsecase is a Checkpoint usecase, the checkpoints of the syncer are kept
+// in the database, so that the replicas resume from each other's checkpoints.
+type CheckpointUsecase struct {
+	repo CheckpointRepo
+	log  *log.Helper
+}
+
+// NewCheckpointUsecase new a Checkpoint usecase.
+func NewCheckpointUsecase(repo CheckpointRepo, logger log.Logger) *CheckpointUsecase {
+	return &CheckpointUsecase{repo: repo, log: log.NewHelper(logger)}
+}
+
+// GetCheckpoint returns the value of the checkpoint, empty if there is none.
+func (uc *CheckpointUsecase) GetCheckpoint(ctx context.Context, name string) (string, error) {
+	uc.log.WithContext(ctx).Debugf("GetCheckpoint for %s", name)
+	return uc.repo.Get(ctx, name)
+}
+
+// SaveCheckpoint saves the value of the checkpoint.
+func (uc *CheckpointUsecase) SaveCheckpoint(ctx context.Context, name, value string) error {
+	uc.log.WithContext(ctx).Debugf("SaveCheckpoint for %s: %s", name, value)
+	return uc.repo.Save(ctx, name, value)
+}
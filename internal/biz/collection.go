@@ -11,6 +11,7 @@ import (
 // Collection is a Collection model.
 type Collection struct {
 	ID             int                      `json:"id"`
+	Network        string                   `json:"network"`
 	Tick           string                   `json:"tick"`
 	P              string                   `json:"p"`
 	Max            uint64                   `json:"max"`
@@ -27,6 +28,8 @@ type Collection struct {
 	InscriptionID  int64                    `json:"inscription_id"`
 	InscriptionUID string                   `json:"inscription_uid"`
 	Sig            sig.DeploySig            `json:"sig,omitempty"`
+	// ChildMints accepts only the mints inscribed as the children of the deploy inscription.
+	ChildMints bool `json:"child_mints,omitempty"`
 }
 
 type CollectionListOption struct {
@@ -35,6 +38,30 @@ type CollectionListOption struct {
 	P      string
 	Tick   string
 	Order  string
+	// InscriptionIDFrom filters the Collections deployed from the inscription on.
+	InscriptionIDFrom int64
+	// BlockHeightFrom filters the Collections deployed from the block height on.
+	BlockHeightFrom uint64
+}
+
+const (
+	// CollectionSearchOrderRelevance ranks the tick matches first, then the name and description matches.
+	CollectionSearchOrderRelevance = "relevance"
+	// CollectionSearchOrderSupply ranks the collections with the most minted tokens first.
+	CollectionSearchOrderSupply = "supply"
+	// CollectionSearchOrderRecentMint ranks the collections with the latest mints first.
+	CollectionSearchOrderRecentMint = "recent_mint"
+)
+
+// CollectionSearchOption searches the Collections by the tick, name and description.
+// The words of the fields are matched by the prefix Query, or anywhere and by similarity if Fuzzy.
+type CollectionSearchOption struct {
+	Limit  int
+	Offset int
+	P      string
+	Query  string
+	Fuzzy  bool
+	Order  string
 }
 
 // CollectionRepo is a Greater repo.
@@ -47,6 +74,8 @@ type CollectionRepo interface {
 	List(context.Context, ...CollectionListOption) ([]*Collection, error)
 	Delete(context.Context, int) error
 	Count(context.Context, ...CollectionListOption) (int, error)
+	Search(context.Context, CollectionSearchOption) ([]*Collection, error)
+	CountSearch(context.Context, CollectionSearchOption) (int, error)
 }
 
 // CollectionUsecase is a Collection usecase.
@@ -111,3 +140,13 @@ func (uc *CollectionUsecase) DeleteCollection(ctx context.Context, id int) error
 func (uc *CollectionUsecase) CountCollection(ctx context.Context, opt *CollectionListOption) (int, error) {
 	return uc.repo.Count(ctx, *opt)
 }
+
+// SearchCollections searches the Collections, ranked by opt.Order.
+func (uc *CollectionUsecase) SearchCollections(ctx context.Context, opt *CollectionSearchOption) ([]*Collection, error) {
+	return uc.repo.Search(ctx, *opt)
+}
+
+// CountSearchCollections counts the number of the searched Collections.
+func (uc *CollectionUsecase) CountSearchCollections(ctx context.Context, opt *CollectionSearchOption) (int, error) {
+	return uc.repo.CountSearch(ctx, *opt)
+}
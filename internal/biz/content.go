@@ -0,0 +1,74 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ErrContentTooLarge is returned for the Content larger than the max content size.
+var ErrContentTooLarge = errors.New("content is too large")
+
+// Content is the raw content of an Inscription, addressed by its sha256 Hash.
+type Content struct {
+	UID         string
+	ContentType string
+	// Hash is the hex encoded sha256 hash of the content.
+	Hash string
+	Size int64
+	Body io.ReadSeekCloser
+}
+
+// ContentRepo caches the Content of the Inscriptions.
+type ContentRepo interface {
+	// Get returns the cached Content of the Inscription, nil if it's not cached.
+	Get(ctx context.Context, uid string) (*Content, error)
+	// Put caches the Content of the Inscription read from r, and returns it. It returns
+	// ErrContentTooLarge if r is larger than MaxContentSize.
+	Put(ctx context.Context, uid, contentType string, r io.Reader) (*Content, error)
+	// MaxContentSize is the max size of the Content in bytes.
+	MaxContentSize() int64
+}
+
+// ContentSource fetches the Content of the Inscriptions.
+type ContentSource interface {
+	// FetchContent returns the content type and the body of the Inscription, the
+	// body is nil if the Inscription is not found.
+	FetchContent(ctx context.Context, uid string) (string, io.ReadCloser, error)
+}
+
+// ContentUsecase is a Content usecase.
+type ContentUsecase struct {
+	repo   ContentRepo
+	source ContentSource
+	log    *log.Helper
+}
+
+// NewContentUsecase new a Content usecase.
+func NewContentUsecase(repo ContentRepo, source ContentSource, logger log.Logger) *ContentUsecase {
+	return &ContentUsecase{repo: repo, source: source, log: log.NewHelper(logger)}
+}
+
+// GetContent returns the Content of the indexed Inscription, it's fetched and cached
+// on the first request. It returns nil if the Inscription is not found, and refuses
+// the Inscription larger than the max content size without fetching it.
+func (uc *ContentUsecase) GetContent(ctx context.Context, ins *Inscription) (*Content, error) {
+	if int64(ins.ContentLength) > uc.repo.MaxContentSize() {
+		return nil, fmt.Errorf("%w: %s has %d bytes", ErrContentTooLarge, ins.UID, ins.ContentLength)
+	}
+	uid := ins.UID
+	content, err := uc.repo.Get(ctx, uid)
+	if err != nil || content != nil {
+		return content, err
+	}
+	uc.log.WithContext(ctx).Debugf("GetContent fetching %s", uid)
+	contentType, body, err := uc.source.FetchContent(ctx, uid)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	defer body.Close()
+	return uc.repo.Put(ctx, uid, contentType, body)
+}
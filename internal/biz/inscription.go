@@ -10,6 +10,7 @@ import (
 // Inscription is a Inscription model.
 type Inscription struct {
 	ID            int       `json:"id"`
+	Network       string    `json:"network"`
 	InscriptionID int64     `json:"inscription_id"`
 	UID           string    `json:"uid"`
 	Address       string    `json:"address"`
@@ -18,26 +19,70 @@ type Inscription struct {
 	ContentType   string    `json:"content_type"`
 	Timestamp     time.Time `json:"timestamp"`
 	GenesisHeight uint64    `json:"genesis_height"`
+	TxIndex       uint64    `json:"tx_index"`
 	GenesisFee    uint64    `json:"genesis_fee"`
 	GenesisTx     string    `json:"genesis_tx"`
 	Location      string    `json:"location"`
 	Output        string    `json:"output"`
 	Offset        uint64    `json:"offset"`
+	// Sat is the sat the Inscription is on, nil if the Inscription is unbound.
+	Sat       *uint64 `json:"sat,omitempty"`
+	SatRarity string  `json:"sat_rarity"`
+	Cursed    bool    `json:"cursed"`
+	// ParentUID is the uid of the parent Inscription, empty if the Inscription has no parent.
+	ParentUID string `json:"parent_uid,omitempty"`
 }
 
 type InscriptionListOption struct {
 	Limit  int
 	Offset int
 	Order  string
+	// Address and ContentType filter the Inscriptions by the owner address and
+	// the prefix of the content type, eg: image/ or text/plain.
+	Address     string
+	ContentType string
+	// GenesisHeight, Timestamp and GenesisFee filter the Inscriptions in the
+	// inclusive range [From, To], a zero bound is unbounded.
+	GenesisHeightFrom uint64
+	GenesisHeightTo   uint64
+	TimestampFrom     time.Time
+	TimestampTo       time.Time
+	GenesisFeeFrom    uint64
+	GenesisFeeTo      uint64
+	// SatRarity filters the Inscriptions on the sats of any of the rarities,
+	// Sat filters them on the sats in the range.
+	SatRarity []string
+	SatFrom   uint64
+	SatTo     uint64
+	// Cursed filters the cursed Inscriptions if true, and the blessed ones if false.
+	Cursed *bool
+	// ParentUID filters the children of the Inscription.
+	ParentUID string
+	// Outputs filters the Inscriptions on any of the outputs, eg: "<txid>:<vout>".
+	Outputs []string
+	// Cursor lists the Inscriptions from the inscription id on, inclusive. The
+	// Inscriptions are in the order of the inscription id without Order, in the
+	// descending order if Desc is set.
+	Cursor *int64
+	Desc   bool
 }
 
+// defaultInscriptionPageSize is the max size of a page of Inscriptions.
+const defaultInscriptionPageSize = 100
+
 // InscriptionRepo is a Greater repo.
 type InscriptionRepo interface {
 	Create(context.Context, *Inscription) (*Inscription, error)
 	Update(context.Context, *Inscription) (*Inscription, error)
 	FindByInscriptionID(context.Context, int64) (*Inscription, error)
+	FindByUID(context.Context, string) (*Inscription, error)
 	List(context.Context, ...InscriptionListOption) ([]*Inscription, error)
 	Delete(context.Context, int) error
+	// DeleteFrom deletes the Inscriptions from the inscription id on, and returns the count.
+	DeleteFrom(context.Context, int64) (int, error)
+	// DeleteFromHeight deletes the Inscriptions from the genesis height on, the cursed
+	// ones included, and returns the count.
+	DeleteFromHeight(context.Context, uint64) (int, error)
 	Count(context.Context, ...InscriptionListOption) (int, error)
 }
 
@@ -64,18 +109,75 @@ func (uc *InscriptionUsecase) UpdateInscription(ctx context.Context, g *Inscript
 	return uc.repo.Update(ctx, g)
 }
 
+// SaveInscription creates the Inscription, or updates the Inscription of the same uid,
+// eg: when the inscription is re-processed.
+func (uc *InscriptionUsecase) SaveInscription(ctx context.Context, g *Inscription) (*Inscription, error) {
+	uc.log.WithContext(ctx).Debugf("SaveInscription for inscription %d", g.InscriptionID)
+	existing, err := uc.repo.FindByUID(ctx, g.UID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return uc.repo.Create(ctx, g)
+	}
+	updated := *g
+	updated.ID = existing.ID
+	return uc.repo.Update(ctx, &updated)
+}
+
 // FindByInscriptionID finds the Inscription by InscriptionID.
 func (uc *InscriptionUsecase) FindByInscriptionID(ctx context.Context, inscriptionID int64) (*Inscription, error) {
 	uc.log.WithContext(ctx).Debugf("FindByInscriptionID for %d", inscriptionID)
 	return uc.repo.FindByInscriptionID(ctx, inscriptionID)
 }
 
+// FindByUID finds the Inscription by UID.
+func (uc *InscriptionUsecase) FindByUID(ctx context.Context, uid string) (*Inscription, error) {
+	uc.log.WithContext(ctx).Debugf("FindByUID for %s", uid)
+	return uc.repo.FindByUID(ctx, uid)
+}
+
 // ListInscriptions lists Inscriptions.
 func (uc *InscriptionUsecase) ListInscriptions(ctx context.Context, opt *InscriptionListOption) ([]*Inscription, error) {
 	uc.log.WithContext(ctx).Debugf("ListInscriptions for %v", opt)
 	return uc.repo.List(ctx, *opt)
 }
 
+// ListInscriptionsPage lists a page of the Inscriptions from the cursor of the option,
+// and returns the cursor of the next page, nil on the last page.
+func (uc *InscriptionUsecase) ListInscriptionsPage(ctx context.Context, opt *InscriptionListOption) ([]*Inscription, *int64, error) {
+	uc.log.WithContext(ctx).Debugf("ListInscriptionsPage for %v", opt)
+	limit := opt.Limit
+	if limit <= 0 || limit > defaultInscriptionPageSize {
+		limit = defaultInscriptionPageSize
+	}
+	o := *opt
+	o.Limit = limit + 1
+	o.Offset = 0
+	o.Order = ""
+	inscriptions, err := uc.repo.List(ctx, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(inscriptions) <= limit {
+		return inscriptions, nil, nil
+	}
+	next := inscriptions[limit].InscriptionID
+	return inscriptions[:limit], &next, nil
+}
+
+// DeleteInscriptionsFrom deletes the Inscriptions from the inscription id on.
+func (uc *InscriptionUsecase) DeleteInscriptionsFrom(ctx context.Context, inscriptionID int64) (int, error) {
+	uc.log.WithContext(ctx).Debugf("DeleteInscriptionsFrom for %d", inscriptionID)
+	return uc.repo.DeleteFrom(ctx, inscriptionID)
+}
+
+// DeleteInscriptionsFromHeight deletes the Inscriptions from the genesis height on.
+func (uc *InscriptionUsecase) DeleteInscriptionsFromHeight(ctx context.Context, height uint64) (int, error) {
+	uc.log.WithContext(ctx).Debugf("DeleteInscriptionsFromHeight for %d", height)
+	return uc.repo.DeleteFromHeight(ctx, height)
+}
+
 // DeleteInscription deletes a Inscription.
 func (uc *InscriptionUsecase) DeleteInscription(ctx context.Context, id int) error {
 	uc.log.WithContext(ctx).Debugf("DeleteInscription for %d", id)
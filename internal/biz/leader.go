@@ -0,0 +1,243 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ErrLeaseLost is the error of a leader whose lease has expired or been taken over.
+var ErrLeaseLost = errors.New("leader lease lost")
+
+// Lease is the leadership of Name held by Holder. The fencing Token increases with
+// every new leader, so that a stale leader can be told apart from the current one.
+type Lease struct {
+	Name   string
+	Holder string
+	Token  int64
+}
+
+// Fence is the fencing token of a lease. The repos commit the writes of a context
+// carrying a Fence only while no leader of the lease with a greater token has
+// committed, in the same transaction as the writes, and refuse them with ErrLeaseLost
+// otherwise.
+type Fence struct {
+	Name  string
+	Token int64
+}
+
+type fenceKey struct{}
+
+// NewFenceContext returns a new Context whose writes are fenced by the Fence.
+func NewFenceContext(ctx context.Context, fence *Fence) context.Context {
+	return context.WithValue(ctx, fenceKey{}, fence)
+}
+
+// FenceFromContext returns the Fence of the writes carried by the Context.
+func FenceFromContext(ctx context.Context) (*Fence, bool) {
+	fence, ok := ctx.Value(fenceKey{}).(*Fence)
+	return fence, ok && fence != nil
+}
+
+type lockKey struct{}
+
+// NewLockContext returns a new Context whose transactions hold the lock of the name,
+// the transactions holding the same lock are run one after another.
+func NewLockContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, lockKey{}, name)
+}
+
+// LockFromContext returns the name of the lock held by the transactions of the Context.
+func LockFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(lockKey{}).(string)
+	return name, ok && name != ""
+}
+
+// LeaseRepo is a Lease repo.
+type LeaseRepo interface {
+	// AcquireLease acquires the lease for the holder for the ttl, nil if it is held by another.
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*Lease, error)
+	// RenewLease extends the lease for the ttl, ErrLeaseLost if it is not held anymore.
+	RenewLease(ctx context.Context, lease *Lease, ttl time.Duration) error
+	// ReleaseLease releases the lease if it is still held.
+	ReleaseLease(ctx context.Context, lease *Lease) error
+	// CheckLease returns ErrLeaseLost if the lease is not held anymore.
+	CheckLease(ctx context.Context, lease *Lease) error
+}
+
+// LeaderUsecase elects a leader among the replicas by a lease, the standbys wait
+// and take over once the lease of the leader expires.
+type LeaderUsecase struct {
+	repo   LeaseRepo
+	holder string
+	log    *log.Helper
+}
+
+// NewLeaderUsecase new a Leader usecase, the holder is the host and the process.
+func NewLeaderUsecase(repo LeaseRepo, logger log.Logger) *LeaderUsecase {
+	host, _ := os.Hostname()
+	return &LeaderUsecase{
+		repo:   repo,
+		holder: fmt.Sprintf("%s-%d", host, os.Getpid()),
+		log:    log.NewHelper(logger),
+	}
+}
+
+// Campaign blocks until the lease of name is acquired or the context is done. The
+// lease is tried every third of the ttl, and renewed as often once acquired.
+func (uc *LeaderUsecase) Campaign(ctx context.Context, name string, ttl time.Duration) (*Leadership, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("invalid lease ttl %s", ttl)
+	}
+	interval := ttl / 3
+	waiting := false
+	for {
+		start := time.Now()
+		lease, err := uc.repo.AcquireLease(ctx, name, uc.holder, ttl)
+		if err != nil {
+			uc.log.Errorf("failed to acquire lease %s: %v", name, err)
+		}
+		if lease != nil {
+			uc.log.Infof("%s is the leader of %s with token %d", lease.Holder, name, lease.Token)
+			return uc.lead(ctx, lease, start.Add(ttl), ttl), nil
+		}
+		if !waiting && err == nil {
+			uc.log.Infof("%s is waiting for the lease %s", uc.holder, name)
+			waiting = true
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Leadership is the lease held by the leader, it is renewed in the background until
+// it is resigned or lost.
+type Leadership struct {
+	uc     *LeaderUsecase
+	lease  *Lease
+	ttl    time.Duration
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+
+	lock sync.Mutex
+	// expiresAt is when the lease expires without renewal, it is measured from before
+	// the lease is acquired or renewed, so it is never later than the expiry in the repo.
+	expiresAt time.Time
+	lost      bool
+}
+
+func (uc *LeaderUsecase) lead(ctx context.Context, lease *Lease, expiresAt time.Time, ttl time.Duration) *Leadership {
+	l := &Leadership{
+		uc:        uc,
+		lease:     lease,
+		ttl:       ttl,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		expiresAt: expiresAt,
+	}
+	l.ctx, l.cancel = context.WithCancel(ctx)
+	go l.renew()
+	return l
+}
+
+// Context is done once the lease is lost, or the context of the campaign is done.
+func (l *Leadership) Context() context.Context {
+	return l.ctx
+}
+
+// Token is the fencing token of the lease.
+func (l *Leadership) Token() int64 {
+	return l.lease.Token
+}
+
+// Fence is the fence of the commits of the leader.
+func (l *Leadership) Fence() *Fence {
+	return &Fence{Name: l.lease.Name, Token: l.lease.Token}
+}
+
+// Check fences the commits of the leader, it returns ErrLeaseLost if the lease has
+// expired locally or is not held in the repo anymore.
+func (l *Leadership) Check(ctx context.Context) error {
+	l.lock.Lock()
+	valid := !l.lost && time.Now().Before(l.expiresAt)
+	l.lock.Unlock()
+	if !valid {
+		return ErrLeaseLost
+	}
+	if err := l.uc.repo.CheckLease(ctx, l.lease); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			l.lose()
+		}
+		return err
+	}
+	return nil
+}
+
+// Resign stops renewing the lease and releases it.
+func (l *Leadership) Resign() {
+	close(l.stop)
+	<-l.done
+	l.cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+	defer cancel()
+	if err := l.uc.repo.ReleaseLease(ctx, l.lease); err != nil {
+		l.uc.log.Errorf("failed to release lease %s: %v", l.lease.Name, err)
+	}
+}
+
+// renew renews the lease every third of the ttl, the leadership is lost if the
+// lease is taken over or can't be renewed before it expires.
+func (l *Leadership) renew() {
+	defer close(l.done)
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := l.uc.repo.RenewLease(ctx, l.lease, l.ttl)
+		cancel()
+		l.lock.Lock()
+		if err == nil {
+			l.expiresAt = start.Add(l.ttl)
+		}
+		expired := time.Now().After(l.expiresAt)
+		l.lock.Unlock()
+		switch {
+		case errors.Is(err, ErrLeaseLost):
+			l.uc.log.Warnf("lease %s of token %d is taken over", l.lease.Name, l.lease.Token)
+			l.lose()
+			return
+		case expired:
+			l.uc.log.Warnf("lease %s of token %d expired: %v", l.lease.Name, l.lease.Token, err)
+			l.lose()
+			return
+		case err != nil:
+			l.uc.log.Errorf("failed to renew lease %s: %v", l.lease.Name, err)
+		}
+	}
+}
+
+func (l *Leadership) lose() {
+	l.lock.Lock()
+	l.lost = true
+	l.lock.Unlock()
+	l.cancel()
+}
@@ -0,0 +1,146 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/adshao/go-brc721/sig"
+)
+
+// MintSigFieldSig and MintSigFieldSignature are the verdict fields of the mint sig
+// itself and its signature, the other verdicts are keyed by the sig.SigField.
+const (
+	MintSigFieldSig       = "sig"
+	MintSigFieldSignature = "s"
+)
+
+// MintFieldDeploy, MintFieldCursed, MintFieldParent and MintFieldSupply are the
+// verdict fields of the eligibility of a mint for its Collection.
+const (
+	MintFieldDeploy = "deploy"
+	MintFieldCursed = "cursed"
+	MintFieldParent = "parent"
+	MintFieldSupply = "supply"
+)
+
+// MintSigVerdict is the verdict of a field of the mint sig.
+type MintSigVerdict struct {
+	Field  string `json:"field"`
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// MintSigCandidate is a mint to verify against the DeploySig of its Collection.
+// The Receiver, BlockHeight and BlockTime are the ones of the mint inscription.
+type MintSigCandidate struct {
+	Sig         *sig.MintSig
+	Receiver    string
+	BlockHeight uint64
+	BlockTime   time.Time
+}
+
+// MintSigReport is the result of verifying a mint sig.
+type MintSigReport struct {
+	// Required is false if the Collection does not require signed mints.
+	Required bool              `json:"required"`
+	Valid    bool              `json:"valid"`
+	Verdicts []*MintSigVerdict `json:"verdicts,omitempty"`
+	// Sig holds the signed fields of the mint, as they are verified and stored.
+	Sig *sig.MintSig `json:"sig,omitempty"`
+}
+
+func (r *MintSigReport) verdict(field, reason string, args ...interface{}) {
+	v := &MintSigVerdict{Field: field, Valid: reason == ""}
+	if reason != "" {
+		v.Reason = fmt.Sprintf(reason, args...)
+		r.Valid = false
+	}
+	r.Verdicts = append(r.Verdicts, v)
+}
+
+// VerifyMintSig checks every signed field of the mint against the DeploySig of the Collection,
+// then verifies the signature. It only reads the Tokens, to check if the uid is already used.
+func (uc *TokenUsecase) VerifyMintSig(ctx context.Context, collection *Collection, mint *MintSigCandidate) (*MintSigReport, error) {
+	report := &MintSigReport{Valid: true}
+	if collection.Sig.PubKey == "" || len(collection.Sig.Fields) == 0 {
+		return report, nil
+	}
+	report.Required = true
+	if mint.Sig == nil {
+		report.verdict(MintSigFieldSig, "missing mint sig")
+		return report, nil
+	}
+	mintSig := &sig.MintSig{
+		Signature: mint.Sig.Signature,
+	}
+	for _, field := range collection.Sig.Fields {
+		switch field {
+		case sig.SigFieldReceiver:
+			if mint.Receiver == "" {
+				report.verdict(string(field), "missing receiver")
+				continue
+			}
+			mintSig.Receiver = mint.Receiver
+		case sig.SigFieldUid:
+			if mint.Sig.Uid == "" {
+				report.verdict(string(field), "missing sig.uid")
+				continue
+			}
+			token, err := uc.repo.FindByTickSigUID(ctx, collection.P, collection.Tick, mint.Sig.Uid)
+			if err != nil {
+				return nil, err
+			}
+			mintSig.Uid = mint.Sig.Uid
+			if token != nil {
+				report.verdict(string(field), "sig.uid %s is already used by token %d", mint.Sig.Uid, token.TokenID)
+				continue
+			}
+		case sig.SigFieldExpiredTime:
+			if mint.Sig.ExpiredTime == 0 {
+				report.verdict(string(field), "missing sig.expt")
+				continue
+			}
+			if mint.BlockTime.IsZero() {
+				return nil, fmt.Errorf("missing block time to check sig.expt")
+			}
+			mintSig.ExpiredTime = mint.Sig.ExpiredTime
+			if uint64(mint.BlockTime.Unix()) > mint.Sig.ExpiredTime {
+				report.verdict(string(field), "sig.expt %d is expired at block time %d", mint.Sig.ExpiredTime, mint.BlockTime.Unix())
+				continue
+			}
+		case sig.SigFieldExpiredHeight:
+			if mint.Sig.ExpiredHeight == 0 {
+				report.verdict(string(field), "missing sig.exph")
+				continue
+			}
+			mintSig.ExpiredHeight = mint.Sig.ExpiredHeight
+			if mint.BlockHeight > mint.Sig.ExpiredHeight {
+				report.verdict(string(field), "sig.exph %d is expired at block height %d", mint.Sig.ExpiredHeight, mint.BlockHeight)
+				continue
+			}
+		}
+		report.verdict(string(field), "")
+	}
+	report.Sig = mintSig
+	if mintSig.Signature == "" {
+		report.verdict(MintSigFieldSignature, "missing sig.s")
+		return report, nil
+	}
+	pubKey, err := sig.ParsePubKey(collection.Sig.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s of collection %s: %v", collection.Sig.PubKey, collection.Tick, err)
+	}
+	valid, err := mintSig.Verify(pubKey)
+	switch {
+	case err != nil:
+		report.verdict(MintSigFieldSignature, "failed to verify sig.s: %v", err)
+	case !valid && mint.Sig.Receiver != "" && mintSig.Receiver != "" && mint.Sig.Receiver != mintSig.Receiver:
+		report.verdict(MintSigFieldSignature, "invalid sig.s, the signed sig.rec %s is not the receiver %s", mint.Sig.Receiver, mintSig.Receiver)
+	case !valid:
+		report.verdict(MintSigFieldSignature, "invalid sig.s")
+	default:
+		report.verdict(MintSigFieldSignature, "")
+	}
+	return report, nil
+}
@@ -0,0 +1,69 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+	NetworkSignet  = "signet"
+	NetworkRegtest = "regtest"
+)
+
+// Network is the Bitcoin network the data is indexed from.
+type Network struct {
+	Name string
+	// Params are the chain params of the addresses of the network.
+	Params *chaincfg.Params
+}
+
+var networks = map[string]*Network{
+	NetworkMainnet: {Name: NetworkMainnet, Params: &chaincfg.MainNetParams},
+	NetworkTestnet: {Name: NetworkTestnet, Params: &chaincfg.TestNet3Params},
+	NetworkSignet:  {Name: NetworkSignet, Params: &chaincfg.SigNetParams},
+	NetworkRegtest: {Name: NetworkRegtest, Params: &chaincfg.RegressionNetParams},
+}
+
+// ParseNetwork returns the Network by name, mainnet if the name is empty.
+func ParseNetwork(name string) (*Network, error) {
+	if name == "" {
+		name = NetworkMainnet
+	}
+	n, ok := networks[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %q, expected mainnet, testnet, signet or regtest", name)
+	}
+	return n, nil
+}
+
+type networkKey struct{}
+
+// NewNetworkContext returns a new Context carrying the network of the data.
+func NewNetworkContext(ctx context.Context, network string) context.Context {
+	return context.WithValue(ctx, networkKey{}, network)
+}
+
+// NetworkFromContext returns the network of the data carried by the Context.
+func NetworkFromContext(ctx context.Context) (string, bool) {
+	network, ok := ctx.Value(networkKey{}).(string)
+	return network, ok && network != ""
+}
+
+// ValidateAddress checks the address is a base58 P2PKH or P2SH address, or a
+// segwit address of the Network.
+func (n *Network) ValidateAddress(address string) error {
+	addr, err := btcutil.DecodeAddress(address, n.Params)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	if !addr.IsForNet(n.Params) {
+		return fmt.Errorf("address %s is not a %s address", address, n.Name)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package biz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InscriptionPosition is the position of an inscription on the chain. The inscriptions
+// are ordered by their positions, the numbers are not monotonic since the cursed
+// inscriptions are numbered negatively.
+type InscriptionPosition struct {
+	Height uint64 `json:"height"`
+	// TxIndex is the index of the genesis tx in the block.
+	TxIndex uint64 `json:"tx_index"`
+	// Index is the index of the inscription in the genesis tx, the suffix of its uid.
+	Index uint64 `json:"index"`
+}
+
+// NewInscriptionPosition returns the position of the inscription of the uid, eg:
+// <txid>i0, in the genesis tx of the tx index at the height.
+func NewInscriptionPosition(height, txIndex uint64, uid string) (InscriptionPosition, error) {
+	pos := InscriptionPosition{Height: height, TxIndex: txIndex}
+	i := strings.LastIndex(uid, "i")
+	if i < 0 {
+		return pos, fmt.Errorf("invalid inscription uid %q", uid)
+	}
+	index, err := strconv.ParseUint(uid[i+1:], 10, 64)
+	if err != nil {
+		return pos, fmt.Errorf("invalid inscription uid %q: %v", uid, err)
+	}
+	pos.Index = index
+	return pos, nil
+}
+
+// Less reports whether the position is before the other one.
+func (p InscriptionPosition) Less(o InscriptionPosition) bool {
+	if p.Height != o.Height {
+		return p.Height < o.Height
+	}
+	if p.TxIndex != o.TxIndex {
+		return p.TxIndex < o.TxIndex
+	}
+	return p.Index < o.Index
+}
+
+func (p InscriptionPosition) String() string {
+	return fmt.Sprintf("%d:%d:%d", p.Height, p.TxIndex, p.Index)
+}
+
+// Position returns the position of the Inscription.
+func (g *Inscription) Position() (InscriptionPosition, error) {
+	return NewInscriptionPosition(g.GenesisHeight, g.TxIndex, g.UID)
+}
@@ -0,0 +1,74 @@
+package biz
+
+import "fmt"
+
+// The rarity of a sat by the ordinal theory, from the most common to the rarest.
+const (
+	SatRarityCommon    = "common"
+	SatRarityUncommon  = "uncommon"
+	SatRarityRare      = "rare"
+	SatRarityEpic      = "epic"
+	SatRarityLegendary = "legendary"
+	SatRarityMythic    = "mythic"
+)
+
+var satRarities = []string{SatRarityCommon, SatRarityUncommon, SatRarityRare, SatRarityEpic, SatRarityLegendary, SatRarityMythic}
+
+const (
+	subsidyHalvingInterval = 210000
+	diffChangeInterval     = 2016
+	// cycleEpochs is the number of the halving epochs of a cycle, when a halving
+	// and a difficulty adjustment happen at the same block.
+	cycleEpochs = 6
+	coinValue   = 100000000
+	// supplyEpochs is the number of the halving epochs with a subsidy.
+	supplyEpochs = 33
+)
+
+// ParseSatRarity checks the rarity name.
+func ParseSatRarity(rarity string) (string, error) {
+	for _, r := range satRarities {
+		if r == rarity {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sat rarity %q, expected one of %v", rarity, satRarities)
+}
+
+// SatRarity returns the rarity of the sat:
+//   - uncommon: the first sat of a block
+//   - rare: the first sat of a difficulty adjustment period
+//   - epic: the first sat of a halving epoch
+//   - legendary: the first sat of a cycle
+//   - mythic: the first sat of the genesis block
+//
+// The sats beyond the supply are common.
+func SatRarity(sat uint64) string {
+	if sat == 0 {
+		return SatRarityMythic
+	}
+	var start uint64
+	for epoch := uint64(0); epoch < supplyEpochs; epoch++ {
+		subsidy := uint64(50*coinValue) >> epoch
+		size := subsidy * subsidyHalvingInterval
+		if sat >= start+size {
+			start += size
+			continue
+		}
+		offset := (sat - start) % subsidy
+		height := epoch*subsidyHalvingInterval + (sat-start)/subsidy
+		switch {
+		case offset != 0:
+			return SatRarityCommon
+		case height%(cycleEpochs*subsidyHalvingInterval) == 0:
+			return SatRarityLegendary
+		case height%subsidyHalvingInterval == 0:
+			return SatRarityEpic
+		case height%diffChangeInterval == 0:
+			return SatRarityRare
+		default:
+			return SatRarityUncommon
+		}
+	}
+	return SatRarityCommon
+}
@@ -0,0 +1,246 @@
+package biz
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// SnapshotVersion is the version of the snapshot format.
+const SnapshotVersion = 1
+
+var snapshotJSON = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// SnapshotHeader is the first record of a snapshot.
+type SnapshotHeader struct {
+	Version int `json:"version"`
+	// Network is the network of the state.
+	Network string `json:"network,omitempty"`
+	// Height is the block height of the state, 0 for the latest state.
+	Height uint64 `json:"height"`
+	// Checkpoint is the last synced inscription id to continue syncing from.
+	Checkpoint int64     `json:"checkpoint"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SnapshotTrailer is the last record of a snapshot.
+type SnapshotTrailer struct {
+	Collections  int `json:"collections"`
+	Tokens       int `json:"tokens"`
+	Inscriptions int `json:"inscriptions"`
+	// Checksum is the hex sha256 of the uncompressed lines before the trailer.
+	Checksum string `json:"checksum"`
+}
+
+// SnapshotRecord is a line of the snapshot in JSON lines, the collections,
+// tokens and inscriptions follow the header in order.
+type SnapshotRecord struct {
+	Header     *SnapshotHeader `json:"header,omitempty"`
+	Collection *Collection     `json:"collection,omitempty"`
+	Token      *Token          `json:"token,omitempty"`
+	// Traits and Transfers are the traits and transfers of the token of the record.
+	Traits      []*Trait         `json:"traits,omitempty"`
+	Transfers   []*Transfer      `json:"transfers,omitempty"`
+	Inscription *Inscription     `json:"inscription,omitempty"`
+	Trailer     *SnapshotTrailer `json:"trailer,omitempty"`
+}
+
+// SnapshotRepo reads and restores the whole state.
+type SnapshotRepo interface {
+	// LastInscriptionID returns the last inscription id of the state at the height.
+	LastInscriptionID(ctx context.Context, height uint64) (int64, error)
+	// Export calls fn with the collections, tokens and inscriptions at the height,
+	// 0 for the latest state, the supply of the collections is counted at the height,
+	// and the tokens are owned by their owners at the height.
+	Export(ctx context.Context, height uint64, fn func(*SnapshotRecord) error) error
+	// Import creates the records returned by next into the empty database in a
+	// transaction until next returns io.EOF, nothing is created on error.
+	Import(ctx context.Context, next func() (*SnapshotRecord, error)) error
+}
+
+// SnapshotWriter writes the gzip compressed snapshot.
+type SnapshotWriter struct {
+	gz      *gzip.Writer
+	hash    hash.Hash
+	w       io.Writer
+	trailer SnapshotTrailer
+}
+
+// NewSnapshotWriter writes the header of the snapshot.
+func NewSnapshotWriter(w io.Writer, header *SnapshotHeader) (*SnapshotWriter, error) {
+	gz := gzip.NewWriter(w)
+	h := sha256.New()
+	sw := &SnapshotWriter{gz: gz, hash: h, w: io.MultiWriter(gz, h)}
+	return sw, sw.Write(&SnapshotRecord{Header: header})
+}
+
+// Write writes a record of the snapshot.
+func (w *SnapshotWriter) Write(record *SnapshotRecord) error {
+	b, err := snapshotJSON.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	switch {
+	case record.Collection != nil:
+		w.trailer.Collections++
+	case record.Token != nil:
+		w.trailer.Tokens++
+	case record.Inscription != nil:
+		w.trailer.Inscriptions++
+	}
+	return nil
+}
+
+// Close writes the trailer with the checksum, and flushes the snapshot.
+func (w *SnapshotWriter) Close() (*SnapshotTrailer, error) {
+	trailer := w.trailer
+	trailer.Checksum = hex.EncodeToString(w.hash.Sum(nil))
+	b, err := snapshotJSON.Marshal(&SnapshotRecord{Trailer: &trailer})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.gz.Write(append(b, '\n')); err != nil {
+		return nil, err
+	}
+	return &trailer, w.gz.Close()
+}
+
+// SnapshotReader reads the gzip compressed snapshot.
+type SnapshotReader struct {
+	r       *bufio.Reader
+	hash    hash.Hash
+	line    int
+	Header  *SnapshotHeader
+	Trailer *SnapshotTrailer
+}
+
+// NewSnapshotReader reads the header of the snapshot.
+func NewSnapshotReader(r io.Reader) (*SnapshotReader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	sr := &SnapshotReader{r: bufio.NewReader(gz), hash: sha256.New()}
+	record, err := sr.Next()
+	if err == io.EOF || (err == nil && record.Header == nil) {
+		return nil, errors.New("invalid snapshot: missing header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Header.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", record.Header.Version)
+	}
+	sr.Header = record.Header
+	return sr, nil
+}
+
+// Next returns the next record of the snapshot, or io.EOF after the trailer
+// whose checksum matches the records.
+func (r *SnapshotReader) Next() (*SnapshotRecord, error) {
+	if r.Trailer != nil {
+		return nil, io.EOF
+	}
+	b, err := r.r.ReadBytes('\n')
+	if err == io.EOF && len(b) == 0 {
+		return nil, errors.New("invalid snapshot: missing trailer")
+	}
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	r.line++
+	var record SnapshotRecord
+	if err := snapshotJSON.Unmarshal(b, &record); err != nil {
+		return nil, fmt.Errorf("invalid snapshot record at line %d: %w", r.line, err)
+	}
+	if record.Trailer == nil {
+		r.hash.Write(b)
+		return &record, nil
+	}
+	if checksum := hex.EncodeToString(r.hash.Sum(nil)); checksum != record.Trailer.Checksum {
+		return nil, fmt.Errorf("invalid snapshot: checksum mismatch, expected %s, got %s", record.Trailer.Checksum, checksum)
+	}
+	if _, err := r.r.Peek(1); err != io.EOF {
+		return nil, errors.New("invalid snapshot: trailing data after the trailer")
+	}
+	r.Trailer = record.Trailer
+	return nil, io.EOF
+}
+
+// SnapshotUsecase is a Snapshot usecase.
+type SnapshotUsecase struct {
+	repo SnapshotRepo
+	log  *log.Helper
+}
+
+// NewSnapshotUsecase new a Snapshot usecase.
+func NewSnapshotUsecase(repo SnapshotRepo, logger log.Logger) *SnapshotUsecase {
+	return &SnapshotUsecase{repo: repo, log: log.NewHelper(logger)}
+}
+
+// ExportSnapshot writes the snapshot of the state at the height, 0 for the latest
+// state. The checkpoint is the current sync checkpoint, which is moved back to the
+// last inscription at the height.
+func (uc *SnapshotUsecase) ExportSnapshot(ctx context.Context, w io.Writer, height uint64, checkpoint int64) (*SnapshotTrailer, error) {
+	if height > 0 {
+		last, err := uc.repo.LastInscriptionID(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		if last < checkpoint {
+			checkpoint = last
+		}
+	}
+	network, _ := NetworkFromContext(ctx)
+	sw, err := NewSnapshotWriter(w, &SnapshotHeader{
+		Version:    SnapshotVersion,
+		Network:    network,
+		Height:     height,
+		Checkpoint: checkpoint,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Export(ctx, height, sw.Write); err != nil {
+		return nil, err
+	}
+	trailer, err := sw.Close()
+	if err != nil {
+		return nil, err
+	}
+	uc.log.WithContext(ctx).Infof("exported snapshot at height %d: %d collections, %d tokens, %d inscriptions",
+		height, trailer.Collections, trailer.Tokens, trailer.Inscriptions)
+	return trailer, nil
+}
+
+// ImportSnapshot restores the snapshot into the empty database, and returns its
+// header. Nothing is imported if the snapshot is corrupted or of another network.
+func (uc *SnapshotUsecase) ImportSnapshot(ctx context.Context, r io.Reader) (*SnapshotHeader, error) {
+	sr, err := NewSnapshotReader(r)
+	if err != nil {
+		return nil, err
+	}
+	if network, ok := NetworkFromContext(ctx); ok && sr.Header.Network != "" && sr.Header.Network != network {
+		return nil, fmt.Errorf("snapshot of %s cannot be imported into %s", sr.Header.Network, network)
+	}
+	if err := uc.repo.Import(ctx, sr.Next); err != nil {
+		return nil, err
+	}
+	uc.log.WithContext(ctx).Infof("imported snapshot at height %d: %d collections, %d tokens, %d inscriptions",
+		sr.Header.Height, sr.Trailer.Collections, sr.Trailer.Tokens, sr.Trailer.Inscriptions)
+	return sr.Header, nil
+}
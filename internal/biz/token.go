@@ -15,6 +15,7 @@ const (
 // Token is a Token model.
 type Token struct {
 	ID             int         `json:"id"`
+	Network        string      `json:"network"`
 	P              string      `json:"p"`
 	Tick           string      `json:"tick"`
 	TokenID        uint64      `json:"token_id"`
@@ -34,6 +35,22 @@ type TokenListOption struct {
 	Tick   string
 	P      string
 	Order  string
+	// Traits filters the Tokens having the traits, the values of the same
+	// trait type are OR'ed, and the different trait types are AND'ed.
+	Traits []*Trait
+	// InscriptionIDFrom filters the Tokens minted from the inscription on.
+	InscriptionIDFrom int64
+	// BlockHeightFrom filters the Tokens minted from the block height on.
+	BlockHeightFrom uint64
+	// SigUID and SigReceiver filter the Tokens by their signed mint fields.
+	SigUID      string
+	SigReceiver string
+	// SigExpiredTime and SigExpiredHeight filter the Tokens signed with an expiry
+	// in the inclusive range [From, To], a zero bound is unbounded.
+	SigExpiredTimeFrom   uint64
+	SigExpiredTimeTo     uint64
+	SigExpiredHeightFrom uint64
+	SigExpiredHeightTo   uint64
 }
 
 // TokenRepo is a Greater repo.
@@ -46,6 +63,13 @@ type TokenRepo interface {
 	List(context.Context, ...TokenListOption) ([]*Token, error)
 	Delete(context.Context, int) error
 	Count(context.Context, ...TokenListOption) (int, error)
+	// Transfer records the Transfer of the Token.
+	Transfer(context.Context, *Transfer) (*Transfer, error)
+	// DeleteTransfersFrom deletes the Transfers from the block height on.
+	DeleteTransfersFrom(context.Context, uint64) ([]*Transfer, error)
+	// ListOwners lists the owners of the Tokens minted at the block height,
+	// 0 for the latest, the Tokens of all the Collections if tick is empty.
+	ListOwners(ctx context.Context, p, tick string, height uint64) ([]*TokenOwner, error)
 }
 
 // TokenUsecase is a Token usecase.
@@ -0,0 +1,156 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Trait is a Trait model, one attribute of the token metadata.
+type Trait struct {
+	TraitType string `json:"trait_type"`
+	Value     string `json:"value"`
+}
+
+// TraitStat is the frequency of a trait value in the Collection.
+// The rarity score of the trait value is 1 / Frequency, and the rarity
+// score of a Token is the sum of the rarity scores of its traits.
+type TraitStat struct {
+	TraitType   string  `json:"trait_type"`
+	Value       string  `json:"value"`
+	Count       int     `json:"count"`
+	Frequency   float64 `json:"frequency"`
+	RarityScore float64 `json:"rarity_score"`
+}
+
+// TokenRarity is the rarity of a Token.
+type TokenRarity struct {
+	Traits      []*TraitStat `json:"traits"`
+	RarityScore float64      `json:"rarity_score"`
+}
+
+// TraitRepo is a Trait repo.
+type TraitRepo interface {
+	// Replace replaces the traits of the Token.
+	Replace(context.Context, *Token, []*Trait) error
+	// FindByTokenIDs finds the traits of the Tokens, keyed by the Token ID.
+	FindByTokenIDs(context.Context, []int) (map[int][]*Trait, error)
+	// CountByTick counts the Tokens of the Collection by trait value.
+	CountByTick(context.Context, string, string) ([]*TraitStat, error)
+}
+
+// TraitUsecase is a Trait usecase.
+type TraitUsecase struct {
+	repo      TraitRepo
+	tokenRepo TokenRepo
+	log       *log.Helper
+}
+
+// NewTraitUsecase new a Trait usecase.
+func NewTraitUsecase(repo TraitRepo, tokenRepo TokenRepo, logger log.Logger) *TraitUsecase {
+	return &TraitUsecase{repo: repo, tokenRepo: tokenRepo, log: log.NewHelper(logger)}
+}
+
+// ParseTraits parses the traits from the metadata attributes, eg:
+// [{"trait_type": "eyes", "value": "blue"}, {"trait_type": "level", "value": 5}].
+// The attributes without trait_type or value are ignored.
+func ParseTraits(attributes []map[string]interface{}) []*Trait {
+	traits := make([]*Trait, 0)
+	seen := make(map[Trait]bool)
+	for _, attr := range attributes {
+		traitType, ok := attr["trait_type"].(string)
+		if !ok || traitType == "" {
+			continue
+		}
+		var value string
+		switch v := attr["value"].(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			value = strconv.FormatBool(v)
+		case nil:
+			continue
+		default:
+			value = fmt.Sprint(v)
+		}
+		trait := Trait{TraitType: traitType, Value: value}
+		if seen[trait] {
+			continue
+		}
+		seen[trait] = true
+		traits = append(traits, &trait)
+	}
+	return traits
+}
+
+// SetTokenTraits replaces the traits of the Token.
+func (uc *TraitUsecase) SetTokenTraits(ctx context.Context, token *Token, traits []*Trait) error {
+	uc.log.WithContext(ctx).Debugf("SetTokenTraits for %s %s %d", token.P, token.Tick, token.TokenID)
+	return uc.repo.Replace(ctx, token, traits)
+}
+
+// GetCollectionTraits gets the trait frequency table of the Collection.
+func (uc *TraitUsecase) GetCollectionTraits(ctx context.Context, p, tick string) ([]*TraitStat, error) {
+	uc.log.WithContext(ctx).Debugf("GetCollectionTraits for %s %s", p, tick)
+	stats, err := uc.repo.CountByTick(ctx, p, tick)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := uc.tokenRepo.Count(ctx, TokenListOption{P: p, Tick: tick})
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		if supply == 0 || stat.Count == 0 {
+			continue
+		}
+		stat.Frequency = float64(stat.Count) / float64(supply)
+		stat.RarityScore = float64(supply) / float64(stat.Count)
+	}
+	return stats, nil
+}
+
+// GetTokensRarity gets the rarity of the Tokens, keyed by the Token ID.
+func (uc *TraitUsecase) GetTokensRarity(ctx context.Context, tokens []*Token) (map[int]*TokenRarity, error) {
+	ids := make([]int, 0, len(tokens))
+	for _, token := range tokens {
+		ids = append(ids, token.ID)
+	}
+	traits, err := uc.repo.FindByTokenIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	// the frequency tables of the collections, keyed by p and tick.
+	tables := make(map[[2]string]map[Trait]*TraitStat)
+	rarities := make(map[int]*TokenRarity)
+	for _, token := range tokens {
+		key := [2]string{token.P, token.Tick}
+		table, ok := tables[key]
+		if !ok {
+			stats, err := uc.GetCollectionTraits(ctx, token.P, token.Tick)
+			if err != nil {
+				return nil, err
+			}
+			table = make(map[Trait]*TraitStat)
+			for _, stat := range stats {
+				table[Trait{TraitType: stat.TraitType, Value: stat.Value}] = stat
+			}
+			tables[key] = table
+		}
+		rarity := &TokenRarity{Traits: make([]*TraitStat, 0)}
+		for _, trait := range traits[token.ID] {
+			stat, ok := table[*trait]
+			if !ok {
+				continue
+			}
+			rarity.Traits = append(rarity.Traits, stat)
+			rarity.RarityScore += stat.RarityScore
+		}
+		rarities[token.ID] = rarity
+	}
+	return rarities, nil
+}
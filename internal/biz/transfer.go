@@ -0,0 +1,112 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Transfer is a Transfer model, the move of a Token to a new owner.
+type Transfer struct {
+	ID             int       `json:"id"`
+	Network        string    `json:"network"`
+	P              string    `json:"p"`
+	Tick           string    `json:"tick"`
+	TokenID        uint64    `json:"token_id"`
+	InscriptionUID string    `json:"inscription_uid"`
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	TxHash         string    `json:"tx_hash"`
+	BlockHeight    uint64    `json:"block_height"`
+	BlockTime      time.Time `json:"block_time"`
+	// FromLocation and ToLocation are the satpoints of the inscription of the Token
+	// before and after the Transfer, FromOutputValue is the value of the output it
+	// is moved from. ToLocation is empty if the inscription is spent as the fee.
+	FromLocation    string `json:"from_location,omitempty"`
+	ToLocation      string `json:"to_location,omitempty"`
+	FromOutputValue uint64 `json:"from_output_value,omitempty"`
+}
+
+// TokenOwner is the owner of a Token at a block height.
+type TokenOwner struct {
+	P       string
+	Tick    string
+	TokenID uint64
+	Address string
+}
+
+// Holder is an address holding the Tokens of a Collection.
+type Holder struct {
+	P        string   `json:"p"`
+	Tick     string   `json:"tick"`
+	Address  string   `json:"address"`
+	Count    int      `json:"count"`
+	TokenIDs []uint64 `json:"token_ids"`
+}
+
+type HolderListOption struct {
+	P    string
+	Tick string
+	// Height is the block height of the ownership, 0 for the latest.
+	Height uint64
+	// MinCount filters the Holders holding at least MinCount Tokens.
+	MinCount int
+}
+
+// TransferToken records the transfer of the Token by P, Tick and TokenID, and moves the Token to the
+// new owner unless a later transfer is recorded. The transfer recorded by the same tx is returned as is.
+func (uc *TokenUsecase) TransferToken(ctx context.Context, g *Transfer) (*Transfer, error) {
+	uc.log.WithContext(ctx).Debugf("TransferToken for %s %s %d to %s", g.P, g.Tick, g.TokenID, g.To)
+	return uc.repo.Transfer(ctx, g)
+}
+
+// DeleteTransfersFrom deletes the Transfers from the block height on, and moves the Tokens back
+// to their owners before the height. The deleted Transfers are returned from the latest one.
+func (uc *TokenUsecase) DeleteTransfersFrom(ctx context.Context, height uint64) ([]*Transfer, error) {
+	uc.log.WithContext(ctx).Debugf("DeleteTransfersFrom for %d", height)
+	return uc.repo.DeleteTransfersFrom(ctx, height)
+}
+
+// ListHolders lists the Holders of the Collections at the block height, grouped
+// by the Collection in the descending order of the holding count, then the address.
+func (uc *TokenUsecase) ListHolders(ctx context.Context, opt *HolderListOption) ([]*Holder, error) {
+	uc.log.WithContext(ctx).Debugf("ListHolders for %v", opt)
+	owners, err := uc.repo.ListOwners(ctx, opt.P, opt.Tick, opt.Height)
+	if err != nil {
+		return nil, err
+	}
+	type key struct{ p, tick, address string }
+	holders := make(map[key]*Holder)
+	for _, owner := range owners {
+		k := key{owner.P, owner.Tick, owner.Address}
+		h, ok := holders[k]
+		if !ok {
+			h = &Holder{P: owner.P, Tick: owner.Tick, Address: owner.Address}
+			holders[k] = h
+		}
+		h.Count++
+		h.TokenIDs = append(h.TokenIDs, owner.TokenID)
+	}
+	ret := make([]*Holder, 0, len(holders))
+	for _, h := range holders {
+		if h.Count < opt.MinCount {
+			continue
+		}
+		sort.Slice(h.TokenIDs, func(i, j int) bool { return h.TokenIDs[i] < h.TokenIDs[j] })
+		ret = append(ret, h)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i], ret[j]
+		if a.P != b.P {
+			return a.P < b.P
+		}
+		if a.Tick != b.Tick {
+			return a.Tick < b.Tick
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Address < b.Address
+	})
+	return ret, nil
+}
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/checkpoint"
+)
+
+type checkpointRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewCheckpointRepo new a checkpoint repo.
+func NewCheckpointRepo(data *Data, logger log.Logger) biz.CheckpointRepo {
+	return &checkpointRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+func (r *checkpointRepo) Get(ctx context.Context, name string) (string, error) {
+	res, err := r.data.client(ctx).Checkpoint.Query().Where(checkpoint.Name(name)).Only(ctx)
+	if ent.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return res.Value, nil
+}
+
+func (r *checkpointRepo) Save(ctx context.Context, name, value string) error {
+	return r.data.InTx(ctx, func(ctx context.Context) error {
+		client := r.data.client(ctx)
+		n, err := client.Checkpoint.Query().Where(checkpoint.Name(name)).Count(ctx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return client.Checkpoint.Create().SetName(name).SetValue(value).Exec(ctx)
+		}
+		return client.Checkpoint.Update().Where(checkpoint.Name(name)).SetValue(value).Exec(ctx)
+	})
+}
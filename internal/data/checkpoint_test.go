@@ -0,0 +1,34 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func TestCheckpoints(t *testing.T) {
+	r := require.New(t)
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	repo := NewCheckpointRepo(d, log.GetLogger())
+	mainnet := context.Background()
+	testnet := biz.NewNetworkContext(mainnet, biz.NetworkTestnet)
+
+	value, err := repo.Get(mainnet, "last_inscription_id")
+	r.NoError(err)
+	r.Equal("", value)
+
+	// the checkpoints are kept by network.
+	r.NoError(repo.Save(mainnet, "last_inscription_id", "100"))
+	r.NoError(repo.Save(testnet, "last_inscription_id", "10"))
+	r.NoError(repo.Save(mainnet, "last_inscription_id", "200"))
+	for ctx, expected := range map[context.Context]string{mainnet: "200", testnet: "10"} {
+		value, err = repo.Get(ctx, "last_inscription_id")
+		r.NoError(err)
+		r.Equal(expected, value)
+	}
+}
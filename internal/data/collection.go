@@ -27,7 +27,7 @@ func NewCollectionRepo(data *Data, logger log.Logger) biz.CollectionRepo {
 }
 
 func (r *collectionRepo) Create(ctx context.Context, g *biz.Collection) (*biz.Collection, error) {
-	res, err := r.data.db.Collection.Create().
+	c := r.data.client(ctx).Collection.Create().
 		SetP(g.P).
 		SetTick(g.Tick).
 		SetMax(g.Max).
@@ -43,17 +43,23 @@ func (r *collectionRepo) Create(ctx context.Context, g *biz.Collection) (*biz.Co
 		SetAddress(g.Address).
 		SetInscriptionID(g.InscriptionID).
 		SetInscriptionUID(g.InscriptionUID).
-		SetSig(g.Sig).
-		Save(ctx)
+		SetChildMints(g.ChildMints)
+	// the sig is optional, and an empty sig does not pass its validator
+	if g.Sig.PubKey != "" {
+		c.SetSig(g.Sig)
+	}
+	res, err := c.Save(ctx)
 	if err != nil {
 		return nil, err
 	}
+	r.data.cache.del(ctx, collectionTickKey(res.Network, res.P, res.Tick))
 	return r.fromDbCollection(res), err
 }
 
 func (r *collectionRepo) fromDbCollection(t *ent.Collection) *biz.Collection {
 	collection := &biz.Collection{
 		ID:             t.ID,
+		Network:        t.Network,
 		P:              t.P,
 		Tick:           t.Tick,
 		Max:            t.Max,
@@ -70,12 +76,13 @@ func (r *collectionRepo) fromDbCollection(t *ent.Collection) *biz.Collection {
 		InscriptionID:  t.InscriptionID,
 		InscriptionUID: t.InscriptionUID,
 		Sig:            t.Sig,
+		ChildMints:     t.ChildMints,
 	}
 	return collection
 }
 
 func (r *collectionRepo) Update(ctx context.Context, g *biz.Collection) (*biz.Collection, error) {
-	res, err := r.data.db.Collection.UpdateOneID(g.ID).
+	u := r.data.client(ctx).Collection.UpdateOneID(g.ID).
 		SetP(g.P).
 		SetTick(g.Tick).
 		SetMax(g.Max).
@@ -91,16 +98,22 @@ func (r *collectionRepo) Update(ctx context.Context, g *biz.Collection) (*biz.Co
 		SetAddress(g.Address).
 		SetInscriptionID(g.InscriptionID).
 		SetInscriptionUID(g.InscriptionUID).
-		SetSig(g.Sig).
-		Save(ctx)
+		SetChildMints(g.ChildMints)
+	if g.Sig.PubKey != "" {
+		u.SetSig(g.Sig)
+	} else {
+		u.ClearSig()
+	}
+	res, err := u.Save(ctx)
 	if err != nil {
 		return nil, err
 	}
+	r.data.cache.del(ctx, collectionTickKey(res.Network, res.P, res.Tick))
 	return r.fromDbCollection(res), err
 }
 
 func (r *collectionRepo) FindByID(ctx context.Context, id int) (*biz.Collection, error) {
-	res, err := r.data.db.Collection.Query().Where(collection.IDEQ(id)).Only(ctx)
+	res, err := r.data.client(ctx).Collection.Query().Where(collection.IDEQ(id)).Only(ctx)
 	if err == nil {
 		return r.fromDbCollection(res), nil
 	}
@@ -111,9 +124,16 @@ func (r *collectionRepo) FindByID(ctx context.Context, id int) (*biz.Collection,
 }
 
 func (r *collectionRepo) FindByTick(ctx context.Context, p, tick string) (*biz.Collection, error) {
-	res, err := r.data.db.Collection.Query().Where(collection.PEQ(p), collection.TickEQ(tick)).Only(ctx)
+	key := collectionTickKey(r.data.networkOf(ctx), p, tick)
+	var cached biz.Collection
+	if r.data.cache.get(ctx, key, &cached) {
+		return &cached, nil
+	}
+	res, err := r.data.client(ctx).Collection.Query().Where(collection.PEQ(p), collection.TickEQ(tick)).Only(ctx)
 	if err == nil {
-		return r.fromDbCollection(res), nil
+		c := r.fromDbCollection(res)
+		r.data.cache.set(ctx, key, c)
+		return c, nil
 	}
 	if ent.IsNotFound(err) {
 		return nil, nil
@@ -123,7 +143,7 @@ func (r *collectionRepo) FindByTick(ctx context.Context, p, tick string) (*biz.C
 
 func (r *collectionRepo) FindByInscriptionID(ctx context.Context, id int64) ([]*biz.Collection, error) {
 	items := make([]*biz.Collection, 0)
-	res, err := r.data.db.Collection.Query().Where(collection.InscriptionIDEQ(id)).All(ctx)
+	res, err := r.data.client(ctx).Collection.Query().Where(collection.InscriptionIDEQ(id)).All(ctx)
 	if err == nil {
 		for _, collection := range res {
 			items = append(items, r.fromDbCollection(collection))
@@ -137,7 +157,7 @@ func (r *collectionRepo) FindByInscriptionID(ctx context.Context, id int64) ([]*
 }
 
 func (r *collectionRepo) List(ctx context.Context, opts ...biz.CollectionListOption) ([]*biz.Collection, error) {
-	q := r.data.db.Collection.Query()
+	q := r.data.client(ctx).Collection.Query()
 	var opt biz.CollectionListOption
 	if len(opts) > 0 {
 		opt = opts[0]
@@ -156,6 +176,12 @@ func (r *collectionRepo) List(ctx context.Context, opts ...biz.CollectionListOpt
 	if opt.Tick != "" {
 		q = q.Where(collection.TickEQ(opt.Tick))
 	}
+	if opt.InscriptionIDFrom != 0 {
+		q = q.Where(collection.InscriptionIDGTE(opt.InscriptionIDFrom))
+	}
+	if opt.BlockHeightFrom != 0 {
+		q = q.Where(collection.BlockHeightGTE(opt.BlockHeightFrom))
+	}
 	// order format: "id,created_at,-tick"
 	if opt.Order != "" {
 		orders := strings.Split(opt.Order, ",")
@@ -188,11 +214,19 @@ func (r *collectionRepo) List(ctx context.Context, opts ...biz.CollectionListOpt
 }
 
 func (r *collectionRepo) Delete(ctx context.Context, id int) error {
-	return r.data.db.Collection.DeleteOneID(id).Exec(ctx)
+	res, err := r.data.client(ctx).Collection.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.data.client(ctx).Collection.DeleteOneID(id).Exec(ctx); err != nil {
+		return err
+	}
+	r.data.cache.del(ctx, collectionTickKey(res.Network, res.P, res.Tick))
+	return nil
 }
 
 func (r *collectionRepo) Count(ctx context.Context, opts ...biz.CollectionListOption) (int, error) {
-	q := r.data.db.Collection.Query()
+	q := r.data.client(ctx).Collection.Query()
 	var opt biz.CollectionListOption
 	if len(opts) > 0 {
 		opt = opts[0]
@@ -203,6 +237,12 @@ func (r *collectionRepo) Count(ctx context.Context, opts ...biz.CollectionListOp
 	if opt.Tick != "" {
 		q = q.Where(collection.TickEQ(opt.Tick))
 	}
+	if opt.InscriptionIDFrom != 0 {
+		q = q.Where(collection.InscriptionIDGTE(opt.InscriptionIDFrom))
+	}
+	if opt.BlockHeightFrom != 0 {
+		q = q.Where(collection.BlockHeightGTE(opt.BlockHeightFrom))
+	}
 	return q.Count(ctx)
 }
 
@@ -0,0 +1,147 @@
+package data
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/collection"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/token"
+)
+
+// searchFields are the searched collection fields and their weights in the relevance ranking.
+var searchFields = []struct {
+	column string
+	weight int
+}{
+	{collection.FieldTick, 4},
+	{collection.FieldName, 2},
+	{collection.FieldDescription, 1},
+}
+
+func (r *collectionRepo) Search(ctx context.Context, opt biz.CollectionSearchOption) ([]*biz.Collection, error) {
+	q := r.searchQuery(opt)
+	if opt.Limit != 0 && opt.Limit <= defaultListLimit {
+		q = q.Limit(opt.Limit)
+	} else {
+		q = q.Limit(defaultListLimit)
+	}
+	if opt.Offset != 0 {
+		q = q.Offset(opt.Offset)
+	}
+	query := searchTerm(opt.Query)
+	switch opt.Order {
+	case biz.CollectionSearchOrderSupply:
+		q = q.Order(ent.Desc(collection.FieldSupply))
+	case biz.CollectionSearchOrderRecentMint:
+		q = q.Order(func(s *sql.Selector) {
+			s.OrderExpr(sql.ExprFunc(func(b *sql.Builder) {
+				b.WriteString("COALESCE((SELECT MAX(").Ident(token.FieldBlockHeight).WriteString(") FROM ").Ident(token.Table).
+					WriteString(" WHERE ").Ident(token.CollectionColumn).WriteString(" = ").Ident(s.C(collection.FieldID)).
+					WriteString("), 0) DESC")
+			}))
+		}, ent.Desc(collection.FieldBlockHeight))
+	default:
+		q = q.Order(func(s *sql.Selector) {
+			// the order expression has arguments, which OrderExprFunc does not support.
+			s.OrderExpr(sql.ExprFunc(func(b *sql.Builder) {
+				writeRelevance(b, s, query)
+				b.WriteString(" DESC")
+			}))
+		}, ent.Desc(collection.FieldSupply))
+	}
+	res, err := q.Order(ent.Asc(collection.FieldID)).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*biz.Collection, 0)
+	for _, collection := range res {
+		items = append(items, r.fromDbCollection(collection))
+	}
+	return items, nil
+}
+
+func (r *collectionRepo) CountSearch(ctx context.Context, opt biz.CollectionSearchOption) (int, error) {
+	return r.searchQuery(opt).Count(ctx)
+}
+
+// searchQuery filters the collections matching any word prefix of the search fields,
+// or containing the query anywhere, or similar to it on postgres if fuzzy.
+func (r *collectionRepo) searchQuery(opt biz.CollectionSearchOption) *ent.CollectionQuery {
+	q := r.data.db.Collection.Query()
+	if opt.P != "" {
+		q = q.Where(collection.PEQ(opt.P))
+	}
+	query := searchTerm(opt.Query)
+	pattern := escapeLike(query)
+	return q.Where(func(s *sql.Selector) {
+		preds := make([]*sql.Predicate, 0)
+		for _, f := range searchFields {
+			col := s.C(f.column)
+			if !opt.Fuzzy {
+				preds = append(preds, foldLike(col, pattern+"%"), foldLike(col, "% "+pattern+"%"))
+				continue
+			}
+			preds = append(preds, foldLike(col, "%"+pattern+"%"))
+			if s.Dialect() == dialect.Postgres {
+				// uses the trigram indexes, see pg_trgm.word_similarity_threshold.
+				preds = append(preds, sql.P(func(b *sql.Builder) {
+					b.Arg(query).WriteString(" <% ").Ident(col)
+				}))
+			}
+		}
+		s.Where(sql.Or(preds...))
+	})
+}
+
+// writeRelevance writes the relevance score of the collection: an exact match scores
+// the most, then a prefix match, a word prefix match and any other match. The score
+// is weighted by the field, and by the trigram similarity on postgres.
+func writeRelevance(b *sql.Builder, s *sql.Selector, query string) {
+	pattern := escapeLike(query)
+	for i, f := range searchFields {
+		col := s.C(f.column)
+		if i > 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString("(CASE WHEN LOWER(").Ident(col).WriteString(") = ").Arg(query).
+			WriteString(" THEN ").WriteString(strconv.Itoa(4 * f.weight))
+		for j, p := range []string{pattern + "%", "% " + pattern + "%", "%" + pattern + "%"} {
+			b.WriteString(" WHEN ").Join(foldLike(col, p)).WriteString(" THEN ").WriteString(strconv.Itoa((3 - j) * f.weight))
+		}
+		b.WriteString(" ELSE 0 END)")
+		if b.Dialect() == dialect.Postgres {
+			b.WriteString(" + ").WriteString(strconv.Itoa(f.weight)).WriteString(" * word_similarity(").Arg(query).
+				WriteString(", ").Ident(col).WriteString(")")
+		}
+	}
+}
+
+// foldLike is a case-insensitive LIKE predicate, the pattern must be in lower case.
+func foldLike(col, pattern string) *sql.Predicate {
+	return sql.P(func(b *sql.Builder) {
+		switch b.Dialect() {
+		case dialect.Postgres:
+			b.Ident(col).WriteString(" ILIKE ").Arg(pattern)
+		case dialect.MySQL:
+			// the columns are in the case-sensitive utf8mb4_bin collation.
+			b.Ident(col).WriteString(" COLLATE utf8mb4_general_ci LIKE ").Arg(pattern)
+		default:
+			b.WriteString("LOWER(").Ident(col).WriteString(") LIKE ").Arg(pattern).WriteString(` ESCAPE '\'`)
+		}
+	})
+}
+
+func searchTerm(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// escapeLike escapes the wildcards of the LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
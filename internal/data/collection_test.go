@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func TestCollectionSearch(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	collectionRepo := NewCollectionRepo(d, log.GetLogger())
+	tokenRepo := NewTokenRepo(d, log.GetLogger())
+
+	for i, c := range []struct {
+		tick        string
+		name        string
+		description string
+		supply      uint64
+	}{
+		{"punk", "Ordinal Punks", "the first 100 punks", 100},
+		{"punks2", "Bitcoin Punks", "punks on bitcoin", 200},
+		{"apes", "Ordinal Apes", "apes like punk rock", 300},
+		{"rocks", "Pet Rocks", "100% rocks_on chain", 50},
+	} {
+		_, err := collectionRepo.Create(ctx, &biz.Collection{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           c.tick,
+			Name:           c.name,
+			Description:    c.description,
+			Supply:         c.supply,
+			BlockHeight:    uint64(800000 + i),
+			InscriptionID:  int64(i + 1),
+			InscriptionUID: fmt.Sprintf("%064di0", i+1),
+		})
+		r.NoError(err)
+	}
+
+	search := func(opt biz.CollectionSearchOption) []string {
+		collections, err := collectionRepo.Search(ctx, opt)
+		r.NoError(err)
+		ticks := make([]string, 0)
+		for _, c := range collections {
+			ticks = append(ticks, c.Tick)
+		}
+		return ticks
+	}
+
+	// an exact tick ranks first, then the tick prefixes, then the other fields.
+	r.Equal([]string{"punk", "punks2", "apes"}, search(biz.CollectionSearchOption{Query: "Punk"}))
+	// word prefixes of the name.
+	r.Equal([]string{"apes", "punk"}, search(biz.CollectionSearchOption{Query: "ordinal"}))
+	r.Empty(search(biz.CollectionSearchOption{Query: "oin"}))
+	r.Equal([]string{"punks2"}, search(biz.CollectionSearchOption{Query: "oin", Fuzzy: true}))
+	// the wildcards are matched literally.
+	r.Equal([]string{"rocks"}, search(biz.CollectionSearchOption{Query: "100%"}))
+	r.Equal([]string{"rocks"}, search(biz.CollectionSearchOption{Query: "s_on", Fuzzy: true}))
+	r.Empty(search(biz.CollectionSearchOption{Query: "100%", P: "brc-20"}))
+
+	r.Equal([]string{"apes", "punks2", "punk"}, search(biz.CollectionSearchOption{Query: "punk", Order: biz.CollectionSearchOrderSupply}))
+	r.Equal([]string{"punks2"}, search(biz.CollectionSearchOption{Query: "punk", Order: biz.CollectionSearchOrderSupply, Offset: 1, Limit: 1}))
+	count, err := collectionRepo.CountSearch(ctx, biz.CollectionSearchOption{Query: "punk", Offset: 1, Limit: 1})
+	r.NoError(err)
+	r.Equal(3, count)
+
+	// the collections without mints rank by their deploy height.
+	r.Equal([]string{"apes", "punks2", "punk"}, search(biz.CollectionSearchOption{Query: "punk", Order: biz.CollectionSearchOrderRecentMint}))
+	punk, err := collectionRepo.FindByTick(ctx, biz.ProtocolTypeBRC721, "punk")
+	r.NoError(err)
+	_, err = tokenRepo.Create(ctx, &biz.Token{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "punk",
+		TokenID:        1,
+		BlockHeight:    900000,
+		InscriptionID:  10,
+		InscriptionUID: fmt.Sprintf("%064di0", 10),
+		CollectionID:   punk.ID,
+	})
+	r.NoError(err)
+	r.Equal([]string{"punk", "apes", "punks2"}, search(biz.CollectionSearchOption{Query: "punk", Order: biz.CollectionSearchOrderRecentMint}))
+}
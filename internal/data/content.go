@@ -0,0 +1,299 @@
+package data
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+const (
+	defaultContentCacheSize = 1 << 30
+	defaultMaxContentSize   = 4 << 20
+)
+
+var inscriptionUIDPattern = regexp.MustCompile(`^[0-9a-f]{64}i[0-9]+$`)
+
+// contentRepo caches the content on the disk by its sha256 hash, the blobs are
+// stored at <dir>/blobs/<hash[:2]>/<hash> and shared by the inscriptions with the
+// same content, and <dir>/uids/<uid> holds the hash and the content type of an
+// inscription. The least recently used blobs are evicted once the blobs exceed
+// the max size, the uids of the evicted blobs are removed when they are read.
+type contentRepo struct {
+	dir            string
+	maxSize        int64
+	maxContentSize int64
+	log            *log.Helper
+
+	mu    sync.Mutex
+	size  int64
+	lru   *list.List
+	blobs map[string]*list.Element
+}
+
+type contentBlob struct {
+	hash string
+	size int64
+}
+
+// NewContentRepo loads the content cache from the disk.
+func NewContentRepo(c *conf.Data, logger log.Logger) (biz.ContentRepo, error) {
+	r := &contentRepo{
+		dir:            c.GetContentCache().GetDir(),
+		maxSize:        int64(c.GetContentCache().GetMaxSize()),
+		maxContentSize: int64(c.GetContentCache().GetMaxContentSize()),
+		log:            log.NewHelper(logger),
+		lru:            list.New(),
+		blobs:          make(map[string]*list.Element),
+	}
+	if r.maxSize <= 0 {
+		r.maxSize = defaultContentCacheSize
+	}
+	if r.maxContentSize <= 0 {
+		r.maxContentSize = defaultMaxContentSize
+	}
+	if r.dir == "" {
+		return r, nil
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load restores the lru of the blobs by their modification times, which are
+// touched on every read.
+func (r *contentRepo) load() error {
+	for _, dir := range []string{r.blobsDir(), r.uidsDir()} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	type blob struct {
+		contentBlob
+		modTime time.Time
+	}
+	var blobs []blob
+	err := filepath.WalkDir(r.blobsDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		// the temporary files of the interrupted writes.
+		if strings.HasPrefix(d.Name(), ".") {
+			return os.Remove(path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, blob{contentBlob{hash: d.Name(), size: info.Size()}, info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].modTime.After(blobs[j].modTime) })
+	for _, b := range blobs {
+		r.blobs[b.hash] = r.lru.PushBack(&contentBlob{hash: b.hash, size: b.size})
+		r.size += b.size
+	}
+	r.evict("")
+	r.log.Infof("loaded %d cached contents, %d bytes", len(blobs), r.size)
+	return nil
+}
+
+func (r *contentRepo) blobsDir() string {
+	return filepath.Join(r.dir, "blobs")
+}
+
+func (r *contentRepo) uidsDir() string {
+	return filepath.Join(r.dir, "uids")
+}
+
+func (r *contentRepo) blobPath(hash string) string {
+	return filepath.Join(r.blobsDir(), hash[:2], hash)
+}
+
+func (r *contentRepo) uidPath(uid string) string {
+	return filepath.Join(r.uidsDir(), uid)
+}
+
+func (r *contentRepo) Get(ctx context.Context, uid string) (*biz.Content, error) {
+	if r.dir == "" || !inscriptionUIDPattern.MatchString(uid) {
+		return nil, nil
+	}
+	b, err := os.ReadFile(r.uidPath(uid))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	hash, contentType, _ := strings.Cut(string(b), "\n")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.blobs[hash]
+	if !ok {
+		r.log.WithContext(ctx).Debugf("removing %s of the evicted content %s", uid, hash)
+		return nil, removeIfExists(r.uidPath(uid))
+	}
+	f, err := os.Open(r.blobPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	r.lru.MoveToFront(e)
+	now := time.Now()
+	if err := os.Chtimes(f.Name(), now, now); err != nil {
+		r.log.WithContext(ctx).Warnf("failed touching content %s: %v", hash, err)
+	}
+	return &biz.Content{
+		UID:         uid,
+		ContentType: contentType,
+		Hash:        hash,
+		Size:        e.Value.(*contentBlob).size,
+		Body:        f,
+	}, nil
+}
+
+func (r *contentRepo) Put(ctx context.Context, uid, contentType string, body io.Reader) (*biz.Content, error) {
+	if r.dir == "" || !inscriptionUIDPattern.MatchString(uid) {
+		return r.read(uid, contentType, body)
+	}
+	tmp, err := os.CreateTemp(r.blobsDir(), ".content-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r.limit(body))
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content of %s: %w", uid, err)
+	}
+	if size > r.maxContentSize {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", biz.ErrContentTooLarge, uid, r.maxContentSize)
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.blobs[hash]; ok {
+		r.lru.MoveToFront(e)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(r.blobPath(hash)), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(tmp.Name(), r.blobPath(hash)); err != nil {
+			return nil, err
+		}
+		r.blobs[hash] = r.lru.PushFront(&contentBlob{hash: hash, size: size})
+		r.size += size
+	}
+	if err := writeFileAtomic(r.uidPath(uid), []byte(hash+"\n"+contentType)); err != nil {
+		return nil, err
+	}
+	// the blob is opened before the eviction, it's readable even if it's evicted.
+	f, err := os.Open(r.blobPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	r.evict(hash)
+	return &biz.Content{UID: uid, ContentType: contentType, Hash: hash, Size: size, Body: f}, nil
+}
+
+func (r *contentRepo) MaxContentSize() int64 {
+	return r.maxContentSize
+}
+
+// limit reads the body up to one byte over the max content size, so that the larger
+// content is told apart without reading it all.
+func (r *contentRepo) limit(body io.Reader) io.Reader {
+	return io.LimitReader(body, r.maxContentSize+1)
+}
+
+// read reads the content into the memory without caching it.
+func (r *contentRepo) read(uid, contentType string, body io.Reader) (*biz.Content, error) {
+	b, err := io.ReadAll(r.limit(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content of %s: %w", uid, err)
+	}
+	if int64(len(b)) > r.maxContentSize {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", biz.ErrContentTooLarge, uid, r.maxContentSize)
+	}
+	h := sha256.Sum256(b)
+	return &biz.Content{
+		UID:         uid,
+		ContentType: contentType,
+		Hash:        hex.EncodeToString(h[:]),
+		Size:        int64(len(b)),
+		Body:        nopSeekCloser{bytes.NewReader(b)},
+	}, nil
+}
+
+// evict removes the least recently used blobs until the cache fits in the max
+// size, except the blob of the keep hash.
+func (r *contentRepo) evict(keep string) {
+	for e := r.lru.Back(); e != nil && r.size > r.maxSize; {
+		prev := e.Prev()
+		blob := e.Value.(*contentBlob)
+		if blob.hash != keep {
+			if err := removeIfExists(r.blobPath(blob.hash)); err != nil {
+				r.log.Warnf("failed evicting content %s: %v", blob.hash, err)
+			} else {
+				r.lru.Remove(e)
+				delete(r.blobs, blob.hash)
+				r.size -= blob.size
+			}
+		}
+		e = prev
+	}
+}
+
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error { return nil }
@@ -0,0 +1,145 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+func contentUID(i int) string {
+	return fmt.Sprintf("%064di0", i)
+}
+
+func readContent(t *testing.T, content *biz.Content) string {
+	t.Helper()
+	defer content.Body.Close()
+	b, err := io.ReadAll(content.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestContentCache(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	c := &conf.Data{ContentCache: &conf.Data_ContentCache{Dir: dir, MaxSize: 10}}
+	repo, err := NewContentRepo(c, log.GetLogger())
+	r.NoError(err)
+
+	content, err := repo.Get(ctx, contentUID(1))
+	r.NoError(err)
+	r.Nil(content)
+	content, err = repo.Put(ctx, contentUID(1), "text/plain;charset=utf-8", strings.NewReader("hello"))
+	r.NoError(err)
+	hash := sha256.Sum256([]byte("hello"))
+	r.Equal(hex.EncodeToString(hash[:]), content.Hash)
+	r.Equal(int64(5), content.Size)
+	r.Equal("hello", readContent(t, content))
+
+	// the same content is stored once.
+	_, err = repo.Put(ctx, contentUID(2), "text/plain", strings.NewReader("hello"))
+	r.NoError(err)
+	blobs, err := filepath.Glob(filepath.Join(dir, "blobs", "*", "*"))
+	r.NoError(err)
+	r.Len(blobs, 1)
+	content, err = repo.Get(ctx, contentUID(2))
+	r.NoError(err)
+	r.Equal("text/plain", content.ContentType)
+	r.Equal("hello", readContent(t, content))
+
+	// the cache survives the restarts, and uid 1 is the most recently used.
+	repo, err = NewContentRepo(c, log.GetLogger())
+	r.NoError(err)
+	_, err = repo.Put(ctx, contentUID(3), "image/png", strings.NewReader("world"))
+	r.NoError(err)
+	content, err = repo.Get(ctx, contentUID(1))
+	r.NoError(err)
+	r.Equal("hello", readContent(t, content))
+
+	// the least recently used content is evicted, the content larger than the
+	// cache is still served once.
+	content, err = repo.Put(ctx, contentUID(4), "image/png", strings.NewReader("large content"))
+	r.NoError(err)
+	r.Equal("large content", readContent(t, content))
+	for i, expected := range []bool{false, false, false, true} {
+		content, err := repo.Get(ctx, contentUID(i+1))
+		r.NoError(err)
+		r.Equal(expected, content != nil, i+1)
+		if content != nil {
+			content.Body.Close()
+		}
+	}
+	_, err = os.Stat(filepath.Join(dir, "uids", contentUID(1)))
+	r.True(os.IsNotExist(err))
+	content, err = repo.Put(ctx, contentUID(1), "text/plain", strings.NewReader("hello"))
+	r.NoError(err)
+	content.Body.Close()
+	content, err = repo.Get(ctx, contentUID(4))
+	r.NoError(err)
+	r.Nil(content)
+
+	// the uids are not used as paths unless they are valid.
+	content, err = repo.Put(ctx, "../../escape", "text/plain", strings.NewReader("hello"))
+	r.NoError(err)
+	r.Equal("hello", readContent(t, content))
+	content, err = repo.Get(ctx, "../../escape")
+	r.NoError(err)
+	r.Nil(content)
+}
+
+func TestContentMaxSize(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, c := range []*conf.Data{
+		{ContentCache: &conf.Data_ContentCache{Dir: dir, MaxContentSize: 5}},
+		{ContentCache: &conf.Data_ContentCache{MaxContentSize: 5}},
+	} {
+		repo, err := NewContentRepo(c, log.GetLogger())
+		r.NoError(err)
+		r.Equal(int64(5), repo.MaxContentSize())
+		content, err := repo.Put(ctx, contentUID(1), "text/plain", strings.NewReader("hello"))
+		r.NoError(err)
+		r.Equal("hello", readContent(t, content))
+		// the content larger than the max size is neither read in full nor cached.
+		_, err = repo.Put(ctx, contentUID(2), "text/plain", strings.NewReader("hello world"))
+		r.ErrorIs(err, biz.ErrContentTooLarge)
+		content, err = repo.Get(ctx, contentUID(2))
+		r.NoError(err)
+		r.Nil(content)
+	}
+	blobs, err := filepath.Glob(filepath.Join(dir, "blobs", "*", "*"))
+	r.NoError(err)
+	r.Len(blobs, 1)
+
+	repo, err := NewContentRepo(&conf.Data{}, log.GetLogger())
+	r.NoError(err)
+	r.Equal(int64(defaultMaxContentSize), repo.MaxContentSize())
+}
+
+func TestContentCacheDisabled(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	repo, err := NewContentRepo(&conf.Data{}, log.GetLogger())
+	r.NoError(err)
+	content, err := repo.Put(ctx, contentUID(1), "text/plain", strings.NewReader("hello"))
+	r.NoError(err)
+	_, err = content.Body.Seek(1, io.SeekStart)
+	r.NoError(err)
+	r.Equal("ello", readContent(t, content))
+	content, err = repo.Get(ctx, contentUID(1))
+	r.NoError(err)
+	r.Nil(content)
+}
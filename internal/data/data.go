@@ -3,7 +3,9 @@ package data
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/conf"
 	"github.com/adshao/ordinals-indexer/internal/data/ent"
 
@@ -17,26 +19,41 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	// init mysql driver.
+	_ "github.com/go-sql-driver/mysql"
 	// init postgresql driver.
 	_ "github.com/lib/pq"
+	// init sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewData, NewTokenRepo, NewCollectionRepo, NewRedisRepo, NewInscriptionRepo)
+var ProviderSet = wire.NewSet(NewData, NewNetwork, NewTokenRepo, NewCollectionRepo, NewRedisRepo, NewInscriptionRepo, NewTraitRepo, NewSnapshotRepo, NewContentRepo, NewLeaseRepo, NewCheckpointRepo)
 
 // Data .
 type Data struct {
-	db  *ent.Client
-	rdb *redis.Client
+	db    *ent.Client
+	rdb   *redis.Client
+	cache *cache
+	// network is the network of the data without one in the context.
+	network string
 }
 
 // NewData .
-func NewData(c *conf.Data, logger log.Logger) (*Data, func(), error) {
+func NewData(c *conf.Data, network *biz.Network, logger log.Logger) (*Data, func(), error) {
 	log := log.NewHelper(logger)
+	driverName, err := databaseDialect(c.Database.Driver)
+	if err != nil {
+		return nil, nil, err
+	}
 	drv, err := sql.Open(
-		c.Database.Driver,
+		driverName,
 		c.Database.Source,
 	)
+	if err != nil {
+		log.Errorf("failed opening connection to %s: %v", driverName, err)
+		return nil, nil, err
+	}
 	sqlDrv := dialect.DebugWithContext(drv, func(ctx context.Context, i ...interface{}) {
 		log.WithContext(ctx).Debug(i...)
 		tracer := otel.Tracer("ent.")
@@ -50,11 +67,16 @@ func NewData(c *conf.Data, logger log.Logger) (*Data, func(), error) {
 		)
 		span.End()
 	})
-	client := ent.NewClient(ent.Driver(sqlDrv))
+	migrator, err := newMigrator(drv.DB(), driverName, logger)
+	if err == nil {
+		err = migrator.Check(context.Background())
+	}
 	if err != nil {
-		log.Errorf("failed opening connection to sqlite: %v", err)
+		log.Errorf("failed checking schema of %s: %v", driverName, err)
+		drv.Close()
 		return nil, nil, err
 	}
+	client := ent.NewClient(ent.Driver(sqlDrv))
 
 	rdb := redis.NewClient(&redis.Options{
 		Addr:         c.Redis.Addr,
@@ -66,9 +88,13 @@ func NewData(c *conf.Data, logger log.Logger) (*Data, func(), error) {
 	})
 	rdb.AddHook(redisotel.TracingHook{})
 	d := &Data{
-		db:  client,
-		rdb: rdb,
+		db:      client,
+		rdb:     rdb,
+		cache:   newCache(rdb, c.Redis.CacheTtl.AsDuration(), logger),
+		network: network.Name,
 	}
+	d.scopeByNetwork()
+	d.fenceCommits()
 	return d, func() {
 		log.Info("closing the data resources")
 		if err := d.db.Close(); err != nil {
@@ -79,3 +105,59 @@ func NewData(c *conf.Data, logger log.Logger) (*Data, func(), error) {
 		}
 	}, nil
 }
+
+// InTx runs fn in a transaction carried by its Context, the repos called by fn run in
+// it, and fn joins the transaction of ctx if there is one. The fence of the lease
+// carried by ctx is advanced once in the transaction, so it is committed only if no
+// greater token has been.
+func (d *Data) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ent.TxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	tx, err := d.db.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if f, ok := biz.FenceFromContext(ctx); ok {
+		if err := advanceFence(ctx, tx.Client(), f); err != nil {
+			return rollback(tx, err)
+		}
+	} else if name, ok := biz.LockFromContext(ctx); ok {
+		if err := lockFence(ctx, tx.Client(), name); err != nil {
+			return rollback(tx, err)
+		}
+	}
+	if err := fn(ent.NewTxContext(ctx, tx)); err != nil {
+		return rollback(tx, err)
+	}
+	return tx.Commit()
+}
+
+func rollback(tx *ent.Tx, err error) error {
+	if rerr := tx.Rollback(); rerr != nil {
+		return fmt.Errorf("%w: rolling back transaction: %v", err, rerr)
+	}
+	return err
+}
+
+// client returns the client of the transaction carried by ctx, or of the database.
+func (d *Data) client(ctx context.Context) *ent.Client {
+	if tx := ent.TxFromContext(ctx); tx != nil {
+		return tx.Client()
+	}
+	return d.db
+}
+
+// databaseDialect returns the ent dialect of the configured database driver.
+func databaseDialect(driver string) (string, error) {
+	switch strings.ToLower(driver) {
+	case "", dialect.Postgres, "postgresql":
+		return dialect.Postgres, nil
+	case dialect.SQLite, "sqlite":
+		return dialect.SQLite, nil
+	case dialect.MySQL:
+		return dialect.MySQL, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
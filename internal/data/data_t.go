@@ -4,6 +4,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"testing"
 
+	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/data/ent"
 	"github.com/adshao/ordinals-indexer/internal/data/ent/enttest"
 	"github.com/adshao/ordinals-indexer/internal/data/ent/migrate"
@@ -15,9 +16,13 @@ func NewTData(t *testing.T) (*Data, func()) {
 		enttest.WithMigrateOptions(migrate.WithGlobalUniqueID(true)),
 	}
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&_fk=1", opts...)
-	return &Data{
-			db: client,
-		}, func() {
-			client.Close()
-		}
+	d := &Data{
+		db:      client,
+		network: biz.NetworkMainnet,
+	}
+	d.scopeByNetwork()
+	d.fenceCommits()
+	return d, func() {
+		client.Close()
+	}
 }
@@ -0,0 +1,155 @@
+package data
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+// testDatabases returns the databases to run the integration tests on.
+// SQLite always runs, MySQL runs when ORD_TEST_MYSQL_SOURCE is set,
+// eg: ORD_TEST_MYSQL_SOURCE="test:test@tcp(127.0.0.1:3306)/test?parseTime=True"
+func testDatabases(t *testing.T) []*conf.Data_Database {
+	dbs := []*conf.Data_Database{
+		{
+			Driver: "sqlite3",
+			Source: "file:" + filepath.Join(t.TempDir(), "ordinals.db") + "?cache=shared&_fk=1",
+		},
+	}
+	if source := os.Getenv("ORD_TEST_MYSQL_SOURCE"); source != "" {
+		dbs = append(dbs, &conf.Data_Database{
+			Driver: "mysql",
+			Source: source,
+		})
+	}
+	return dbs
+}
+
+func TestDatabaseDialect(t *testing.T) {
+	r := require.New(t)
+	for driver, expected := range map[string]string{
+		"":           "postgres",
+		"postgres":   "postgres",
+		"postgresql": "postgres",
+		"sqlite3":    "sqlite3",
+		"sqlite":     "sqlite3",
+		"mysql":      "mysql",
+		"MySQL":      "mysql",
+	} {
+		dialect, err := databaseDialect(driver)
+		r.NoError(err, driver)
+		r.Equal(expected, dialect, driver)
+	}
+	_, err := databaseDialect("oracle")
+	r.Error(err)
+}
+
+func TestNewData(t *testing.T) {
+	for _, db := range testDatabases(t) {
+		db := db
+		t.Run(db.Driver, func(t *testing.T) {
+			c := &conf.Data{
+				Database: db,
+				Redis:    &conf.Data_Redis{},
+			}
+			_, _, err := NewData(c, &biz.Network{Name: biz.NetworkMainnet}, log.GetLogger())
+			require.ErrorIs(t, err, ErrSchemaOutOfDate)
+			migrator, cleanup, err := NewMigrator(c, log.GetLogger())
+			require.NoError(t, err)
+			defer cleanup()
+			_, err = migrator.Up(context.Background(), 0)
+			require.NoError(t, err)
+			testRepos(t, c)
+		})
+	}
+}
+
+func testRepos(t *testing.T, c *conf.Data) {
+	r := require.New(t)
+	ctx := context.Background()
+	logger := log.GetLogger()
+	d, cleanup, err := NewData(c, &biz.Network{Name: biz.NetworkMainnet}, logger)
+	r.NoError(err)
+	defer cleanup()
+
+	collectionRepo := NewCollectionRepo(d, logger)
+	tokenRepo := NewTokenRepo(d, logger)
+	inscriptionRepo := NewInscriptionRepo(d, logger)
+
+	blockTime := time.Unix(1624296000, 0)
+	collection, err := collectionRepo.Create(ctx, &biz.Collection{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "ordinals",
+		Max:            1000,
+		BaseURI:        "https://ordinals.io/",
+		Attributes:     []map[string]interface{}{{"trait_type": "eyes", "value": "blue"}},
+		TxHash:         "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564",
+		BlockHeight:    788904,
+		BlockTime:      blockTime,
+		Address:        "bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf",
+		InscriptionID:  4984402,
+		InscriptionUID: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
+	})
+	r.NoError(err)
+	r.True(collection.ID > 0)
+
+	collection.Supply++
+	_, err = collectionRepo.Update(ctx, collection)
+	r.NoError(err)
+	collection, err = collectionRepo.FindByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.NotNil(collection)
+	r.Equal(uint64(1), collection.Supply)
+	r.Len(collection.Attributes, 1)
+	r.Equal(blockTime.Unix(), collection.BlockTime.Unix())
+
+	token, err := tokenRepo.Create(ctx, &biz.Token{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "ordinals",
+		TokenID:        1,
+		TxHash:         "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565",
+		BlockHeight:    788905,
+		BlockTime:      blockTime,
+		Address:        "bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf",
+		InscriptionID:  4984403,
+		InscriptionUID: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i0",
+		CollectionID:   collection.ID,
+	})
+	r.NoError(err)
+	r.True(token.ID > 0)
+	token, err = tokenRepo.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 1)
+	r.NoError(err)
+	r.NotNil(token)
+	r.Equal(collection.ID, token.CollectionID)
+	tokens, err := tokenRepo.List(ctx, biz.TokenListOption{Tick: "ordinals", Order: "-inscription_id"})
+	r.NoError(err)
+	r.Len(tokens, 1)
+	count, err := tokenRepo.Count(ctx, biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Equal(1, count)
+
+	_, err = inscriptionRepo.Create(ctx, &biz.Inscription{
+		InscriptionID: 4984403,
+		UID:           "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i0",
+		Address:       "bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf",
+		OutputValue:   546,
+		ContentType:   "application/json",
+		Timestamp:     blockTime,
+		GenesisHeight: 788905,
+		GenesisTx:     "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565",
+	})
+	r.NoError(err)
+	inscription, err := inscriptionRepo.FindByInscriptionID(ctx, 4984403)
+	r.NoError(err)
+	r.NotNil(inscription)
+	r.Equal("application/json", inscription.ContentType)
+	r.Equal(uint64(546), inscription.OutputValue)
+}
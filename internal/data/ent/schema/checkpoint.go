@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Checkpoint holds the sync checkpoints by name, eg: the last inscription id and the
+// last blocks synced of the network.
+type Checkpoint struct {
+	ent.Schema
+}
+
+func (Checkpoint) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		TimeMixin{},
+		NetworkMixin{},
+	}
+}
+
+// Fields of the Checkpoint.
+func (Checkpoint) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name"),
+		field.Text("value"),
+	}
+}
+
+// Edges of the Checkpoint.
+func (Checkpoint) Edges() []ent.Edge {
+	return nil
+}
+
+func (Checkpoint) Indexes() []ent.Index {
+	return []ent.Index{
+		// unique index.
+		index.Fields("network", "name").Unique(),
+	}
+}
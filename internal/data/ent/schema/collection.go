@@ -17,6 +17,7 @@ type Collection struct {
 func (Collection) Mixin() []ent.Mixin {
 	return []ent.Mixin{
 		TimeMixin{},
+		NetworkMixin{},
 	}
 }
 
@@ -36,9 +37,11 @@ func (Collection) Fields() []ent.Field {
 		field.Uint64("block_height"),
 		field.Time("block_time"),
 		field.String("address"),
-		field.Int64("inscription_id").Unique(),
-		field.String("inscription_uid").Unique(),
+		field.Int64("inscription_id"),
+		field.String("inscription_uid"),
 		field.JSON("sig", sig.DeploySig{}).Optional(),
+		// only the children of the deploy inscription are minted.
+		field.Bool("child_mints").Default(false),
 	}
 }
 
@@ -52,10 +55,14 @@ func (Collection) Edges() []ent.Edge {
 func (Collection) Indexes() []ent.Index {
 	return []ent.Index{
 		// unique index.
-		index.Fields("p", "tick").Unique(),
+		index.Fields("network", "p", "tick").Unique(),
+		index.Fields("network", "inscription_id").Unique(),
+		index.Fields("network", "inscription_uid").Unique(),
 		index.Fields("tx_hash"),
 		index.Fields("block_height"),
 		index.Fields("inscription_id"),
 		index.Fields("address"),
+		// the trigram indexes of the search are created by the postgres migrations
+		// only, the LIKE predicates of the other dialects can't use an index.
 	}
 }
@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Fence holds the greatest fencing token of a lease that has committed, the commits
+// of the leaders with a smaller token are refused.
+type Fence struct {
+	ent.Schema
+}
+
+func (Fence) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		TimeMixin{},
+		NetworkMixin{},
+	}
+}
+
+// Fields of the Fence.
+func (Fence) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name"),
+		field.Int64("token"),
+	}
+}
+
+// Edges of the Fence.
+func (Fence) Edges() []ent.Edge {
+	return nil
+}
+
+func (Fence) Indexes() []ent.Index {
+	return []ent.Index{
+		// unique index.
+		index.Fields("network", "name").Unique(),
+	}
+}
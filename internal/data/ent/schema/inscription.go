@@ -2,7 +2,9 @@ package schema
 
 import (
 	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
 )
 
 // Inscription holds the schema definition for the Inscription entity.
@@ -13,29 +15,61 @@ type Inscription struct {
 func (Inscription) Mixin() []ent.Mixin {
 	return []ent.Mixin{
 		TimeMixin{},
+		NetworkMixin{},
 	}
 }
 
 // Fields of the Inscription.
 func (Inscription) Fields() []ent.Field {
 	return []ent.Field{
-		field.Int64("inscription_id").Unique(),
-		field.String("uid").Unique(),
+		field.Int64("inscription_id"),
+		field.String("uid"),
 		field.String("address"),
 		field.Uint64("output_value"),
 		field.Uint64("content_length"),
 		field.String("content_type"),
 		field.Time("timestamp"),
 		field.Uint64("genesis_height"),
+		// the index of the genesis tx in its block, the inscriptions are ordered
+		// by the genesis height, tx index and the index in the tx.
+		field.Uint64("tx_index").Default(0),
 		field.Uint64("genesis_fee"),
 		field.String("genesis_tx"),
 		field.String("location"),
 		field.String("output"),
 		field.Uint64("offset"),
+		// the sat is nil if the inscription is unbound.
+		field.Uint64("sat").Optional().Nillable(),
+		field.String("sat_rarity").Default(""),
+		field.Bool("cursed").Default(false),
+		// the parent is linked by the edge once it is indexed.
+		field.String("parent_uid").Default(""),
 	}
 }
 
 // Edges of the Inscription.
 func (Inscription) Edges() []ent.Edge {
-	return nil
+	return []ent.Edge{
+		edge.To("children", Inscription.Type).
+			From("parent").
+			Unique(),
+	}
+}
+
+func (Inscription) Indexes() []ent.Index {
+	return []ent.Index{
+		// unique index.
+		index.Fields("network", "inscription_id").Unique(),
+		index.Fields("network", "uid").Unique(),
+		// the listing filters, the pages are ordered by the inscription id.
+		index.Fields("network", "address", "inscription_id"),
+		index.Fields("network", "content_type"),
+		index.Fields("network", "genesis_height", "tx_index"),
+		index.Fields("network", "timestamp"),
+		index.Fields("network", "sat"),
+		index.Fields("network", "sat_rarity", "inscription_id"),
+		index.Fields("network", "parent_uid"),
+		// the inscriptions spent by the transactions of a block.
+		index.Fields("network", "output"),
+	}
 }
@@ -25,3 +25,15 @@ func (TimeMixin) Fields() []ent.Field {
 func (TimeMixin) Edges() []ent.Edge {
 	return nil
 }
+
+// NetworkMixin namespaces the rows by the Bitcoin network they are indexed from.
+type NetworkMixin struct {
+	mixin.Schema
+}
+
+// Fields of the NetworkMixin.
+func (NetworkMixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("network").Default("mainnet").Immutable(),
+	}
+}
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
 	"entgo.io/ent/schema/index"
@@ -17,6 +18,7 @@ type Token struct {
 func (Token) Mixin() []ent.Mixin {
 	return []ent.Mixin{
 		TimeMixin{},
+		NetworkMixin{},
 	}
 }
 
@@ -30,8 +32,8 @@ func (Token) Fields() []ent.Field {
 		field.Uint64("block_height"),
 		field.Time("block_time"),
 		field.String("address"),
-		field.Int64("inscription_id").Unique(),
-		field.String("inscription_uid").Unique(),
+		field.Int64("inscription_id"),
+		field.String("inscription_uid"),
 		field.JSON("sig", sig.MintSig{}).Optional(),
 		field.String("sig_uid").Default(""),
 	}
@@ -41,16 +43,19 @@ func (Token) Fields() []ent.Field {
 func (Token) Edges() []ent.Edge {
 	return []ent.Edge{
 		edge.From("collection", Collection.Type).Ref("tokens").Unique(),
+		edge.To("traits", Trait.Type).Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("transfers", Transfer.Type).Annotations(entsql.OnDelete(entsql.Cascade)),
 	}
 }
 
 func (Token) Indexes() []ent.Index {
 	return []ent.Index{
 		// unique index.
-		index.Fields("p", "tick", "token_id").Unique(),
+		index.Fields("network", "p", "tick", "token_id").Unique(),
 		index.Fields("address"),
 		index.Fields("tx_hash"),
-		index.Fields("inscription_id").Unique(),
+		index.Fields("network", "inscription_id").Unique(),
+		index.Fields("network", "inscription_uid").Unique(),
 		index.Fields("block_height"),
 		index.Fields("sig_uid"),
 	}
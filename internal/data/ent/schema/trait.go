@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Trait holds the schema definition for the Trait entity.
+type Trait struct {
+	ent.Schema
+}
+
+func (Trait) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		TimeMixin{},
+		NetworkMixin{},
+	}
+}
+
+// Fields of the Trait.
+func (Trait) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("tick"),
+		field.String("p").Default("brc-721"),
+		field.String("trait_type"),
+		field.String("value"),
+	}
+}
+
+// Edges of the Trait.
+func (Trait) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("token", Token.Type).Ref("traits").Unique().Required(),
+	}
+}
+
+func (Trait) Indexes() []ent.Index {
+	return []ent.Index{
+		// the trait frequency table and the trait filter of the collection,
+		// the traits are prefixed on mysql to fit the index key length.
+		index.Fields("p", "tick", "trait_type", "value").
+			Annotations(entsql.PrefixColumn("trait_type", 64), entsql.PrefixColumn("value", 64)),
+		// unique index.
+		index.Fields("trait_type", "value").Edges("token").Unique(),
+	}
+}
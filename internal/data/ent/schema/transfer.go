@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Transfer holds the schema definition for the Transfer entity.
+type Transfer struct {
+	ent.Schema
+}
+
+func (Transfer) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		TimeMixin{},
+		NetworkMixin{},
+	}
+}
+
+// Fields of the Transfer.
+func (Transfer) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("tick"),
+		field.String("p").Default("brc-721"),
+		field.String("inscription_uid"),
+		field.String("from_address"),
+		field.String("to_address"),
+		field.String("tx_hash"),
+		field.Uint64("block_height"),
+		field.Time("block_time"),
+		// the satpoints of the inscription before and after the transfer, and the value
+		// of the output it is moved from, to restore the inscription on a rollback.
+		field.String("from_location").Default(""),
+		field.String("to_location").Default(""),
+		field.Uint64("from_output_value").Default(0),
+	}
+}
+
+// Edges of the Transfer.
+func (Transfer) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("token", Token.Type).Ref("transfers").Unique().Required(),
+	}
+}
+
+func (Transfer) Indexes() []ent.Index {
+	return []ent.Index{
+		// the owners of the collection at a block height.
+		index.Fields("p", "tick", "block_height"),
+		index.Fields("to_address"),
+		// the index of the last transfer of a token at a block height is created by
+		// the migrations only, ent puts the edge column after the fields.
+		// unique index.
+		index.Fields("tx_hash").Edges("token").Unique(),
+	}
+}
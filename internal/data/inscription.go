@@ -7,10 +7,14 @@ import (
 	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/data/ent"
 	"github.com/adshao/ordinals-indexer/internal/data/ent/inscription"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/predicate"
 
 	"github.com/go-kratos/kratos/v2/log"
 )
 
+// maxInscriptionListLimit is the max limit of listing the inscriptions.
+const maxInscriptionListLimit = 1000
+
 type inscriptionRepo struct {
 	data                 *Data
 	log                  *log.Helper
@@ -27,29 +31,41 @@ func NewInscriptionRepo(data *Data, logger log.Logger) biz.InscriptionRepo {
 }
 
 func (r *inscriptionRepo) Create(ctx context.Context, g *biz.Inscription) (*biz.Inscription, error) {
-	res, err := r.data.db.Inscription.Create().
+	res, err := r.data.client(ctx).Inscription.Create().
 		SetInscriptionID(g.InscriptionID).
 		SetUID(g.UID).
 		SetAddress(g.Address).
-		SetOutput(g.Output).
+		SetOutputValue(g.OutputValue).
 		SetContentLength(g.ContentLength).
 		SetContentType(g.ContentType).
 		SetTimestamp(g.Timestamp).
 		SetGenesisHeight(g.GenesisHeight).
+		SetTxIndex(g.TxIndex).
 		SetGenesisFee(g.GenesisFee).
 		SetGenesisTx(g.GenesisTx).
 		SetLocation(g.Location).
 		SetOutput(g.Output).
 		SetOffset(g.Offset).
+		SetNillableSat(g.Sat).
+		SetSatRarity(g.SatRarity).
+		SetCursed(g.Cursed).
+		SetParentUID(g.ParentUID).
 		Save(ctx)
 	if err != nil {
 		return nil, err
 	}
+	// link the inscription to its parent, and the children indexed before it.
+	err = linkInscriptionParents(ctx, r.data.client(ctx).Inscription, inscription.Or(inscription.ID(res.ID), inscription.ParentUID(res.UID)))
+	if err != nil {
+		return nil, err
+	}
 	return r.fromDbInscription(res), nil
 }
 
 func (r *inscriptionRepo) fromDbInscription(t *ent.Inscription) *biz.Inscription {
 	token := &biz.Inscription{
+		ID:            t.ID,
+		Network:       t.Network,
 		InscriptionID: t.InscriptionID,
 		UID:           t.UID,
 		Address:       t.Address,
@@ -58,39 +74,69 @@ func (r *inscriptionRepo) fromDbInscription(t *ent.Inscription) *biz.Inscription
 		ContentType:   t.ContentType,
 		Timestamp:     t.Timestamp,
 		GenesisHeight: t.GenesisHeight,
+		TxIndex:       t.TxIndex,
 		GenesisFee:    t.GenesisFee,
 		GenesisTx:     t.GenesisTx,
 		Location:      t.Location,
 		Output:        t.Output,
 		Offset:        t.Offset,
+		Sat:           t.Sat,
+		SatRarity:     t.SatRarity,
+		Cursed:        t.Cursed,
+		ParentUID:     t.ParentUID,
 	}
 	return token
 }
 
 func (r *inscriptionRepo) Update(ctx context.Context, g *biz.Inscription) (*biz.Inscription, error) {
-	u := r.data.db.Inscription.UpdateOneID(g.ID).
+	u := r.data.client(ctx).Inscription.UpdateOneID(g.ID).
 		SetInscriptionID(g.InscriptionID).
 		SetUID(g.UID).
 		SetAddress(g.Address).
-		SetOutput(g.Output).
+		SetOutputValue(g.OutputValue).
 		SetContentLength(g.ContentLength).
 		SetContentType(g.ContentType).
 		SetTimestamp(g.Timestamp).
 		SetGenesisHeight(g.GenesisHeight).
+		SetTxIndex(g.TxIndex).
 		SetGenesisFee(g.GenesisFee).
 		SetGenesisTx(g.GenesisTx).
 		SetLocation(g.Location).
 		SetOutput(g.Output).
-		SetOffset(g.Offset)
+		SetOffset(g.Offset).
+		SetSatRarity(g.SatRarity).
+		SetCursed(g.Cursed).
+		SetParentUID(g.ParentUID).
+		ClearParent()
+	if g.Sat != nil {
+		u.SetSat(*g.Sat)
+	} else {
+		u.ClearSat()
+	}
 	res, err := u.Save(ctx)
 	if err != nil {
 		return nil, err
 	}
+	err = linkInscriptionParents(ctx, r.data.client(ctx).Inscription, inscription.ID(res.ID))
+	if err != nil {
+		return nil, err
+	}
 	return r.fromDbInscription(res), nil
 }
 
 func (r *inscriptionRepo) FindByInscriptionID(ctx context.Context, inscriptionID int64) (*biz.Inscription, error) {
-	res, err := r.data.db.Inscription.Query().Where(inscription.InscriptionID(inscriptionID)).Only(ctx)
+	res, err := r.data.client(ctx).Inscription.Query().Where(inscription.InscriptionID(inscriptionID)).Only(ctx)
+	if err == nil {
+		return r.fromDbInscription(res), nil
+	}
+	if ent.IsNotFound(err) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+func (r *inscriptionRepo) FindByUID(ctx context.Context, uid string) (*biz.Inscription, error) {
+	res, err := r.data.client(ctx).Inscription.Query().Where(inscription.UID(uid)).Only(ctx)
 	if err == nil {
 		return r.fromDbInscription(res), nil
 	}
@@ -101,12 +147,12 @@ func (r *inscriptionRepo) FindByInscriptionID(ctx context.Context, inscriptionID
 }
 
 func (r *inscriptionRepo) List(ctx context.Context, opts ...biz.InscriptionListOption) ([]*biz.Inscription, error) {
-	q := r.data.db.Inscription.Query()
+	q := r.data.client(ctx).Inscription.Query()
 	var opt biz.InscriptionListOption
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
-	if opt.Limit > 0 && opt.Limit <= defaultListLimit {
+	if opt.Limit > 0 && opt.Limit <= maxInscriptionListLimit {
 		q = q.Limit(opt.Limit)
 	} else {
 		q = q.Limit(defaultListLimit)
@@ -114,10 +160,24 @@ func (r *inscriptionRepo) List(ctx context.Context, opts ...biz.InscriptionListO
 	if opt.Offset != 0 {
 		q = q.Offset(opt.Offset)
 	}
-	// order format: field1,-field2
-	if opt.Order != "" {
-		orders := strings.Split(opt.Order, ",")
-		for _, order := range orders {
+	q = r.filter(q, opt)
+	if opt.Cursor != nil {
+		if opt.Desc {
+			q = q.Where(inscription.InscriptionIDLTE(*opt.Cursor))
+		} else {
+			q = q.Where(inscription.InscriptionIDGTE(*opt.Cursor))
+		}
+	}
+	if opt.Order == "" {
+		// the inscription id is unique, the pages are stable.
+		if opt.Desc {
+			q = q.Order(ent.Desc(inscription.FieldInscriptionID))
+		} else {
+			q = q.Order(ent.Asc(inscription.FieldInscriptionID))
+		}
+	} else {
+		// order format: field1,-field2
+		for _, order := range strings.Split(opt.Order, ",") {
 			asc := true
 			field := strings.ToLower(order)
 			if strings.HasPrefix(order, "-") {
@@ -156,10 +216,93 @@ func (r *inscriptionRepo) inOrderFieldsWhiteList(field string) bool {
 }
 
 func (r *inscriptionRepo) Delete(ctx context.Context, id int) error {
-	return r.data.db.Inscription.DeleteOneID(id).Exec(ctx)
+	return r.data.client(ctx).Inscription.DeleteOneID(id).Exec(ctx)
+}
+
+func (r *inscriptionRepo) DeleteFrom(ctx context.Context, inscriptionID int64) (int, error) {
+	return r.data.client(ctx).Inscription.Delete().Where(inscription.InscriptionIDGTE(inscriptionID)).Exec(ctx)
+}
+
+func (r *inscriptionRepo) DeleteFromHeight(ctx context.Context, height uint64) (int, error) {
+	return r.data.client(ctx).Inscription.Delete().Where(inscription.GenesisHeightGTE(height)).Exec(ctx)
 }
 
 func (r *inscriptionRepo) Count(ctx context.Context, opts ...biz.InscriptionListOption) (int, error) {
-	q := r.data.db.Inscription.Query()
+	q := r.data.client(ctx).Inscription.Query()
+	if len(opts) > 0 {
+		q = r.filter(q, opts[0])
+	}
 	return q.Count(ctx)
 }
+
+// filter filters the inscriptions by the list option.
+func (r *inscriptionRepo) filter(q *ent.InscriptionQuery, opt biz.InscriptionListOption) *ent.InscriptionQuery {
+	if opt.Address != "" {
+		q = q.Where(inscription.Address(opt.Address))
+	}
+	if opt.ContentType != "" {
+		q = q.Where(inscription.ContentTypeHasPrefix(opt.ContentType))
+	}
+	if opt.GenesisHeightFrom != 0 {
+		q = q.Where(inscription.GenesisHeightGTE(opt.GenesisHeightFrom))
+	}
+	if opt.GenesisHeightTo != 0 {
+		q = q.Where(inscription.GenesisHeightLTE(opt.GenesisHeightTo))
+	}
+	if !opt.TimestampFrom.IsZero() {
+		q = q.Where(inscription.TimestampGTE(opt.TimestampFrom))
+	}
+	if !opt.TimestampTo.IsZero() {
+		q = q.Where(inscription.TimestampLTE(opt.TimestampTo))
+	}
+	if opt.GenesisFeeFrom != 0 {
+		q = q.Where(inscription.GenesisFeeGTE(opt.GenesisFeeFrom))
+	}
+	if opt.GenesisFeeTo != 0 {
+		q = q.Where(inscription.GenesisFeeLTE(opt.GenesisFeeTo))
+	}
+	if len(opt.SatRarity) > 0 {
+		q = q.Where(inscription.SatRarityIn(opt.SatRarity...))
+	}
+	if opt.SatFrom != 0 {
+		q = q.Where(inscription.SatGTE(opt.SatFrom))
+	}
+	if opt.SatTo != 0 {
+		q = q.Where(inscription.SatLTE(opt.SatTo))
+	}
+	if opt.Cursed != nil {
+		q = q.Where(inscription.Cursed(*opt.Cursed))
+	}
+	if opt.ParentUID != "" {
+		q = q.Where(inscription.HasParentWith(inscription.UID(opt.ParentUID)))
+	}
+	if len(opt.Outputs) > 0 {
+		q = q.Where(inscription.OutputIn(opt.Outputs...))
+	}
+	return q
+}
+
+// linkInscriptionParents links the unlinked inscriptions matching the predicates
+// to their parents which are indexed.
+func linkInscriptionParents(ctx context.Context, c *ent.InscriptionClient, ps ...predicate.Inscription) error {
+	children, err := c.Query().
+		Where(inscription.ParentUIDNEQ(""), inscription.Not(inscription.HasParent())).
+		Where(ps...).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		parent, err := c.Query().Where(inscription.UID(child.ParentUID)).Only(ctx)
+		if ent.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := c.UpdateOneID(child.ID).SetParentID(parent.ID).Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func inscriptionIDs(inscriptions []*biz.Inscription) []int64 {
+	var ids []int64
+	for _, ins := range inscriptions {
+		ids = append(ids, ins.InscriptionID)
+	}
+	return ids
+}
+
+func TestInscriptionListFilters(t *testing.T) {
+	r := require.New(t)
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	uc := biz.NewInscriptionUsecase(NewInscriptionRepo(d, log.GetLogger()), log.GetLogger())
+	ctx := context.Background()
+
+	contentTypes := []string{"image/png", "text/plain;charset=utf-8", "image/webp", "application/json"}
+	for i := int64(1); i <= 8; i++ {
+		address := "bc1qalice"
+		if i%2 == 0 {
+			address = "bc1qbob"
+		}
+		// the even inscriptions are on the first sats of the blocks.
+		sat := uint64(i) * 5000000000
+		if i%2 == 1 {
+			sat++
+		}
+		_, err := uc.SaveInscription(ctx, &biz.Inscription{
+			InscriptionID: i,
+			UID:           fmt.Sprintf("%064di0", i),
+			Address:       address,
+			ContentType:   contentTypes[i%4],
+			Timestamp:     time.Unix(1700000000+i*600, 0),
+			GenesisHeight: uint64(800000 + i),
+			GenesisFee:    uint64(i * 1000),
+			Sat:           &sat,
+			SatRarity:     biz.SatRarity(sat),
+			Cursed:        i%4 == 3,
+		})
+		r.NoError(err)
+	}
+	// the inscriptions are saved once by the uid.
+	_, err := uc.SaveInscription(ctx, &biz.Inscription{InscriptionID: 1, UID: fmt.Sprintf("%064di0", 1), Address: "bc1qcarol", ContentType: "text/plain"})
+	r.NoError(err)
+	count, err := uc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(8, count)
+
+	ins, err := uc.FindByInscriptionID(ctx, 1)
+	r.NoError(err)
+	r.Nil(ins.Sat)
+	ins, err = uc.FindByInscriptionID(ctx, 2)
+	r.NoError(err)
+	r.Equal(uint64(10000000000), *ins.Sat)
+	r.Equal(biz.SatRarityUncommon, ins.SatRarity)
+
+	cursed, blessed := true, false
+	for name, tc := range map[string]struct {
+		opt      biz.InscriptionListOption
+		expected []int64
+	}{
+		"all":          {biz.InscriptionListOption{}, []int64{1, 2, 3, 4, 5, 6, 7, 8}},
+		"address":      {biz.InscriptionListOption{Address: "bc1qcarol"}, []int64{1}},
+		"desc":         {biz.InscriptionListOption{Desc: true}, []int64{8, 7, 6, 5, 4, 3, 2, 1}},
+		"content type": {biz.InscriptionListOption{ContentType: "text/"}, []int64{1, 5}},
+		"height":       {biz.InscriptionListOption{GenesisHeightFrom: 800003, GenesisHeightTo: 800005}, []int64{3, 4, 5}},
+		"timestamp":    {biz.InscriptionListOption{TimestampFrom: time.Unix(1700000000+7*600, 0)}, []int64{7, 8}},
+		"fee":          {biz.InscriptionListOption{GenesisFeeTo: 2000}, []int64{1, 2}},
+		"combined":     {biz.InscriptionListOption{Address: "bc1qbob", ContentType: "image/", GenesisFeeFrom: 5000}, []int64{6, 8}},
+		"sat rarity":   {biz.InscriptionListOption{SatRarity: []string{biz.SatRarityUncommon, biz.SatRarityRare}}, []int64{2, 4, 6, 8}},
+		"sat":          {biz.InscriptionListOption{SatFrom: 15000000000, SatTo: 30000000000}, []int64{3, 4, 5, 6}},
+		"cursed":       {biz.InscriptionListOption{Cursed: &cursed}, []int64{3, 7}},
+		"blessed":      {biz.InscriptionListOption{Cursed: &blessed}, []int64{1, 2, 4, 5, 6, 8}},
+	} {
+		inscriptions, err := uc.ListInscriptions(ctx, &tc.opt)
+		r.NoError(err, name)
+		r.Equal(tc.expected, inscriptionIDs(inscriptions), name)
+	}
+
+	// the cursors are stable while the new inscriptions are indexed.
+	opt := &biz.InscriptionListOption{Limit: 3, Desc: true}
+	inscriptions, next, err := uc.ListInscriptionsPage(ctx, opt)
+	r.NoError(err)
+	r.Equal([]int64{8, 7, 6}, inscriptionIDs(inscriptions))
+	r.Equal(int64(5), *next)
+	_, err = uc.SaveInscription(ctx, &biz.Inscription{InscriptionID: 9, UID: fmt.Sprintf("%064di0", 9)})
+	r.NoError(err)
+	opt.Cursor = next
+	inscriptions, next, err = uc.ListInscriptionsPage(ctx, opt)
+	r.NoError(err)
+	r.Equal([]int64{5, 4, 3}, inscriptionIDs(inscriptions))
+	opt.Cursor = next
+	inscriptions, next, err = uc.ListInscriptionsPage(ctx, opt)
+	r.NoError(err)
+	r.Equal([]int64{2, 1}, inscriptionIDs(inscriptions))
+	r.Nil(next)
+
+	n, err := uc.DeleteInscriptionsFrom(ctx, 7)
+	r.NoError(err)
+	r.Equal(3, n)
+	count, err = uc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(6, count)
+}
+
+func TestInscriptionParents(t *testing.T) {
+	r := require.New(t)
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	uc := biz.NewInscriptionUsecase(NewInscriptionRepo(d, log.GetLogger()), log.GetLogger())
+	ctx := context.Background()
+	uid := func(i int64) string {
+		return fmt.Sprintf("%064di0", i)
+	}
+	children := func(parent int64) []int64 {
+		inscriptions, err := uc.ListInscriptions(ctx, &biz.InscriptionListOption{ParentUID: uid(parent)})
+		r.NoError(err)
+		return inscriptionIDs(inscriptions)
+	}
+
+	// the child indexed before its parent is linked once the parent is indexed.
+	for _, ins := range []*biz.Inscription{
+		{InscriptionID: 3, UID: uid(3), ParentUID: uid(1)},
+		{InscriptionID: 1, UID: uid(1)},
+		{InscriptionID: 2, UID: uid(2), ParentUID: uid(1)},
+		{InscriptionID: 4, UID: uid(4), ParentUID: uid(3)},
+	} {
+		_, err := uc.SaveInscription(ctx, ins)
+		r.NoError(err)
+	}
+	r.Equal([]int64{2, 3}, children(1))
+	r.Equal([]int64{4}, children(3))
+	r.Empty(children(2))
+	ins, err := uc.FindByUID(ctx, uid(4))
+	r.NoError(err)
+	r.Equal(uid(3), ins.ParentUID)
+
+	// the parent is relinked when the inscription is re-processed.
+	_, err = uc.SaveInscription(ctx, &biz.Inscription{InscriptionID: 4, UID: uid(4), ParentUID: uid(2)})
+	r.NoError(err)
+	r.Empty(children(3))
+	r.Equal([]int64{4}, children(2))
+
+	// the parents are deleted with their children on the rollbacks.
+	n, err := uc.DeleteInscriptionsFrom(ctx, 2)
+	r.NoError(err)
+	r.Equal(3, n)
+	r.Empty(children(1))
+}
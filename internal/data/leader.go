@@ -0,0 +1,166 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/fence"
+)
+
+// leaseKey is the key of the lease holder, its value is "<holder> <token>".
+func leaseKey(name string) string {
+	return "lease:" + name
+}
+
+// leaseTokenKey is the key of the last fencing token of the lease.
+func leaseTokenKey(name string) string {
+	return "lease:" + name + ":token"
+}
+
+func leaseValue(lease *biz.Lease) string {
+	return lease.Holder + " " + strconv.FormatInt(lease.Token, 10)
+}
+
+var (
+	// acquireLeaseScript sets the holder with the next fencing token if the lease is free.
+	acquireLeaseScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("SET", KEYS[1], ARGV[1] .. " " .. token, "PX", ARGV[2])
+return token`)
+	// renewLeaseScript extends the lease if it is still held by the holder.
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	// releaseLeaseScript deletes the lease if it is still held by the holder.
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+type leaseRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewLeaseRepo new a lease repo, the leases are held in redis.
+func NewLeaseRepo(data *Data, logger log.Logger) biz.LeaseRepo {
+	return &leaseRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+func (r *leaseRepo) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*biz.Lease, error) {
+	if strings.Contains(holder, " ") {
+		return nil, fmt.Errorf("invalid lease holder %q", holder)
+	}
+	token, err := acquireLeaseScript.Run(ctx, r.data.rdb, []string{leaseKey(name), leaseTokenKey(name)}, holder, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if token == 0 {
+		return nil, nil
+	}
+	return &biz.Lease{Name: name, Holder: holder, Token: token}, nil
+}
+
+func (r *leaseRepo) RenewLease(ctx context.Context, lease *biz.Lease, ttl time.Duration) error {
+	renewed, err := renewLeaseScript.Run(ctx, r.data.rdb, []string{leaseKey(lease.Name)}, leaseValue(lease), ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if renewed == 0 {
+		return biz.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *leaseRepo) ReleaseLease(ctx context.Context, lease *biz.Lease) error {
+	return releaseLeaseScript.Run(ctx, r.data.rdb, []string{leaseKey(lease.Name)}, leaseValue(lease)).Err()
+}
+
+func (r *leaseRepo) CheckLease(ctx context.Context, lease *biz.Lease) error {
+	value, err := r.data.rdb.Get(ctx, leaseKey(lease.Name)).Result()
+	if err == redis.Nil || (err == nil && value != leaseValue(lease)) {
+		return biz.ErrLeaseLost
+	}
+	return err
+}
+
+// fenceCommits asserts the mutations of the contexts carrying a biz.Fence are run in
+// the transactions of InTx, which advance the fence of the lease to the token of the
+// context, so the mutations are committed only if no greater token has been.
+func (d *Data) fenceCommits() {
+	d.db.Use(func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			f, ok := biz.FenceFromContext(ctx)
+			if !ok || m.Type() == ent.TypeFence {
+				return next.Mutate(ctx, m)
+			}
+			if txm, ok := m.(interface{ Tx() (*ent.Tx, error) }); ok && ent.TxFromContext(ctx) != nil {
+				if _, err := txm.Tx(); err == nil {
+					return next.Mutate(ctx, m)
+				}
+			}
+			return nil, fmt.Errorf("mutation of %s fenced by token %d of lease %s is out of a transaction", m.Type(), f.Token, f.Name)
+		})
+	})
+}
+
+// advanceFence advances the fence of the lease to the token in the transaction of the
+// client, it returns biz.ErrLeaseLost if a greater token has been committed. The row
+// of the fence is locked until the transaction is done, so a stale leader can't commit
+// after a new leader has.
+func advanceFence(ctx context.Context, client *ent.Client, f *biz.Fence) error {
+	err := client.Fence.Update().
+		Where(fence.Name(f.Name), fence.TokenLTE(f.Token)).
+		SetToken(f.Token).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	// the fence is read back, as mysql doesn't count the rows updated to the same token.
+	res, err := client.Fence.Query().Where(fence.Name(f.Name)).Only(ctx)
+	switch {
+	case ent.IsNotFound(err):
+		return client.Fence.Create().SetName(f.Name).SetToken(f.Token).Exec(ctx)
+	case err != nil:
+		return err
+	case res.Token != f.Token:
+		return fmt.Errorf("%w: token %d of lease %s is fenced by %d", biz.ErrLeaseLost, f.Token, f.Name, res.Token)
+	}
+	return nil
+}
+
+// lockFence locks the row of the fence of the name until the transaction of the client
+// is done, without advancing its token. It serializes the commits of the writers of a
+// lease when the leader election is disabled.
+func lockFence(ctx context.Context, client *ent.Client, name string) error {
+	err := client.Fence.Update().
+		Where(fence.Name(name)).
+		AddToken(0).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	exist, err := client.Fence.Query().Where(fence.Name(name)).Exist(ctx)
+	if err != nil || exist {
+		return err
+	}
+	return client.Fence.Create().SetName(name).SetToken(0).Exec(ctx)
+}
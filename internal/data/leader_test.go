@@ -0,0 +1,167 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func TestLeaseRepo(t *testing.T) {
+	r := require.New(t)
+	d, mr, cleanup := newTCachedData(t)
+	defer cleanup()
+	repo := NewLeaseRepo(d, log.GetLogger())
+	ctx := context.Background()
+
+	lease, err := repo.AcquireLease(ctx, "sync", "host-1", time.Second)
+	r.NoError(err)
+	r.Equal(&biz.Lease{Name: "sync", Holder: "host-1", Token: 1}, lease)
+	r.NoError(repo.CheckLease(ctx, lease))
+
+	// the lease is not acquired by another holder until it expires.
+	other, err := repo.AcquireLease(ctx, "sync", "host-2", time.Second)
+	r.NoError(err)
+	r.Nil(other)
+	mr.FastForward(800 * time.Millisecond)
+	r.NoError(repo.RenewLease(ctx, lease, time.Second))
+	mr.FastForward(800 * time.Millisecond)
+	r.NoError(repo.CheckLease(ctx, lease))
+
+	// the standby takes over the expired lease with a new fencing token.
+	mr.FastForward(time.Second)
+	r.ErrorIs(repo.CheckLease(ctx, lease), biz.ErrLeaseLost)
+	other, err = repo.AcquireLease(ctx, "sync", "host-2", time.Second)
+	r.NoError(err)
+	r.Equal(int64(2), other.Token)
+	r.ErrorIs(repo.RenewLease(ctx, lease, time.Second), biz.ErrLeaseLost)
+	r.ErrorIs(repo.CheckLease(ctx, lease), biz.ErrLeaseLost)
+
+	// the stale leader can't release the lease of the new one.
+	r.NoError(repo.ReleaseLease(ctx, lease))
+	r.NoError(repo.CheckLease(ctx, other))
+	r.NoError(repo.ReleaseLease(ctx, other))
+	r.ErrorIs(repo.CheckLease(ctx, other), biz.ErrLeaseLost)
+
+	// the same holder gets a new token after the release.
+	lease, err = repo.AcquireLease(ctx, "sync", "host-1", time.Second)
+	r.NoError(err)
+	r.Equal(int64(3), lease.Token)
+	lease.Token = 2
+	r.ErrorIs(repo.CheckLease(ctx, lease), biz.ErrLeaseLost)
+}
+
+func TestFenceCommits(t *testing.T) {
+	r := require.New(t)
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	repo := NewInscriptionRepo(d, log.GetLogger())
+	tokenRepo := NewTokenRepo(d, log.GetLogger())
+	create := func(ctx context.Context, id int64) error {
+		return d.InTx(ctx, func(ctx context.Context) error {
+			_, err := repo.Create(ctx, &biz.Inscription{InscriptionID: id, UID: fmt.Sprintf("%064di0", id)})
+			return err
+		})
+	}
+	leader := biz.NewFenceContext(context.Background(), &biz.Fence{Name: "sync:mainnet", Token: 1})
+	r.NoError(create(leader, 1))
+	ins, err := repo.FindByInscriptionID(leader, 1)
+	r.NoError(err)
+	ins.Address = "bc1qalice"
+	r.NoError(d.InTx(leader, func(ctx context.Context) error {
+		_, err := repo.Update(ctx, ins)
+		return err
+	}))
+	// the fenced mutations are run in a transaction only.
+	_, err = repo.Create(leader, &biz.Inscription{InscriptionID: 5, UID: fmt.Sprintf("%064di0", 5)})
+	r.ErrorContains(err, "out of a transaction")
+
+	// the stale leader can't commit once the new leader has committed.
+	standby := biz.NewFenceContext(context.Background(), &biz.Fence{Name: "sync:mainnet", Token: 2})
+	r.NoError(create(standby, 2))
+	r.ErrorIs(create(leader, 3), biz.ErrLeaseLost)
+	r.ErrorIs(d.InTx(leader, func(ctx context.Context) error {
+		_, err := repo.DeleteFrom(ctx, 1)
+		return err
+	}), biz.ErrLeaseLost)
+	var collection *biz.Collection
+	r.NoError(d.InTx(standby, func(ctx context.Context) error {
+		collection, err = NewCollectionRepo(d, log.GetLogger()).Create(ctx, &biz.Collection{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Max: 10, InscriptionID: 1, InscriptionUID: fmt.Sprintf("%064di0", 1)})
+		return err
+	}))
+	token := &biz.Token{P: biz.ProtocolTypeBRC721, Tick: "ordinals", TokenID: 1, InscriptionID: 2, InscriptionUID: fmt.Sprintf("%064di0", 2), CollectionID: collection.ID}
+	transfer := &biz.Transfer{P: biz.ProtocolTypeBRC721, Tick: "ordinals", TokenID: 1, To: "bc1qbob", TxHash: fmt.Sprintf("%064d", 1)}
+	mint := func(ctx context.Context) error {
+		if _, err := tokenRepo.Create(ctx, token); err != nil {
+			return err
+		}
+		_, err := tokenRepo.Transfer(ctx, transfer)
+		return err
+	}
+	r.ErrorIs(d.InTx(leader, mint), biz.ErrLeaseLost)
+	// the mutations of a transaction are committed together, or none of them.
+	aborted := errors.New("aborted")
+	r.ErrorIs(d.InTx(standby, func(ctx context.Context) error {
+		if err := mint(ctx); err != nil {
+			return err
+		}
+		return aborted
+	}), aborted)
+	for _, count := range []func(context.Context) (int, error){d.db.Token.Query().Count, d.db.Transfer.Query().Count} {
+		n, err := count(context.Background())
+		r.NoError(err)
+		r.Zero(n)
+	}
+	r.NoError(d.InTx(standby, mint))
+	transfers, err := d.db.Transfer.Query().Count(context.Background())
+	r.NoError(err)
+	r.Equal(1, transfers)
+	count, err := repo.Count(context.Background())
+	r.NoError(err)
+	r.Equal(2, count)
+
+	// the fences of the leases and the writes without a fence are independent.
+	other := biz.NewFenceContext(context.Background(), &biz.Fence{Name: "sync:testnet", Token: 1})
+	r.NoError(create(other, 3))
+	r.NoError(create(context.Background(), 4))
+	_, err = repo.Create(context.Background(), &biz.Inscription{InscriptionID: 6, UID: fmt.Sprintf("%064di0", 6)})
+	r.NoError(err)
+	fences, err := d.db.Fence.Query().All(context.Background())
+	r.NoError(err)
+	r.Len(fences, 2)
+}
+
+func TestLockCommits(t *testing.T) {
+	r := require.New(t)
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	repo := NewInscriptionRepo(d, log.GetLogger())
+	locked := biz.NewLockContext(context.Background(), "sync:mainnet")
+	for id := int64(1); id <= 2; id++ {
+		r.NoError(d.InTx(locked, func(ctx context.Context) error {
+			_, err := repo.Create(ctx, &biz.Inscription{InscriptionID: id, UID: fmt.Sprintf("%064di0", id)})
+			return err
+		}))
+	}
+	count, err := repo.Count(context.Background())
+	r.NoError(err)
+	r.Equal(2, count)
+
+	// the lock holds the row of the fence without advancing its token.
+	f, err := d.db.Fence.Query().Only(context.Background())
+	r.NoError(err)
+	r.Equal("sync:mainnet", f.Name)
+	r.Zero(f.Token)
+	leader := biz.NewFenceContext(context.Background(), &biz.Fence{Name: "sync:mainnet", Token: 1})
+	r.NoError(d.InTx(leader, func(ctx context.Context) error { return nil }))
+	r.NoError(d.InTx(locked, func(ctx context.Context) error { return nil }))
+	f, err = d.db.Fence.Query().Only(context.Background())
+	r.NoError(err)
+	r.Equal(int64(1), f.Token)
+}
@@ -0,0 +1,540 @@
+package data
+
+import (
+	"bufio"
+	"context"
+	stdsql "database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/ordinals-indexer/internal/conf"
+
+	"entgo.io/ent/dialect"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// migrationsFS holds the versioned migrations of every dialect, they are generated
+// from the ent schema by migrations/main.go.
+//
+//go:embed migrations/*/*.sql
+var migrationsFS embed.FS
+
+const (
+	// migrationsTable records the applied migration versions.
+	migrationsTable = "schema_migrations"
+	// atlasRevisionsTable records the migrations applied by the atlas cli before.
+	atlasRevisionsTable = "atlas_schema_revisions"
+)
+
+// ErrSchemaOutOfDate is returned when the database has pending migrations.
+var ErrSchemaOutOfDate = errors.New("database schema is out of date")
+
+// ErrSchemaDirty is returned when a migration failed halfway on a dialect without
+// transactional DDL, the schema must be fixed by hand and the migration forced.
+var ErrSchemaDirty = errors.New("database schema is dirty")
+
+// Migration is a versioned schema migration.
+type Migration struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Dirty is set while the migration is applied or reverted without a transaction,
+	// it is left set if it failed halfway.
+	Dirty bool
+
+	up   string
+	down string
+}
+
+// Migrator applies the versioned migrations of the configured database dialect.
+type Migrator struct {
+	db      *stdsql.DB
+	dialect string
+	// transactional is false if the DDL statements commit implicitly, eg: on MySQL,
+	// the migrations are then recorded as dirty until they are done.
+	transactional bool
+	migrations    []*Migration
+	log           *log.Helper
+}
+
+// NewMigrator opens the configured database and loads its migrations.
+func NewMigrator(c *conf.Data, logger log.Logger) (*Migrator, func(), error) {
+	driverName, err := databaseDialect(c.Database.Driver)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := stdsql.Open(driverName, c.Database.Source)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := newMigrator(db, driverName, logger)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return m, func() {
+		if err := db.Close(); err != nil {
+			m.log.Error(err)
+		}
+	}, nil
+}
+
+func newMigrator(db *stdsql.DB, driverName string, logger log.Logger) (*Migrator, error) {
+	migrations, err := loadMigrations(driverName)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:            db,
+		dialect:       driverName,
+		transactional: driverName != dialect.MySQL,
+		migrations:    migrations,
+		log:           log.NewHelper(logger),
+	}, nil
+}
+
+// loadMigrations reads the <version>_<name>.up.sql and <version>_<name>.down.sql files
+// of the dialect, ordered by version.
+func loadMigrations(driverName string) ([]*Migration, error) {
+	dir := path.Join("migrations", driverName)
+	names, err := fs.Glob(migrationsFS, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, name := range names {
+		base := path.Base(name)
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+		version, desc, _ := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		v, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", name)
+		}
+		b, err := migrationsFS.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[v]
+		if !ok {
+			m = &Migration{Version: v, Name: desc}
+			byVersion[v] = m
+		}
+		if direction == "up" {
+			m.up = string(b)
+		} else {
+			m.down = string(b)
+		}
+	}
+	if len(byVersion) == 0 {
+		return nil, fmt.Errorf("no migrations found for %s", driverName)
+	}
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("missing up migration of version %d", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Status returns all migrations with their applied state.
+func (m *Migrator) Status(ctx context.Context) ([]*Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]*Migration, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		s := *mg
+		if r, ok := applied[mg.Version]; ok {
+			s.Applied, s.AppliedAt, s.Dirty = true, r.appliedAt, r.dirty
+		}
+		migrations = append(migrations, &s)
+	}
+	return migrations, nil
+}
+
+// checkDirty returns ErrSchemaDirty if a migration failed halfway.
+func checkDirty(migrations []*Migration) error {
+	for _, mg := range migrations {
+		if mg.Dirty {
+			return fmt.Errorf("%w: migration %d_%s failed halfway, fix the schema by hand and force it", ErrSchemaDirty, mg.Version, mg.Name)
+		}
+	}
+	return nil
+}
+
+// Check returns ErrSchemaOutOfDate if there are pending migrations, and ErrSchemaDirty
+// if a migration failed halfway.
+func (m *Migrator) Check(ctx context.Context) error {
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkDirty(migrations); err != nil {
+		return err
+	}
+	var pending []string
+	for _, mg := range migrations {
+		if !mg.Applied {
+			pending = append(pending, strconv.FormatInt(mg.Version, 10))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s, run the migrate command first", ErrSchemaOutOfDate, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up applies at most steps pending migrations, all of them if steps <= 0.
+// It returns the applied migrations.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	if err := m.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDirty(migrations); err != nil {
+		return nil, err
+	}
+	var done []*Migration
+	for _, mg := range migrations {
+		if mg.Applied {
+			continue
+		}
+		if steps > 0 && len(done) >= steps {
+			break
+		}
+		m.log.Infof("applying migration %d_%s", mg.Version, mg.Name)
+		if err := m.apply(ctx, mg); err != nil {
+			return done, fmt.Errorf("failed applying migration %d_%s: %w", mg.Version, mg.Name, err)
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// apply runs the up migration and records it, in one transaction if the DDL is
+// transactional. Otherwise it is recorded as dirty before it runs, and clean once done.
+func (m *Migrator) apply(ctx context.Context, mg *Migration) error {
+	insert := fmt.Sprintf("INSERT INTO %s (version, name, applied_at, dirty) VALUES (%s, %s, %s, %s)", migrationsTable, m.placeholder(1), m.placeholder(2), m.placeholder(3), m.placeholder(4))
+	if m.transactional {
+		return m.exec(ctx, mg.up, insert, mg.Version, mg.Name, time.Now().UTC(), false)
+	}
+	if _, err := m.db.ExecContext(ctx, insert, mg.Version, mg.Name, time.Now().UTC(), true); err != nil {
+		return err
+	}
+	if err := m.execStatements(ctx, m.db, mg.up); err != nil {
+		return fmt.Errorf("%w, the migration is left dirty", err)
+	}
+	return m.setDirty(ctx, mg.Version, false)
+}
+
+// revert runs the down migration and deletes its record, in one transaction if the
+// DDL is transactional. Otherwise it is marked dirty before it runs.
+func (m *Migrator) revert(ctx context.Context, mg *Migration) error {
+	del := fmt.Sprintf("DELETE FROM %s WHERE version = %s", migrationsTable, m.placeholder(1))
+	if m.transactional {
+		return m.exec(ctx, mg.down, del, mg.Version)
+	}
+	if err := m.setDirty(ctx, mg.Version, true); err != nil {
+		return err
+	}
+	if err := m.execStatements(ctx, m.db, mg.down); err != nil {
+		return fmt.Errorf("%w, the migration is left dirty", err)
+	}
+	_, err := m.db.ExecContext(ctx, del, mg.Version)
+	return err
+}
+
+// Force records the migration as applied and clean, or as not applied, without
+// running it, once the schema of a dirty migration has been fixed by hand.
+func (m *Migrator) Force(ctx context.Context, version int64, applied bool) error {
+	if err := m.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+	var mg *Migration
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			mg = migration
+		}
+	}
+	if mg == nil {
+		return fmt.Errorf("migration %d not found", version)
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = %s", migrationsTable, m.placeholder(1)), version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if applied {
+		insert := fmt.Sprintf("INSERT INTO %s (version, name, applied_at, dirty) VALUES (%s, %s, %s, %s)", migrationsTable, m.placeholder(1), m.placeholder(2), m.placeholder(3), m.placeholder(4))
+		if _, err := tx.ExecContext(ctx, insert, mg.Version, mg.Name, time.Now().UTC(), false); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) setDirty(ctx context.Context, version int64, dirty bool) error {
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET dirty = %s WHERE version = %s", migrationsTable, m.placeholder(1), m.placeholder(2)), dirty, version)
+	return err
+}
+
+// Down reverts at most steps applied migrations, the latest one if steps <= 0.
+// It returns the reverted migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if err := m.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	if steps <= 0 {
+		steps = 1
+	}
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDirty(migrations); err != nil {
+		return nil, err
+	}
+	var done []*Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		mg := migrations[i]
+		if !mg.Applied {
+			continue
+		}
+		if mg.down == "" {
+			return done, fmt.Errorf("migration %d_%s can not be reverted", mg.Version, mg.Name)
+		}
+		m.log.Infof("reverting migration %d_%s", mg.Version, mg.Name)
+		if err := m.revert(ctx, mg); err != nil {
+			return done, fmt.Errorf("failed reverting migration %d_%s: %w", mg.Version, mg.Name, err)
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// exec runs the statements of a migration file and records it in one transaction, it
+// is only atomic on the dialects with transactional DDL.
+func (m *Migrator) exec(ctx context.Context, script, record string, args ...interface{}) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := m.execStatements(ctx, tx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// execStatements runs the statements of a migration file one by one.
+func (m *Migrator) execStatements(ctx context.Context, db interface {
+	ExecContext(context.Context, string, ...interface{}) (stdsql.Result, error)
+}, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w, statement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// ensureMigrationsTable creates the migrations table, importing the versions
+// applied by the atlas cli if the database was migrated by it before.
+func (m *Migrator) ensureMigrationsTable(ctx context.Context) error {
+	exists, err := m.tableExists(ctx, migrationsTable)
+	if err != nil || exists {
+		return err
+	}
+	applied, err := m.atlasVersions(ctx)
+	if err != nil {
+		return err
+	}
+	bigint := "bigint"
+	if m.dialect == dialect.SQLite {
+		bigint = "integer"
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (version %s NOT NULL, name varchar(255) NOT NULL, applied_at timestamp NOT NULL, dirty boolean NOT NULL DEFAULT false, PRIMARY KEY (version))", migrationsTable, bigint))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (%s, %s, %s)", migrationsTable, m.placeholder(1), m.placeholder(2), m.placeholder(3))
+	for _, mg := range m.migrations {
+		appliedAt, ok := applied[mg.Version]
+		if !ok {
+			continue
+		}
+		m.log.Infof("importing migration %d_%s applied by atlas", mg.Version, mg.Name)
+		if _, err := tx.ExecContext(ctx, insert, mg.Version, mg.Name, appliedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// appliedMigration is the record of an applied migration.
+type appliedMigration struct {
+	appliedAt time.Time
+	dirty     bool
+}
+
+// appliedVersions returns the applied migration versions, when they were applied, and
+// whether they are dirty.
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int64]appliedMigration, error) {
+	exists, err := m.tableExists(ctx, migrationsTable)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]appliedMigration)
+	if !exists {
+		versions, err := m.atlasVersions(ctx)
+		for version, appliedAt := range versions {
+			applied[version] = appliedMigration{appliedAt: appliedAt}
+		}
+		return applied, err
+	}
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("SELECT version, applied_at, dirty FROM %s", migrationsTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			version int64
+			r       appliedMigration
+		)
+		if err := rows.Scan(&version, &r.appliedAt, &r.dirty); err != nil {
+			return nil, err
+		}
+		applied[version] = r
+	}
+	return applied, rows.Err()
+}
+
+// atlasVersions returns the versions fully applied by the atlas cli, which was
+// used to migrate postgres databases before.
+func (m *Migrator) atlasVersions(ctx context.Context) (map[int64]time.Time, error) {
+	applied := make(map[int64]time.Time)
+	if m.dialect != dialect.Postgres {
+		return applied, nil
+	}
+	var schema string
+	err := m.db.QueryRowContext(ctx, "SELECT table_schema FROM information_schema.tables WHERE table_name = $1 LIMIT 1", atlasRevisionsTable).Scan(&schema)
+	if errors.Is(err, stdsql.ErrNoRows) {
+		return applied, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf(`SELECT version, executed_at FROM %q.%q WHERE applied = total`, schema, atlasRevisionsTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			version    string
+			executedAt time.Time
+		)
+		if err := rows.Scan(&version, &executedAt); err != nil {
+			return nil, err
+		}
+		v, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			continue
+		}
+		applied[v] = executedAt
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) tableExists(ctx context.Context, table string) (bool, error) {
+	var query string
+	switch m.dialect {
+	case dialect.SQLite:
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	case dialect.MySQL:
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	default:
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1"
+	}
+	var n int
+	if err := m.db.QueryRowContext(ctx, query, table).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (m *Migrator) placeholder(i int) string {
+	if m.dialect == dialect.Postgres {
+		return "$" + strconv.Itoa(i)
+	}
+	return "?"
+}
+
+// splitStatements splits a migration file into statements, every statement ends with
+// a semicolon at the end of a line and comment lines are dropped.
+func splitStatements(script string) []string {
+	var (
+		stmts []string
+		stmt  strings.Builder
+	)
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		if stmt.Len() > 0 {
+			stmt.WriteString("\n")
+		}
+		stmt.WriteString(line)
+		if strings.HasSuffix(line, ";") {
+			stmts = append(stmts, strings.TrimSuffix(stmt.String(), ";"))
+			stmt.Reset()
+		}
+	}
+	if stmt.Len() > 0 {
+		stmts = append(stmts, stmt.String())
+	}
+	return stmts
+}
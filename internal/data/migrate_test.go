@@ -0,0 +1,139 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	atlasmigrate "ariga.io/atlas/sql/migrate"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+)
+
+func TestMigrationsChecksum(t *testing.T) {
+	for _, driverName := range []string{"postgres", "sqlite3", "mysql"} {
+		dir, err := atlasmigrate.NewLocalDir(filepath.Join("migrations", driverName))
+		require.NoError(t, err, driverName)
+		require.NoError(t, atlasmigrate.Validate(dir), driverName)
+		migrations, err := loadMigrations(driverName)
+		require.NoError(t, err, driverName)
+		for _, m := range migrations {
+			require.NotEmpty(t, m.down, "%s: %d_%s", driverName, m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	for _, db := range testDatabases(t) {
+		db := db
+		t.Run(db.Driver, func(t *testing.T) {
+			r := require.New(t)
+			ctx := context.Background()
+			migrator, cleanup, err := NewMigrator(&conf.Data{Database: db}, log.GetLogger())
+			r.NoError(err)
+			defer cleanup()
+
+			r.ErrorIs(migrator.Check(ctx), ErrSchemaOutOfDate)
+			migrations, err := migrator.Up(ctx, 1)
+			r.NoError(err)
+			r.Len(migrations, 1)
+			migrations, err = migrator.Up(ctx, 0)
+			r.NoError(err)
+			r.Len(migrations, len(migrator.migrations)-1)
+			r.NoError(migrator.Check(ctx))
+			status, err := migrator.Status(ctx)
+			r.NoError(err)
+			for _, m := range status {
+				r.True(m.Applied, m.Name)
+			}
+
+			// the migrations must create the same schema as the ent schema.
+			driverName, err := databaseDialect(db.Driver)
+			r.NoError(err)
+			client, err := ent.Open(driverName, db.Source)
+			r.NoError(err)
+			defer client.Close()
+			var buf bytes.Buffer
+			r.NoError(client.Schema.WriteTo(ctx, &buf))
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				r.True(line == "BEGIN;" || line == "COMMIT;" || strings.HasPrefix(line, "PRAGMA "), buf.String())
+			}
+
+			migrations, err = migrator.Down(ctx, 0)
+			r.NoError(err)
+			r.Len(migrations, 1)
+			r.Equal(status[len(status)-1].Version, migrations[0].Version)
+			r.ErrorIs(migrator.Check(ctx), ErrSchemaOutOfDate)
+			migrations, err = migrator.Down(ctx, len(status))
+			r.NoError(err)
+			r.Len(migrations, len(status)-1)
+			status, err = migrator.Status(ctx)
+			r.NoError(err)
+			for _, m := range status {
+				r.False(m.Applied, m.Name)
+			}
+		})
+	}
+}
+
+func TestMigratorDirty(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	db := testDatabases(t)[0]
+	migrator, cleanup, err := NewMigrator(&conf.Data{Database: db}, log.GetLogger())
+	r.NoError(err)
+	defer cleanup()
+	_, err = migrator.Up(ctx, 0)
+	r.NoError(err)
+
+	// the DDL commits implicitly on MySQL, the failed migration is left dirty.
+	migrator.transactional = false
+	migrator.migrations = append(migrator.migrations, &Migration{
+		Version: 99991231000000,
+		Name:    "broken",
+		up:      "CREATE TABLE broken (id integer);\nCREATE TABLE broken (id integer);",
+		down:    "DROP TABLE broken;",
+	})
+	_, err = migrator.Up(ctx, 0)
+	r.ErrorContains(err, "left dirty")
+	r.ErrorIs(migrator.Check(ctx), ErrSchemaDirty)
+	_, err = migrator.Up(ctx, 0)
+	r.ErrorIs(err, ErrSchemaDirty)
+	_, err = migrator.Down(ctx, 1)
+	r.ErrorIs(err, ErrSchemaDirty)
+	status, err := migrator.Status(ctx)
+	r.NoError(err)
+	r.True(status[len(status)-1].Dirty)
+
+	// the schema is fixed by hand, and the migration is forced.
+	r.NoError(migrator.Force(ctx, 99991231000000, true))
+	r.NoError(migrator.Check(ctx))
+	migrations, err := migrator.Down(ctx, 1)
+	r.NoError(err)
+	r.Len(migrations, 1)
+	r.ErrorIs(migrator.Check(ctx), ErrSchemaOutOfDate)
+	migrator.migrations[len(migrator.migrations)-1].up = "CREATE TABLE broken (id integer);"
+	migrations, err = migrator.Up(ctx, 0)
+	r.NoError(err)
+	r.Len(migrations, 1)
+	r.NoError(migrator.Check(ctx))
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := splitStatements(`-- create "a" table
+CREATE TABLE "a" ("id" bigint NOT NULL,
+  PRIMARY KEY ("id"));
+
+-- create index "a_id" to table: "a"
+CREATE INDEX "a_id" ON "a" ("id");
+`)
+	require.Equal(t, []string{
+		"CREATE TABLE \"a\" (\"id\" bigint NOT NULL,\nPRIMARY KEY (\"id\"))",
+		"CREATE INDEX \"a_id\" ON \"a\" (\"id\")",
+	}, stmts)
+}
@@ -0,0 +1,6 @@
+-- reverse: create "tokens" table
+DROP TABLE `tokens`;
+-- reverse: create "inscriptions" table
+DROP TABLE `inscriptions`;
+-- reverse: create "collections" table
+DROP TABLE `collections`;
@@ -0,0 +1,6 @@
+-- create "collections" table
+CREATE TABLE `collections` (`id` bigint NOT NULL AUTO_INCREMENT, `created_at` timestamp NOT NULL, `updated_at` timestamp NOT NULL, `tick` varchar(255) NOT NULL, `p` varchar(255) NOT NULL DEFAULT "brc-721", `max` bigint unsigned NOT NULL, `supply` bigint unsigned NOT NULL, `base_uri` varchar(255) NOT NULL, `name` varchar(255) NOT NULL, `description` varchar(255) NOT NULL, `image` varchar(255) NOT NULL, `attributes` json NOT NULL, `tx_hash` varchar(255) NOT NULL, `block_height` bigint unsigned NOT NULL, `block_time` timestamp NOT NULL, `address` varchar(255) NOT NULL, `inscription_id` bigint NOT NULL, `inscription_uid` varchar(255) NOT NULL, `sig` json NULL, PRIMARY KEY (`id`), INDEX `collection_address` (`address`), INDEX `collection_block_height` (`block_height`), INDEX `collection_inscription_id` (`inscription_id`), UNIQUE INDEX `collection_p_tick` (`p`, `tick`), INDEX `collection_tx_hash` (`tx_hash`), UNIQUE INDEX `inscription_id` (`inscription_id`), UNIQUE INDEX `inscription_uid` (`inscription_uid`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "inscriptions" table
+CREATE TABLE `inscriptions` (`id` bigint NOT NULL AUTO_INCREMENT, `created_at` timestamp NOT NULL, `updated_at` timestamp NOT NULL, `inscription_id` bigint NOT NULL, `uid` varchar(255) NOT NULL, `address` varchar(255) NOT NULL, `output_value` bigint unsigned NOT NULL, `content_length` bigint unsigned NOT NULL, `content_type` varchar(255) NOT NULL, `timestamp` timestamp NOT NULL, `genesis_height` bigint unsigned NOT NULL, `genesis_fee` bigint unsigned NOT NULL, `genesis_tx` varchar(255) NOT NULL, `location` varchar(255) NOT NULL, `output` varchar(255) NOT NULL, `offset` bigint unsigned NOT NULL, PRIMARY KEY (`id`), UNIQUE INDEX `inscription_id` (`inscription_id`), UNIQUE INDEX `uid` (`uid`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
+-- create "tokens" table
+CREATE TABLE `tokens` (`id` bigint NOT NULL AUTO_INCREMENT, `created_at` timestamp NOT NULL, `updated_at` timestamp NOT NULL, `tick` varchar(255) NOT NULL, `p` varchar(255) NOT NULL DEFAULT "brc-721", `token_id` bigint unsigned NOT NULL, `tx_hash` varchar(255) NOT NULL, `block_height` bigint unsigned NOT NULL, `block_time` timestamp NOT NULL, `address` varchar(255) NOT NULL, `inscription_id` bigint NOT NULL, `inscription_uid` varchar(255) NOT NULL, `sig` json NULL, `sig_uid` varchar(255) NOT NULL DEFAULT "", `collection_tokens` bigint NULL, PRIMARY KEY (`id`), UNIQUE INDEX `inscription_id` (`inscription_id`), UNIQUE INDEX `inscription_uid` (`inscription_uid`), INDEX `token_address` (`address`), INDEX `token_block_height` (`block_height`), UNIQUE INDEX `token_inscription_id` (`inscription_id`), UNIQUE INDEX `token_p_tick_token_id` (`p`, `tick`, `token_id`), INDEX `token_sig_uid` (`sig_uid`), INDEX `token_tx_hash` (`tx_hash`), INDEX `tokens_collections_tokens` (`collection_tokens`), CONSTRAINT `tokens_collections_tokens` FOREIGN KEY (`collection_tokens`) REFERENCES `collections` (`id`) ON UPDATE NO ACTION ON DELETE SET NULL) CHARSET utf8mb4 COLLATE utf8mb4_bin;
@@ -0,0 +1,2 @@
+-- reverse: modify "collections" table
+ALTER TABLE `collections` DROP INDEX `collection_tick_trgm`, DROP INDEX `collection_name_trgm`, DROP INDEX `collection_description_trgm`;
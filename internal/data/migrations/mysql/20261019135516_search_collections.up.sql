@@ -0,0 +1,2 @@
+-- modify "collections" table
+ALTER TABLE `collections` ADD INDEX `collection_description_trgm` (`description`), ADD INDEX `collection_name_trgm` (`name`), ADD INDEX `collection_tick_trgm` (`tick`);
@@ -0,0 +1,2 @@
+-- reverse: create "traits" table
+DROP TABLE `traits`;
@@ -0,0 +1,2 @@
+-- create "traits" table
+CREATE TABLE `traits` (`id` bigint NOT NULL AUTO_INCREMENT, `created_at` timestamp NOT NULL, `updated_at` timestamp NOT NULL, `tick` varchar(255) NOT NULL, `p` varchar(255) NOT NULL DEFAULT "brc-721", `trait_type` varchar(255) NOT NULL, `value` varchar(255) NOT NULL, `token_traits` bigint NOT NULL, PRIMARY KEY (`id`), INDEX `trait_p_tick_trait_type_value` (`p`, `tick`, `trait_type` (64), `value` (64)), UNIQUE INDEX `trait_trait_type_value_token_traits` (`trait_type`, `value`, `token_traits`), INDEX `traits_tokens_traits` (`token_traits`), CONSTRAINT `traits_tokens_traits` FOREIGN KEY (`token_traits`) REFERENCES `tokens` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE) CHARSET utf8mb4 COLLATE utf8mb4_bin;
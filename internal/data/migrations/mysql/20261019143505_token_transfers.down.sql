@@ -0,0 +1,2 @@
+-- reverse: create "transfers" table
+DROP TABLE `transfers`;
@@ -0,0 +1,2 @@
+-- create "transfers" table
+CREATE TABLE `transfers` (`id` bigint NOT NULL AUTO_INCREMENT, `created_at` timestamp NOT NULL, `updated_at` timestamp NOT NULL, `tick` varchar(255) NOT NULL, `p` varchar(255) NOT NULL DEFAULT "brc-721", `inscription_uid` varchar(255) NOT NULL, `from_address` varchar(255) NOT NULL, `to_address` varchar(255) NOT NULL, `tx_hash` varchar(255) NOT NULL, `block_height` bigint unsigned NOT NULL, `block_time` timestamp NOT NULL, `token_transfers` bigint NOT NULL, PRIMARY KEY (`id`), INDEX `transfer_p_tick_block_height` (`p`, `tick`, `block_height`), INDEX `transfer_to_address` (`to_address`), UNIQUE INDEX `transfer_tx_hash_token_transfers` (`tx_hash`, `token_transfers`), INDEX `transfers_tokens_transfers` (`token_transfers`), CONSTRAINT `transfers_tokens_transfers` FOREIGN KEY (`token_transfers`) REFERENCES `tokens` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE) CHARSET utf8mb4 COLLATE utf8mb4_bin;
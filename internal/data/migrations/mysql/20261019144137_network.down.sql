@@ -0,0 +1,10 @@
+-- reverse: modify "transfers" table
+ALTER TABLE `transfers` DROP COLUMN `network`;
+-- reverse: modify "traits" table
+ALTER TABLE `traits` DROP COLUMN `network`;
+-- reverse: modify "tokens" table
+ALTER TABLE `tokens` DROP INDEX `token_network_inscription_uid`, DROP INDEX `token_network_inscription_id`, DROP INDEX `token_network_p_tick_token_id`, DROP COLUMN `network`, ADD UNIQUE INDEX `token_p_tick_token_id` (`p`, `tick`, `token_id`), ADD UNIQUE INDEX `token_inscription_id` (`inscription_id`), ADD UNIQUE INDEX `inscription_uid` (`inscription_uid`), ADD UNIQUE INDEX `inscription_id` (`inscription_id`);
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP INDEX `inscription_network_uid`, DROP INDEX `inscription_network_inscription_id`, DROP COLUMN `network`, ADD UNIQUE INDEX `uid` (`uid`), ADD UNIQUE INDEX `inscription_id` (`inscription_id`);
+-- reverse: modify "collections" table
+ALTER TABLE `collections` DROP INDEX `collection_network_inscription_uid`, DROP INDEX `collection_network_inscription_id`, DROP INDEX `collection_network_p_tick`, DROP COLUMN `network`, ADD UNIQUE INDEX `inscription_uid` (`inscription_uid`), ADD UNIQUE INDEX `inscription_id` (`inscription_id`), ADD UNIQUE INDEX `collection_p_tick` (`p`, `tick`);
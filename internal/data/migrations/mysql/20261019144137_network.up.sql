@@ -0,0 +1,10 @@
+-- modify "collections" table
+ALTER TABLE `collections` DROP INDEX `collection_p_tick`, DROP INDEX `inscription_id`, DROP INDEX `inscription_uid`, ADD COLUMN `network` varchar(255) NOT NULL DEFAULT "mainnet", ADD UNIQUE INDEX `collection_network_p_tick` (`network`, `p`, `tick`), ADD UNIQUE INDEX `collection_network_inscription_id` (`network`, `inscription_id`), ADD UNIQUE INDEX `collection_network_inscription_uid` (`network`, `inscription_uid`);
+-- modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP INDEX `inscription_id`, DROP INDEX `uid`, ADD COLUMN `network` varchar(255) NOT NULL DEFAULT "mainnet", ADD UNIQUE INDEX `inscription_network_inscription_id` (`network`, `inscription_id`), ADD UNIQUE INDEX `inscription_network_uid` (`network`, `uid`);
+-- modify "tokens" table
+ALTER TABLE `tokens` DROP INDEX `inscription_id`, DROP INDEX `inscription_uid`, DROP INDEX `token_inscription_id`, DROP INDEX `token_p_tick_token_id`, ADD COLUMN `network` varchar(255) NOT NULL DEFAULT "mainnet", ADD UNIQUE INDEX `token_network_p_tick_token_id` (`network`, `p`, `tick`, `token_id`), ADD UNIQUE INDEX `token_network_inscription_id` (`network`, `inscription_id`), ADD UNIQUE INDEX `token_network_inscription_uid` (`network`, `inscription_uid`);
+-- modify "traits" table
+ALTER TABLE `traits` ADD COLUMN `network` varchar(255) NOT NULL DEFAULT "mainnet";
+-- modify "transfers" table
+ALTER TABLE `transfers` ADD COLUMN `network` varchar(255) NOT NULL DEFAULT "mainnet";
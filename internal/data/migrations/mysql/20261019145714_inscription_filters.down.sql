@@ -0,0 +1,2 @@
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP INDEX `inscription_network_timestamp`, DROP INDEX `inscription_network_genesis_height`, DROP INDEX `inscription_network_content_type`, DROP INDEX `inscription_network_address_inscription_id`;
@@ -0,0 +1,2 @@
+-- modify "inscriptions" table
+ALTER TABLE `inscriptions` ADD INDEX `inscription_network_address_inscription_id` (`network`, `address`, `inscription_id`), ADD INDEX `inscription_network_content_type` (`network`, `content_type`), ADD INDEX `inscription_network_genesis_height` (`network`, `genesis_height`), ADD INDEX `inscription_network_timestamp` (`network`, `timestamp`);
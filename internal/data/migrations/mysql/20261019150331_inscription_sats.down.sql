@@ -0,0 +1,2 @@
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP INDEX `inscription_network_sat_rarity_inscription_id`, DROP INDEX `inscription_network_sat`, DROP COLUMN `cursed`, DROP COLUMN `sat_rarity`, DROP COLUMN `sat`;
@@ -0,0 +1,2 @@
+-- modify "inscriptions" table
+ALTER TABLE `inscriptions` ADD COLUMN `sat` bigint unsigned NULL, ADD COLUMN `sat_rarity` varchar(255) NOT NULL DEFAULT "", ADD COLUMN `cursed` bool NOT NULL DEFAULT 0, ADD INDEX `inscription_network_sat` (`network`, `sat`), ADD INDEX `inscription_network_sat_rarity_inscription_id` (`network`, `sat_rarity`, `inscription_id`);
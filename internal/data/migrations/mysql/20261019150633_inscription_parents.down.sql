@@ -0,0 +1,4 @@
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP FOREIGN KEY `inscriptions_inscriptions_children`, DROP INDEX `inscriptions_inscriptions_children`, DROP INDEX `inscription_network_parent_uid`, DROP COLUMN `inscription_children`, DROP COLUMN `parent_uid`;
+-- reverse: modify "collections" table
+ALTER TABLE `collections` DROP COLUMN `child_mints`;
@@ -0,0 +1,4 @@
+-- modify "collections" table
+ALTER TABLE `collections` ADD COLUMN `child_mints` bool NOT NULL DEFAULT 0;
+-- modify "inscriptions" table
+ALTER TABLE `inscriptions` ADD COLUMN `parent_uid` varchar(255) NOT NULL DEFAULT "", ADD COLUMN `inscription_children` bigint NULL, ADD INDEX `inscription_network_parent_uid` (`network`, `parent_uid`), ADD INDEX `inscriptions_inscriptions_children` (`inscription_children`), ADD CONSTRAINT `inscriptions_inscriptions_children` FOREIGN KEY (`inscription_children`) REFERENCES `inscriptions` (`id`) ON UPDATE NO ACTION ON DELETE SET NULL;
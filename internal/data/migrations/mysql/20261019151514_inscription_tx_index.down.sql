@@ -0,0 +1,2 @@
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP INDEX `inscription_network_genesis_height_tx_index`, DROP COLUMN `tx_index`, ADD INDEX `inscription_network_genesis_height` (`network`, `genesis_height`);
@@ -0,0 +1,2 @@
+-- modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP INDEX `inscription_network_genesis_height`, ADD COLUMN `tx_index` bigint unsigned NOT NULL DEFAULT 0, ADD INDEX `inscription_network_genesis_height_tx_index` (`network`, `genesis_height`, `tx_index`);
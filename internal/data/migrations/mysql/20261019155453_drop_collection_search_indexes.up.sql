@@ -0,0 +1,2 @@
+-- modify "collections" table
+ALTER TABLE `collections` DROP INDEX `collection_description_trgm`, DROP INDEX `collection_name_trgm`, DROP INDEX `collection_tick_trgm`;
@@ -0,0 +1,4 @@
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP INDEX `inscription_network_output`;
+-- reverse: modify "transfers" table
+ALTER TABLE `transfers` DROP INDEX `transfer_token_transfers_block_height`, DROP COLUMN `from_output_value`, DROP COLUMN `to_location`, DROP COLUMN `from_location`;
@@ -0,0 +1,4 @@
+-- modify "transfers" table
+ALTER TABLE `transfers` ADD COLUMN `from_location` varchar(255) NOT NULL DEFAULT "", ADD COLUMN `to_location` varchar(255) NOT NULL DEFAULT "", ADD COLUMN `from_output_value` bigint unsigned NOT NULL DEFAULT 0, ADD INDEX `transfer_token_transfers_block_height` (`token_transfers`, `block_height`);
+-- modify "inscriptions" table
+ALTER TABLE `inscriptions` ADD INDEX `inscription_network_output` (`network`, `output`);
@@ -0,0 +1,2 @@
+-- reverse: create "fences" table
+DROP TABLE `fences`;
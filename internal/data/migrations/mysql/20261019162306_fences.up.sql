@@ -0,0 +1,2 @@
+-- create "fences" table
+CREATE TABLE `fences` (`id` bigint NOT NULL AUTO_INCREMENT, `created_at` timestamp NOT NULL, `updated_at` timestamp NOT NULL, `network` varchar(255) NOT NULL DEFAULT "mainnet", `name` varchar(255) NOT NULL, `token` bigint NOT NULL, PRIMARY KEY (`id`), UNIQUE INDEX `fence_network_name` (`network`, `name`)) CHARSET utf8mb4 COLLATE utf8mb4_bin;
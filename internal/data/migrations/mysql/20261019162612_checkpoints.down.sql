@@ -0,0 +1,2 @@
+-- reverse: create "checkpoints" table
+DROP TABLE `checkpoints`;
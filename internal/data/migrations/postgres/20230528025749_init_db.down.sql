@@ -0,0 +1,26 @@
+-- reverse: Create index "tokens_tick_key" to table: "tokens"
+DROP INDEX "tokens_tick_key";
+-- reverse: Create index "token_tx_hash" to table: "tokens"
+DROP INDEX "token_tx_hash";
+-- reverse: Create index "token_p_tick_token_id" to table: "tokens"
+DROP INDEX "token_p_tick_token_id";
+-- reverse: Create index "token_inscription_id" to table: "tokens"
+DROP INDEX "token_inscription_id";
+-- reverse: Create index "token_block_height" to table: "tokens"
+DROP INDEX "token_block_height";
+-- reverse: Create index "token_address" to table: "tokens"
+DROP INDEX "token_address";
+-- reverse: Create "tokens" table
+DROP TABLE "tokens";
+-- reverse: Create index "collection_tx_hash" to table: "collections"
+DROP INDEX "collection_tx_hash";
+-- reverse: Create index "collection_p_tick" to table: "collections"
+DROP INDEX "collection_p_tick";
+-- reverse: Create index "collection_inscription_id" to table: "collections"
+DROP INDEX "collection_inscription_id";
+-- reverse: Create index "collection_block_height" to table: "collections"
+DROP INDEX "collection_block_height";
+-- reverse: Create index "collection_address" to table: "collections"
+DROP INDEX "collection_address";
+-- reverse: Create "collections" table
+DROP TABLE "collections";
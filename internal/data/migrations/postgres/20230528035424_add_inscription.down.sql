@@ -0,0 +1,6 @@
+-- reverse: Create index "inscriptions_uid_key" to table: "inscriptions"
+DROP INDEX "inscriptions_uid_key";
+-- reverse: Create index "inscriptions_inscription_id_key" to table: "inscriptions"
+DROP INDEX "inscriptions_inscription_id_key";
+-- reverse: Create "inscriptions" table
+DROP TABLE "inscriptions";
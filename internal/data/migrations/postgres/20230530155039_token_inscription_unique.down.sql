@@ -0,0 +1,4 @@
+-- reverse: Create index "token_inscription_id" to table: "tokens"
+DROP INDEX "token_inscription_id";
+-- reverse: Drop index "token_inscription_id" from table: "tokens"
+CREATE INDEX "token_inscription_id" ON "tokens" ("inscription_id");
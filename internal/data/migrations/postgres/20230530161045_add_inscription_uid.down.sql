@@ -0,0 +1,12 @@
+-- reverse: Create index "tokens_inscription_uid_key" to table: "tokens"
+DROP INDEX "tokens_inscription_uid_key";
+-- reverse: Create index "tokens_inscription_id_key" to table: "tokens"
+DROP INDEX "tokens_inscription_id_key";
+-- reverse: Modify "tokens" table
+ALTER TABLE "tokens" DROP COLUMN "inscription_uid";
+-- reverse: Create index "collections_inscription_uid_key" to table: "collections"
+DROP INDEX "collections_inscription_uid_key";
+-- reverse: Create index "collections_inscription_id_key" to table: "collections"
+DROP INDEX "collections_inscription_id_key";
+-- reverse: Modify "collections" table
+ALTER TABLE "collections" DROP COLUMN "inscription_uid";
@@ -0,0 +1,2 @@
+-- reverse: Drop index "tokens_tick_key" from table: "tokens"
+CREATE UNIQUE INDEX "tokens_tick_key" ON "tokens" ("tick");
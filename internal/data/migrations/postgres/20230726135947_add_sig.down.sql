@@ -0,0 +1,6 @@
+-- reverse: Create index "token_sig_uid" to table: "tokens"
+DROP INDEX "token_sig_uid";
+-- reverse: Modify "tokens" table
+ALTER TABLE "tokens" DROP COLUMN "sig_uid", DROP COLUMN "sig";
+-- reverse: Modify "collections" table
+ALTER TABLE "collections" DROP COLUMN "sig";
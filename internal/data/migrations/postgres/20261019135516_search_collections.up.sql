@@ -0,0 +1,8 @@
+-- create extension "pg_trgm"
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+-- create index "collection_tick_trgm" to table: "collections"
+CREATE INDEX "collection_tick_trgm" ON "collections" USING GIN ("tick" gin_trgm_ops);
+-- create index "collection_name_trgm" to table: "collections"
+CREATE INDEX "collection_name_trgm" ON "collections" USING GIN ("name" gin_trgm_ops);
+-- create index "collection_description_trgm" to table: "collections"
+CREATE INDEX "collection_description_trgm" ON "collections" USING GIN ("description" gin_trgm_ops);
@@ -0,0 +1,6 @@
+-- create "traits" table
+CREATE TABLE "traits" ("id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY, "created_at" timestamptz NOT NULL, "updated_at" timestamptz NOT NULL, "tick" character varying NOT NULL, "p" character varying NOT NULL DEFAULT 'brc-721', "trait_type" character varying NOT NULL, "value" character varying NOT NULL, "token_traits" bigint NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "traits_tokens_traits" FOREIGN KEY ("token_traits") REFERENCES "tokens" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- create index "trait_p_tick_trait_type_value" to table: "traits"
+CREATE INDEX "trait_p_tick_trait_type_value" ON "traits" ("p", "tick", "trait_type", "value");
+-- create index "trait_trait_type_value_token_traits" to table: "traits"
+CREATE UNIQUE INDEX "trait_trait_type_value_token_traits" ON "traits" ("trait_type", "value", "token_traits");
@@ -0,0 +1,8 @@
+-- reverse: create index "transfer_tx_hash_token_transfers" to table: "transfers"
+DROP INDEX "transfer_tx_hash_token_transfers";
+-- reverse: create index "transfer_to_address" to table: "transfers"
+DROP INDEX "transfer_to_address";
+-- reverse: create index "transfer_p_tick_block_height" to table: "transfers"
+DROP INDEX "transfer_p_tick_block_height";
+-- reverse: create "transfers" table
+DROP TABLE "transfers";
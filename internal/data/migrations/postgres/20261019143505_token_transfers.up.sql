@@ -0,0 +1,8 @@
+-- create "transfers" table
+CREATE TABLE "transfers" ("id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY, "created_at" timestamptz NOT NULL, "updated_at" timestamptz NOT NULL, "tick" character varying NOT NULL, "p" character varying NOT NULL DEFAULT 'brc-721', "inscription_uid" character varying NOT NULL, "from_address" character varying NOT NULL, "to_address" character varying NOT NULL, "tx_hash" character varying NOT NULL, "block_height" bigint NOT NULL, "block_time" timestamptz NOT NULL, "token_transfers" bigint NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "transfers_tokens_transfers" FOREIGN KEY ("token_transfers") REFERENCES "tokens" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- create index "transfer_p_tick_block_height" to table: "transfers"
+CREATE INDEX "transfer_p_tick_block_height" ON "transfers" ("p", "tick", "block_height");
+-- create index "transfer_to_address" to table: "transfers"
+CREATE INDEX "transfer_to_address" ON "transfers" ("to_address");
+-- create index "transfer_tx_hash_token_transfers" to table: "transfers"
+CREATE UNIQUE INDEX "transfer_tx_hash_token_transfers" ON "transfers" ("tx_hash", "token_transfers");
@@ -0,0 +1,44 @@
+-- reverse: modify "transfers" table
+ALTER TABLE "transfers" DROP COLUMN "network";
+-- reverse: modify "traits" table
+ALTER TABLE "traits" DROP COLUMN "network";
+-- reverse: create index "token_network_inscription_uid" to table: "tokens"
+DROP INDEX "token_network_inscription_uid";
+-- reverse: create index "token_network_inscription_id" to table: "tokens"
+DROP INDEX "token_network_inscription_id";
+-- reverse: create index "token_network_p_tick_token_id" to table: "tokens"
+DROP INDEX "token_network_p_tick_token_id";
+-- reverse: drop index "tokens_inscription_uid_key" from table: "tokens"
+CREATE UNIQUE INDEX "tokens_inscription_uid_key" ON "tokens" ("inscription_uid");
+-- reverse: drop index "tokens_inscription_id_key" from table: "tokens"
+CREATE UNIQUE INDEX "tokens_inscription_id_key" ON "tokens" ("inscription_id");
+-- reverse: drop index "token_inscription_id" from table: "tokens"
+CREATE UNIQUE INDEX "token_inscription_id" ON "tokens" ("inscription_id");
+-- reverse: drop index "token_p_tick_token_id" from table: "tokens"
+CREATE UNIQUE INDEX "token_p_tick_token_id" ON "tokens" ("p", "tick", "token_id");
+-- reverse: modify "tokens" table
+ALTER TABLE "tokens" DROP COLUMN "network";
+-- reverse: create index "inscription_network_uid" to table: "inscriptions"
+DROP INDEX "inscription_network_uid";
+-- reverse: create index "inscription_network_inscription_id" to table: "inscriptions"
+DROP INDEX "inscription_network_inscription_id";
+-- reverse: drop index "inscriptions_uid_key" from table: "inscriptions"
+CREATE UNIQUE INDEX "inscriptions_uid_key" ON "inscriptions" ("uid");
+-- reverse: drop index "inscriptions_inscription_id_key" from table: "inscriptions"
+CREATE UNIQUE INDEX "inscriptions_inscription_id_key" ON "inscriptions" ("inscription_id");
+-- reverse: modify "inscriptions" table
+ALTER TABLE "inscriptions" DROP COLUMN "network";
+-- reverse: create index "collection_network_inscription_uid" to table: "collections"
+DROP INDEX "collection_network_inscription_uid";
+-- reverse: create index "collection_network_inscription_id" to table: "collections"
+DROP INDEX "collection_network_inscription_id";
+-- reverse: create index "collection_network_p_tick" to table: "collections"
+DROP INDEX "collection_network_p_tick";
+-- reverse: drop index "collections_inscription_uid_key" from table: "collections"
+CREATE UNIQUE INDEX "collections_inscription_uid_key" ON "collections" ("inscription_uid");
+-- reverse: drop index "collections_inscription_id_key" from table: "collections"
+CREATE UNIQUE INDEX "collections_inscription_id_key" ON "collections" ("inscription_id");
+-- reverse: drop index "collection_p_tick" from table: "collections"
+CREATE UNIQUE INDEX "collection_p_tick" ON "collections" ("p", "tick");
+-- reverse: modify "collections" table
+ALTER TABLE "collections" DROP COLUMN "network";
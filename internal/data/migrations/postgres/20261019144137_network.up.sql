@@ -0,0 +1,44 @@
+-- modify "collections" table
+ALTER TABLE "collections" ADD COLUMN "network" character varying NOT NULL DEFAULT 'mainnet';
+-- drop index "collection_p_tick" from table: "collections"
+DROP INDEX "collection_p_tick";
+-- drop index "collections_inscription_id_key" from table: "collections"
+DROP INDEX "collections_inscription_id_key";
+-- drop index "collections_inscription_uid_key" from table: "collections"
+DROP INDEX "collections_inscription_uid_key";
+-- create index "collection_network_p_tick" to table: "collections"
+CREATE UNIQUE INDEX "collection_network_p_tick" ON "collections" ("network", "p", "tick");
+-- create index "collection_network_inscription_id" to table: "collections"
+CREATE UNIQUE INDEX "collection_network_inscription_id" ON "collections" ("network", "inscription_id");
+-- create index "collection_network_inscription_uid" to table: "collections"
+CREATE UNIQUE INDEX "collection_network_inscription_uid" ON "collections" ("network", "inscription_uid");
+-- modify "inscriptions" table
+ALTER TABLE "inscriptions" ADD COLUMN "network" character varying NOT NULL DEFAULT 'mainnet';
+-- drop index "inscriptions_inscription_id_key" from table: "inscriptions"
+DROP INDEX "inscriptions_inscription_id_key";
+-- drop index "inscriptions_uid_key" from table: "inscriptions"
+DROP INDEX "inscriptions_uid_key";
+-- create index "inscription_network_inscription_id" to table: "inscriptions"
+CREATE UNIQUE INDEX "inscription_network_inscription_id" ON "inscriptions" ("network", "inscription_id");
+-- create index "inscription_network_uid" to table: "inscriptions"
+CREATE UNIQUE INDEX "inscription_network_uid" ON "inscriptions" ("network", "uid");
+-- modify "tokens" table
+ALTER TABLE "tokens" ADD COLUMN "network" character varying NOT NULL DEFAULT 'mainnet';
+-- drop index "token_p_tick_token_id" from table: "tokens"
+DROP INDEX "token_p_tick_token_id";
+-- drop index "token_inscription_id" from table: "tokens"
+DROP INDEX "token_inscription_id";
+-- drop index "tokens_inscription_id_key" from table: "tokens"
+DROP INDEX "tokens_inscription_id_key";
+-- drop index "tokens_inscription_uid_key" from table: "tokens"
+DROP INDEX "tokens_inscription_uid_key";
+-- create index "token_network_p_tick_token_id" to table: "tokens"
+CREATE UNIQUE INDEX "token_network_p_tick_token_id" ON "tokens" ("network", "p", "tick", "token_id");
+-- create index "token_network_inscription_id" to table: "tokens"
+CREATE UNIQUE INDEX "token_network_inscription_id" ON "tokens" ("network", "inscription_id");
+-- create index "token_network_inscription_uid" to table: "tokens"
+CREATE UNIQUE INDEX "token_network_inscription_uid" ON "tokens" ("network", "inscription_uid");
+-- modify "traits" table
+ALTER TABLE "traits" ADD COLUMN "network" character varying NOT NULL DEFAULT 'mainnet';
+-- modify "transfers" table
+ALTER TABLE "transfers" ADD COLUMN "network" character varying NOT NULL DEFAULT 'mainnet';
@@ -0,0 +1,8 @@
+-- reverse: create index "inscription_network_timestamp" to table: "inscriptions"
+DROP INDEX "inscription_network_timestamp";
+-- reverse: create index "inscription_network_genesis_height" to table: "inscriptions"
+DROP INDEX "inscription_network_genesis_height";
+-- reverse: create index "inscription_network_content_type" to table: "inscriptions"
+DROP INDEX "inscription_network_content_type";
+-- reverse: create index "inscription_network_address_inscription_id" to table: "inscriptions"
+DROP INDEX "inscription_network_address_inscription_id";
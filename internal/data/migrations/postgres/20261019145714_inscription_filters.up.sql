@@ -0,0 +1,8 @@
+-- create index "inscription_network_address_inscription_id" to table: "inscriptions"
+CREATE INDEX "inscription_network_address_inscription_id" ON "inscriptions" ("network", "address", "inscription_id");
+-- create index "inscription_network_content_type" to table: "inscriptions"
+CREATE INDEX "inscription_network_content_type" ON "inscriptions" ("network", "content_type");
+-- create index "inscription_network_genesis_height" to table: "inscriptions"
+CREATE INDEX "inscription_network_genesis_height" ON "inscriptions" ("network", "genesis_height");
+-- create index "inscription_network_timestamp" to table: "inscriptions"
+CREATE INDEX "inscription_network_timestamp" ON "inscriptions" ("network", "timestamp");
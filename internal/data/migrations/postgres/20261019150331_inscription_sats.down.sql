@@ -0,0 +1,6 @@
+-- reverse: create index "inscription_network_sat_rarity_inscription_id" to table: "inscriptions"
+DROP INDEX "inscription_network_sat_rarity_inscription_id";
+-- reverse: create index "inscription_network_sat" to table: "inscriptions"
+DROP INDEX "inscription_network_sat";
+-- reverse: modify "inscriptions" table
+ALTER TABLE "inscriptions" DROP COLUMN "cursed", DROP COLUMN "sat_rarity", DROP COLUMN "sat";
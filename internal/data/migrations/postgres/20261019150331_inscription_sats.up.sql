@@ -0,0 +1,6 @@
+-- modify "inscriptions" table
+ALTER TABLE "inscriptions" ADD COLUMN "sat" bigint NULL, ADD COLUMN "sat_rarity" character varying NOT NULL DEFAULT '', ADD COLUMN "cursed" boolean NOT NULL DEFAULT false;
+-- create index "inscription_network_sat" to table: "inscriptions"
+CREATE INDEX "inscription_network_sat" ON "inscriptions" ("network", "sat");
+-- create index "inscription_network_sat_rarity_inscription_id" to table: "inscriptions"
+CREATE INDEX "inscription_network_sat_rarity_inscription_id" ON "inscriptions" ("network", "sat_rarity", "inscription_id");
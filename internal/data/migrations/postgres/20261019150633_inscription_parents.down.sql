@@ -0,0 +1,6 @@
+-- reverse: create index "inscription_network_parent_uid" to table: "inscriptions"
+DROP INDEX "inscription_network_parent_uid";
+-- reverse: modify "inscriptions" table
+ALTER TABLE "inscriptions" DROP CONSTRAINT "inscriptions_inscriptions_children", DROP COLUMN "inscription_children", DROP COLUMN "parent_uid";
+-- reverse: modify "collections" table
+ALTER TABLE "collections" DROP COLUMN "child_mints";
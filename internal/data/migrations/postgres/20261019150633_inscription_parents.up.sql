@@ -0,0 +1,6 @@
+-- modify "collections" table
+ALTER TABLE "collections" ADD COLUMN "child_mints" boolean NOT NULL DEFAULT false;
+-- modify "inscriptions" table
+ALTER TABLE "inscriptions" ADD COLUMN "parent_uid" character varying NOT NULL DEFAULT '', ADD COLUMN "inscription_children" bigint NULL, ADD CONSTRAINT "inscriptions_inscriptions_children" FOREIGN KEY ("inscription_children") REFERENCES "inscriptions" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
+-- create index "inscription_network_parent_uid" to table: "inscriptions"
+CREATE INDEX "inscription_network_parent_uid" ON "inscriptions" ("network", "parent_uid");
@@ -0,0 +1,6 @@
+-- drop index "inscription_network_genesis_height" from table: "inscriptions"
+DROP INDEX "inscription_network_genesis_height";
+-- modify "inscriptions" table
+ALTER TABLE "inscriptions" ADD COLUMN "tx_index" bigint NOT NULL DEFAULT 0;
+-- create index "inscription_network_genesis_height_tx_index" to table: "inscriptions"
+CREATE INDEX "inscription_network_genesis_height_tx_index" ON "inscriptions" ("network", "genesis_height", "tx_index");
@@ -0,0 +1,6 @@
+-- reverse: create index "inscription_network_output" to table: "inscriptions"
+DROP INDEX "inscription_network_output";
+-- reverse: create index "transfer_token_transfers_block_height" to table: "transfers"
+DROP INDEX "transfer_token_transfers_block_height";
+-- reverse: modify "transfers" table
+ALTER TABLE "transfers" DROP COLUMN "from_output_value", DROP COLUMN "to_location", DROP COLUMN "from_location";
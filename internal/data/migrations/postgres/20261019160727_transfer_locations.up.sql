@@ -0,0 +1,6 @@
+-- modify "transfers" table
+ALTER TABLE "transfers" ADD COLUMN "from_location" character varying NOT NULL DEFAULT '', ADD COLUMN "to_location" character varying NOT NULL DEFAULT '', ADD COLUMN "from_output_value" bigint NOT NULL DEFAULT 0;
+-- create index "transfer_token_transfers_block_height" to table: "transfers"
+CREATE INDEX "transfer_token_transfers_block_height" ON "transfers" ("token_transfers", "block_height");
+-- create index "inscription_network_output" to table: "inscriptions"
+CREATE INDEX "inscription_network_output" ON "inscriptions" ("network", "output");
@@ -0,0 +1,4 @@
+-- reverse: create index "fence_network_name" to table: "fences"
+DROP INDEX "fence_network_name";
+-- reverse: create "fences" table
+DROP TABLE "fences";
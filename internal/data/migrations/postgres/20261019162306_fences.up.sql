@@ -0,0 +1,4 @@
+-- create "fences" table
+CREATE TABLE "fences" ("id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY, "created_at" timestamptz NOT NULL, "updated_at" timestamptz NOT NULL, "network" character varying NOT NULL DEFAULT 'mainnet', "name" character varying NOT NULL, "token" bigint NOT NULL, PRIMARY KEY ("id"));
+-- create index "fence_network_name" to table: "fences"
+CREATE UNIQUE INDEX "fence_network_name" ON "fences" ("network", "name");
@@ -0,0 +1,4 @@
+-- create "checkpoints" table
+CREATE TABLE "checkpoints" ("id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY, "created_at" timestamptz NOT NULL, "updated_at" timestamptz NOT NULL, "network" character varying NOT NULL DEFAULT 'mainnet', "name" character varying NOT NULL, "value" text NOT NULL, PRIMARY KEY ("id"));
+-- create index "checkpoint_network_name" to table: "checkpoints"
+CREATE UNIQUE INDEX "checkpoint_network_name" ON "checkpoints" ("network", "name");
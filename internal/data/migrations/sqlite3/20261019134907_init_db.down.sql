@@ -0,0 +1,40 @@
+-- reverse: create index "token_sig_uid" to table: "tokens"
+DROP INDEX `token_sig_uid`;
+-- reverse: create index "token_block_height" to table: "tokens"
+DROP INDEX `token_block_height`;
+-- reverse: create index "token_inscription_id" to table: "tokens"
+DROP INDEX `token_inscription_id`;
+-- reverse: create index "token_tx_hash" to table: "tokens"
+DROP INDEX `token_tx_hash`;
+-- reverse: create index "token_address" to table: "tokens"
+DROP INDEX `token_address`;
+-- reverse: create index "token_p_tick_token_id" to table: "tokens"
+DROP INDEX `token_p_tick_token_id`;
+-- reverse: create index "tokens_inscription_uid_key" to table: "tokens"
+DROP INDEX `tokens_inscription_uid_key`;
+-- reverse: create index "tokens_inscription_id_key" to table: "tokens"
+DROP INDEX `tokens_inscription_id_key`;
+-- reverse: create "tokens" table
+DROP TABLE `tokens`;
+-- reverse: create index "inscriptions_uid_key" to table: "inscriptions"
+DROP INDEX `inscriptions_uid_key`;
+-- reverse: create index "inscriptions_inscription_id_key" to table: "inscriptions"
+DROP INDEX `inscriptions_inscription_id_key`;
+-- reverse: create "inscriptions" table
+DROP TABLE `inscriptions`;
+-- reverse: create index "collection_address" to table: "collections"
+DROP INDEX `collection_address`;
+-- reverse: create index "collection_inscription_id" to table: "collections"
+DROP INDEX `collection_inscription_id`;
+-- reverse: create index "collection_block_height" to table: "collections"
+DROP INDEX `collection_block_height`;
+-- reverse: create index "collection_tx_hash" to table: "collections"
+DROP INDEX `collection_tx_hash`;
+-- reverse: create index "collection_p_tick" to table: "collections"
+DROP INDEX `collection_p_tick`;
+-- reverse: create index "collections_inscription_uid_key" to table: "collections"
+DROP INDEX `collections_inscription_uid_key`;
+-- reverse: create index "collections_inscription_id_key" to table: "collections"
+DROP INDEX `collections_inscription_id_key`;
+-- reverse: create "collections" table
+DROP TABLE `collections`;
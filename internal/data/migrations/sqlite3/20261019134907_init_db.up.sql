@@ -0,0 +1,40 @@
+-- create "collections" table
+CREATE TABLE `collections` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `max` integer NOT NULL, `supply` integer NOT NULL, `base_uri` text NOT NULL, `name` text NOT NULL, `description` text NOT NULL, `image` text NOT NULL, `attributes` json NOT NULL, `tx_hash` text NOT NULL, `block_height` integer NOT NULL, `block_time` datetime NOT NULL, `address` text NOT NULL, `inscription_id` integer NOT NULL, `inscription_uid` text NOT NULL, `sig` json NULL);
+-- create index "collections_inscription_id_key" to table: "collections"
+CREATE UNIQUE INDEX `collections_inscription_id_key` ON `collections` (`inscription_id`);
+-- create index "collections_inscription_uid_key" to table: "collections"
+CREATE UNIQUE INDEX `collections_inscription_uid_key` ON `collections` (`inscription_uid`);
+-- create index "collection_p_tick" to table: "collections"
+CREATE UNIQUE INDEX `collection_p_tick` ON `collections` (`p`, `tick`);
+-- create index "collection_tx_hash" to table: "collections"
+CREATE INDEX `collection_tx_hash` ON `collections` (`tx_hash`);
+-- create index "collection_block_height" to table: "collections"
+CREATE INDEX `collection_block_height` ON `collections` (`block_height`);
+-- create index "collection_inscription_id" to table: "collections"
+CREATE INDEX `collection_inscription_id` ON `collections` (`inscription_id`);
+-- create index "collection_address" to table: "collections"
+CREATE INDEX `collection_address` ON `collections` (`address`);
+-- create "inscriptions" table
+CREATE TABLE `inscriptions` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `inscription_id` integer NOT NULL, `uid` text NOT NULL, `address` text NOT NULL, `output_value` integer NOT NULL, `content_length` integer NOT NULL, `content_type` text NOT NULL, `timestamp` datetime NOT NULL, `genesis_height` integer NOT NULL, `genesis_fee` integer NOT NULL, `genesis_tx` text NOT NULL, `location` text NOT NULL, `output` text NOT NULL, `offset` integer NOT NULL);
+-- create index "inscriptions_inscription_id_key" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscriptions_inscription_id_key` ON `inscriptions` (`inscription_id`);
+-- create index "inscriptions_uid_key" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscriptions_uid_key` ON `inscriptions` (`uid`);
+-- create "tokens" table
+CREATE TABLE `tokens` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `token_id` integer NOT NULL, `tx_hash` text NOT NULL, `block_height` integer NOT NULL, `block_time` datetime NOT NULL, `address` text NOT NULL, `inscription_id` integer NOT NULL, `inscription_uid` text NOT NULL, `sig` json NULL, `sig_uid` text NOT NULL DEFAULT '', `collection_tokens` integer NULL, CONSTRAINT `tokens_collections_tokens` FOREIGN KEY (`collection_tokens`) REFERENCES `collections` (`id`) ON DELETE SET NULL);
+-- create index "tokens_inscription_id_key" to table: "tokens"
+CREATE UNIQUE INDEX `tokens_inscription_id_key` ON `tokens` (`inscription_id`);
+-- create index "tokens_inscription_uid_key" to table: "tokens"
+CREATE UNIQUE INDEX `tokens_inscription_uid_key` ON `tokens` (`inscription_uid`);
+-- create index "token_p_tick_token_id" to table: "tokens"
+CREATE UNIQUE INDEX `token_p_tick_token_id` ON `tokens` (`p`, `tick`, `token_id`);
+-- create index "token_address" to table: "tokens"
+CREATE INDEX `token_address` ON `tokens` (`address`);
+-- create index "token_tx_hash" to table: "tokens"
+CREATE INDEX `token_tx_hash` ON `tokens` (`tx_hash`);
+-- create index "token_inscription_id" to table: "tokens"
+CREATE UNIQUE INDEX `token_inscription_id` ON `tokens` (`inscription_id`);
+-- create index "token_block_height" to table: "tokens"
+CREATE INDEX `token_block_height` ON `tokens` (`block_height`);
+-- create index "token_sig_uid" to table: "tokens"
+CREATE INDEX `token_sig_uid` ON `tokens` (`sig_uid`);
@@ -0,0 +1,6 @@
+-- reverse: create index "collection_description_trgm" to table: "collections"
+DROP INDEX `collection_description_trgm`;
+-- reverse: create index "collection_name_trgm" to table: "collections"
+DROP INDEX `collection_name_trgm`;
+-- reverse: create index "collection_tick_trgm" to table: "collections"
+DROP INDEX `collection_tick_trgm`;
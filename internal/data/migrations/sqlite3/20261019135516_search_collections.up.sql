@@ -0,0 +1,6 @@
+-- create index "collection_tick_trgm" to table: "collections"
+CREATE INDEX `collection_tick_trgm` ON `collections` (`tick`);
+-- create index "collection_name_trgm" to table: "collections"
+CREATE INDEX `collection_name_trgm` ON `collections` (`name`);
+-- create index "collection_description_trgm" to table: "collections"
+CREATE INDEX `collection_description_trgm` ON `collections` (`description`);
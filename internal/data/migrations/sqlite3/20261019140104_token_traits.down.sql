@@ -0,0 +1,6 @@
+-- reverse: create index "trait_trait_type_value_token_traits" to table: "traits"
+DROP INDEX `trait_trait_type_value_token_traits`;
+-- reverse: create index "trait_p_tick_trait_type_value" to table: "traits"
+DROP INDEX `trait_p_tick_trait_type_value`;
+-- reverse: create "traits" table
+DROP TABLE `traits`;
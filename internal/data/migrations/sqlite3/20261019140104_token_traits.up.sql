@@ -0,0 +1,6 @@
+-- create "traits" table
+CREATE TABLE `traits` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `trait_type` text NOT NULL, `value` text NOT NULL, `token_traits` integer NOT NULL, CONSTRAINT `traits_tokens_traits` FOREIGN KEY (`token_traits`) REFERENCES `tokens` (`id`) ON DELETE CASCADE);
+-- create index "trait_p_tick_trait_type_value" to table: "traits"
+CREATE INDEX `trait_p_tick_trait_type_value` ON `traits` (`p`, `tick`, `trait_type`, `value`);
+-- create index "trait_trait_type_value_token_traits" to table: "traits"
+CREATE UNIQUE INDEX `trait_trait_type_value_token_traits` ON `traits` (`trait_type`, `value`, `token_traits`);
@@ -0,0 +1,8 @@
+-- create "transfers" table
+CREATE TABLE `transfers` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `inscription_uid` text NOT NULL, `from_address` text NOT NULL, `to_address` text NOT NULL, `tx_hash` text NOT NULL, `block_height` integer NOT NULL, `block_time` datetime NOT NULL, `token_transfers` integer NOT NULL, CONSTRAINT `transfers_tokens_transfers` FOREIGN KEY (`token_transfers`) REFERENCES `tokens` (`id`) ON DELETE CASCADE);
+-- create index "transfer_p_tick_block_height" to table: "transfers"
+CREATE INDEX `transfer_p_tick_block_height` ON `transfers` (`p`, `tick`, `block_height`);
+-- create index "transfer_to_address" to table: "transfers"
+CREATE INDEX `transfer_to_address` ON `transfers` (`to_address`);
+-- create index "transfer_tx_hash_token_transfers" to table: "transfers"
+CREATE UNIQUE INDEX `transfer_tx_hash_token_transfers` ON `transfers` (`tx_hash`, `token_transfers`);
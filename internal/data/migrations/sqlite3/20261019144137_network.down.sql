@@ -0,0 +1,27 @@
+-- reverse: modify "transfers" table
+ALTER TABLE `transfers` DROP COLUMN `network`;
+-- reverse: modify "traits" table
+ALTER TABLE `traits` DROP COLUMN `network`;
+-- reverse: modify "tokens" table
+DROP INDEX `token_network_inscription_uid`;
+DROP INDEX `token_network_inscription_id`;
+DROP INDEX `token_network_p_tick_token_id`;
+ALTER TABLE `tokens` DROP COLUMN `network`;
+CREATE UNIQUE INDEX `token_inscription_id` ON `tokens` (`inscription_id`);
+CREATE UNIQUE INDEX `token_p_tick_token_id` ON `tokens` (`p`, `tick`, `token_id`);
+CREATE UNIQUE INDEX `tokens_inscription_uid_key` ON `tokens` (`inscription_uid`);
+CREATE UNIQUE INDEX `tokens_inscription_id_key` ON `tokens` (`inscription_id`);
+-- reverse: modify "inscriptions" table
+DROP INDEX `inscription_network_uid`;
+DROP INDEX `inscription_network_inscription_id`;
+ALTER TABLE `inscriptions` DROP COLUMN `network`;
+CREATE UNIQUE INDEX `inscriptions_uid_key` ON `inscriptions` (`uid`);
+CREATE UNIQUE INDEX `inscriptions_inscription_id_key` ON `inscriptions` (`inscription_id`);
+-- reverse: modify "collections" table
+DROP INDEX `collection_network_inscription_uid`;
+DROP INDEX `collection_network_inscription_id`;
+DROP INDEX `collection_network_p_tick`;
+ALTER TABLE `collections` DROP COLUMN `network`;
+CREATE UNIQUE INDEX `collection_p_tick` ON `collections` (`p`, `tick`);
+CREATE UNIQUE INDEX `collections_inscription_uid_key` ON `collections` (`inscription_uid`);
+CREATE UNIQUE INDEX `collections_inscription_id_key` ON `collections` (`inscription_id`);
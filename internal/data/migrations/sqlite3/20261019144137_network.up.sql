@@ -0,0 +1,92 @@
+-- disable the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = off;
+-- create "new_collections" table
+CREATE TABLE `new_collections` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `max` integer NOT NULL, `supply` integer NOT NULL, `base_uri` text NOT NULL, `name` text NOT NULL, `description` text NOT NULL, `image` text NOT NULL, `attributes` json NOT NULL, `tx_hash` text NOT NULL, `block_height` integer NOT NULL, `block_time` datetime NOT NULL, `address` text NOT NULL, `inscription_id` integer NOT NULL, `inscription_uid` text NOT NULL, `sig` json NULL);
+-- copy rows from old table "collections" to new temporary table "new_collections"
+INSERT INTO `new_collections` (`id`, `created_at`, `updated_at`, `tick`, `p`, `max`, `supply`, `base_uri`, `name`, `description`, `image`, `attributes`, `tx_hash`, `block_height`, `block_time`, `address`, `inscription_id`, `inscription_uid`, `sig`) SELECT `id`, `created_at`, `updated_at`, `tick`, `p`, `max`, `supply`, `base_uri`, `name`, `description`, `image`, `attributes`, `tx_hash`, `block_height`, `block_time`, `address`, `inscription_id`, `inscription_uid`, `sig` FROM `collections`;
+-- drop "collections" table after copying rows
+DROP TABLE `collections`;
+-- rename temporary table "new_collections" to "collections"
+ALTER TABLE `new_collections` RENAME TO `collections`;
+-- create index "collection_network_p_tick" to table: "collections"
+CREATE UNIQUE INDEX `collection_network_p_tick` ON `collections` (`network`, `p`, `tick`);
+-- create index "collection_network_inscription_id" to table: "collections"
+CREATE UNIQUE INDEX `collection_network_inscription_id` ON `collections` (`network`, `inscription_id`);
+-- create index "collection_network_inscription_uid" to table: "collections"
+CREATE UNIQUE INDEX `collection_network_inscription_uid` ON `collections` (`network`, `inscription_uid`);
+-- create index "collection_tx_hash" to table: "collections"
+CREATE INDEX `collection_tx_hash` ON `collections` (`tx_hash`);
+-- create index "collection_block_height" to table: "collections"
+CREATE INDEX `collection_block_height` ON `collections` (`block_height`);
+-- create index "collection_inscription_id" to table: "collections"
+CREATE INDEX `collection_inscription_id` ON `collections` (`inscription_id`);
+-- create index "collection_address" to table: "collections"
+CREATE INDEX `collection_address` ON `collections` (`address`);
+-- create index "collection_tick_trgm" to table: "collections"
+CREATE INDEX `collection_tick_trgm` ON `collections` (`tick`);
+-- create index "collection_name_trgm" to table: "collections"
+CREATE INDEX `collection_name_trgm` ON `collections` (`name`);
+-- create index "collection_description_trgm" to table: "collections"
+CREATE INDEX `collection_description_trgm` ON `collections` (`description`);
+-- create "new_inscriptions" table
+CREATE TABLE `new_inscriptions` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `inscription_id` integer NOT NULL, `uid` text NOT NULL, `address` text NOT NULL, `output_value` integer NOT NULL, `content_length` integer NOT NULL, `content_type` text NOT NULL, `timestamp` datetime NOT NULL, `genesis_height` integer NOT NULL, `genesis_fee` integer NOT NULL, `genesis_tx` text NOT NULL, `location` text NOT NULL, `output` text NOT NULL, `offset` integer NOT NULL);
+-- copy rows from old table "inscriptions" to new temporary table "new_inscriptions"
+INSERT INTO `new_inscriptions` (`id`, `created_at`, `updated_at`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset`) SELECT `id`, `created_at`, `updated_at`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset` FROM `inscriptions`;
+-- drop "inscriptions" table after copying rows
+DROP TABLE `inscriptions`;
+-- rename temporary table "new_inscriptions" to "inscriptions"
+ALTER TABLE `new_inscriptions` RENAME TO `inscriptions`;
+-- create index "inscription_network_inscription_id" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_inscription_id` ON `inscriptions` (`network`, `inscription_id`);
+-- create index "inscription_network_uid" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_uid` ON `inscriptions` (`network`, `uid`);
+-- create "new_tokens" table
+CREATE TABLE `new_tokens` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `token_id` integer NOT NULL, `tx_hash` text NOT NULL, `block_height` integer NOT NULL, `block_time` datetime NOT NULL, `address` text NOT NULL, `inscription_id` integer NOT NULL, `inscription_uid` text NOT NULL, `sig` json NULL, `sig_uid` text NOT NULL DEFAULT '', `collection_tokens` integer NULL, CONSTRAINT `tokens_collections_tokens` FOREIGN KEY (`collection_tokens`) REFERENCES `collections` (`id`) ON DELETE SET NULL);
+-- copy rows from old table "tokens" to new temporary table "new_tokens"
+INSERT INTO `new_tokens` (`id`, `created_at`, `updated_at`, `tick`, `p`, `token_id`, `tx_hash`, `block_height`, `block_time`, `address`, `inscription_id`, `inscription_uid`, `sig`, `sig_uid`, `collection_tokens`) SELECT `id`, `created_at`, `updated_at`, `tick`, `p`, `token_id`, `tx_hash`, `block_height`, `block_time`, `address`, `inscription_id`, `inscription_uid`, `sig`, `sig_uid`, `collection_tokens` FROM `tokens`;
+-- drop "tokens" table after copying rows
+DROP TABLE `tokens`;
+-- rename temporary table "new_tokens" to "tokens"
+ALTER TABLE `new_tokens` RENAME TO `tokens`;
+-- create index "token_network_p_tick_token_id" to table: "tokens"
+CREATE UNIQUE INDEX `token_network_p_tick_token_id` ON `tokens` (`network`, `p`, `tick`, `token_id`);
+-- create index "token_address" to table: "tokens"
+CREATE INDEX `token_address` ON `tokens` (`address`);
+-- create index "token_tx_hash" to table: "tokens"
+CREATE INDEX `token_tx_hash` ON `tokens` (`tx_hash`);
+-- create index "token_network_inscription_id" to table: "tokens"
+CREATE UNIQUE INDEX `token_network_inscription_id` ON `tokens` (`network`, `inscription_id`);
+-- create index "token_network_inscription_uid" to table: "tokens"
+CREATE UNIQUE INDEX `token_network_inscription_uid` ON `tokens` (`network`, `inscription_uid`);
+-- create index "token_block_height" to table: "tokens"
+CREATE INDEX `token_block_height` ON `tokens` (`block_height`);
+-- create index "token_sig_uid" to table: "tokens"
+CREATE INDEX `token_sig_uid` ON `tokens` (`sig_uid`);
+-- create "new_traits" table
+CREATE TABLE `new_traits` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `trait_type` text NOT NULL, `value` text NOT NULL, `token_traits` integer NOT NULL, CONSTRAINT `traits_tokens_traits` FOREIGN KEY (`token_traits`) REFERENCES `tokens` (`id`) ON DELETE CASCADE);
+-- copy rows from old table "traits" to new temporary table "new_traits"
+INSERT INTO `new_traits` (`id`, `created_at`, `updated_at`, `tick`, `p`, `trait_type`, `value`, `token_traits`) SELECT `id`, `created_at`, `updated_at`, `tick`, `p`, `trait_type`, `value`, `token_traits` FROM `traits`;
+-- drop "traits" table after copying rows
+DROP TABLE `traits`;
+-- rename temporary table "new_traits" to "traits"
+ALTER TABLE `new_traits` RENAME TO `traits`;
+-- create index "trait_p_tick_trait_type_value" to table: "traits"
+CREATE INDEX `trait_p_tick_trait_type_value` ON `traits` (`p`, `tick`, `trait_type`, `value`);
+-- create index "trait_trait_type_value_token_traits" to table: "traits"
+CREATE UNIQUE INDEX `trait_trait_type_value_token_traits` ON `traits` (`trait_type`, `value`, `token_traits`);
+-- create "new_transfers" table
+CREATE TABLE `new_transfers` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `inscription_uid` text NOT NULL, `from_address` text NOT NULL, `to_address` text NOT NULL, `tx_hash` text NOT NULL, `block_height` integer NOT NULL, `block_time` datetime NOT NULL, `token_transfers` integer NOT NULL, CONSTRAINT `transfers_tokens_transfers` FOREIGN KEY (`token_transfers`) REFERENCES `tokens` (`id`) ON DELETE CASCADE);
+-- copy rows from old table "transfers" to new temporary table "new_transfers"
+INSERT INTO `new_transfers` (`id`, `created_at`, `updated_at`, `tick`, `p`, `inscription_uid`, `from_address`, `to_address`, `tx_hash`, `block_height`, `block_time`, `token_transfers`) SELECT `id`, `created_at`, `updated_at`, `tick`, `p`, `inscription_uid`, `from_address`, `to_address`, `tx_hash`, `block_height`, `block_time`, `token_transfers` FROM `transfers`;
+-- drop "transfers" table after copying rows
+DROP TABLE `transfers`;
+-- rename temporary table "new_transfers" to "transfers"
+ALTER TABLE `new_transfers` RENAME TO `transfers`;
+-- create index "transfer_p_tick_block_height" to table: "transfers"
+CREATE INDEX `transfer_p_tick_block_height` ON `transfers` (`p`, `tick`, `block_height`);
+-- create index "transfer_to_address" to table: "transfers"
+CREATE INDEX `transfer_to_address` ON `transfers` (`to_address`);
+-- create index "transfer_tx_hash_token_transfers" to table: "transfers"
+CREATE UNIQUE INDEX `transfer_tx_hash_token_transfers` ON `transfers` (`tx_hash`, `token_transfers`);
+-- enable back the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = on;
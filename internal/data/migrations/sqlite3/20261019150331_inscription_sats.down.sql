@@ -0,0 +1,8 @@
+-- reverse: create index "inscription_network_sat_rarity_inscription_id" to table: "inscriptions"
+DROP INDEX `inscription_network_sat_rarity_inscription_id`;
+-- reverse: create index "inscription_network_sat" to table: "inscriptions"
+DROP INDEX `inscription_network_sat`;
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP COLUMN `cursed`;
+ALTER TABLE `inscriptions` DROP COLUMN `sat_rarity`;
+ALTER TABLE `inscriptions` DROP COLUMN `sat`;
@@ -0,0 +1,32 @@
+-- reverse: create index "inscription_network_parent_uid" to table: "inscriptions"
+DROP INDEX `inscription_network_parent_uid`;
+-- reverse: modify "collections" table
+ALTER TABLE `collections` DROP COLUMN `child_mints`;
+-- disable the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = off;
+-- reverse: modify "inscriptions" table, create "new_inscriptions" table
+CREATE TABLE `new_inscriptions` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `inscription_id` integer NOT NULL, `uid` text NOT NULL, `address` text NOT NULL, `output_value` integer NOT NULL, `content_length` integer NOT NULL, `content_type` text NOT NULL, `timestamp` datetime NOT NULL, `genesis_height` integer NOT NULL, `genesis_fee` integer NOT NULL, `genesis_tx` text NOT NULL, `location` text NOT NULL, `output` text NOT NULL, `offset` integer NOT NULL, `sat` integer NULL, `sat_rarity` text NOT NULL DEFAULT '', `cursed` bool NOT NULL DEFAULT false);
+-- copy rows from old table "inscriptions" to new temporary table "new_inscriptions"
+INSERT INTO `new_inscriptions` (`id`, `created_at`, `updated_at`, `network`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset`, `sat`, `sat_rarity`, `cursed`) SELECT `id`, `created_at`, `updated_at`, `network`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset`, `sat`, `sat_rarity`, `cursed` FROM `inscriptions`;
+-- drop "inscriptions" table after copying rows
+DROP TABLE `inscriptions`;
+-- rename temporary table "new_inscriptions" to "inscriptions"
+ALTER TABLE `new_inscriptions` RENAME TO `inscriptions`;
+-- create index "inscription_network_inscription_id" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_inscription_id` ON `inscriptions` (`network`, `inscription_id`);
+-- create index "inscription_network_uid" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_uid` ON `inscriptions` (`network`, `uid`);
+-- create index "inscription_network_address_inscription_id" to table: "inscriptions"
+CREATE INDEX `inscription_network_address_inscription_id` ON `inscriptions` (`network`, `address`, `inscription_id`);
+-- create index "inscription_network_content_type" to table: "inscriptions"
+CREATE INDEX `inscription_network_content_type` ON `inscriptions` (`network`, `content_type`);
+-- create index "inscription_network_genesis_height" to table: "inscriptions"
+CREATE INDEX `inscription_network_genesis_height` ON `inscriptions` (`network`, `genesis_height`);
+-- create index "inscription_network_timestamp" to table: "inscriptions"
+CREATE INDEX `inscription_network_timestamp` ON `inscriptions` (`network`, `timestamp`);
+-- create index "inscription_network_sat" to table: "inscriptions"
+CREATE INDEX `inscription_network_sat` ON `inscriptions` (`network`, `sat`);
+-- create index "inscription_network_sat_rarity_inscription_id" to table: "inscriptions"
+CREATE INDEX `inscription_network_sat_rarity_inscription_id` ON `inscriptions` (`network`, `sat_rarity`, `inscription_id`);
+-- enable back the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = on;
@@ -0,0 +1,58 @@
+-- disable the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = off;
+-- create "new_collections" table
+CREATE TABLE `new_collections` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `tick` text NOT NULL, `p` text NOT NULL DEFAULT 'brc-721', `max` integer NOT NULL, `supply` integer NOT NULL, `base_uri` text NOT NULL, `name` text NOT NULL, `description` text NOT NULL, `image` text NOT NULL, `attributes` json NOT NULL, `tx_hash` text NOT NULL, `block_height` integer NOT NULL, `block_time` datetime NOT NULL, `address` text NOT NULL, `inscription_id` integer NOT NULL, `inscription_uid` text NOT NULL, `sig` json NULL, `child_mints` bool NOT NULL DEFAULT false);
+-- copy rows from old table "collections" to new temporary table "new_collections"
+INSERT INTO `new_collections` (`id`, `created_at`, `updated_at`, `network`, `tick`, `p`, `max`, `supply`, `base_uri`, `name`, `description`, `image`, `attributes`, `tx_hash`, `block_height`, `block_time`, `address`, `inscription_id`, `inscription_uid`, `sig`) SELECT `id`, `created_at`, `updated_at`, `network`, `tick`, `p`, `max`, `supply`, `base_uri`, `name`, `description`, `image`, `attributes`, `tx_hash`, `block_height`, `block_time`, `address`, `inscription_id`, `inscription_uid`, `sig` FROM `collections`;
+-- drop "collections" table after copying rows
+DROP TABLE `collections`;
+-- rename temporary table "new_collections" to "collections"
+ALTER TABLE `new_collections` RENAME TO `collections`;
+-- create index "collection_network_p_tick" to table: "collections"
+CREATE UNIQUE INDEX `collection_network_p_tick` ON `collections` (`network`, `p`, `tick`);
+-- create index "collection_network_inscription_id" to table: "collections"
+CREATE UNIQUE INDEX `collection_network_inscription_id` ON `collections` (`network`, `inscription_id`);
+-- create index "collection_network_inscription_uid" to table: "collections"
+CREATE UNIQUE INDEX `collection_network_inscription_uid` ON `collections` (`network`, `inscription_uid`);
+-- create index "collection_tx_hash" to table: "collections"
+CREATE INDEX `collection_tx_hash` ON `collections` (`tx_hash`);
+-- create index "collection_block_height" to table: "collections"
+CREATE INDEX `collection_block_height` ON `collections` (`block_height`);
+-- create index "collection_inscription_id" to table: "collections"
+CREATE INDEX `collection_inscription_id` ON `collections` (`inscription_id`);
+-- create index "collection_address" to table: "collections"
+CREATE INDEX `collection_address` ON `collections` (`address`);
+-- create index "collection_tick_trgm" to table: "collections"
+CREATE INDEX `collection_tick_trgm` ON `collections` (`tick`);
+-- create index "collection_name_trgm" to table: "collections"
+CREATE INDEX `collection_name_trgm` ON `collections` (`name`);
+-- create index "collection_description_trgm" to table: "collections"
+CREATE INDEX `collection_description_trgm` ON `collections` (`description`);
+-- create "new_inscriptions" table
+CREATE TABLE `new_inscriptions` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `inscription_id` integer NOT NULL, `uid` text NOT NULL, `address` text NOT NULL, `output_value` integer NOT NULL, `content_length` integer NOT NULL, `content_type` text NOT NULL, `timestamp` datetime NOT NULL, `genesis_height` integer NOT NULL, `genesis_fee` integer NOT NULL, `genesis_tx` text NOT NULL, `location` text NOT NULL, `output` text NOT NULL, `offset` integer NOT NULL, `sat` integer NULL, `sat_rarity` text NOT NULL DEFAULT '', `cursed` bool NOT NULL DEFAULT false, `parent_uid` text NOT NULL DEFAULT '', `inscription_children` integer NULL, CONSTRAINT `inscriptions_inscriptions_children` FOREIGN KEY (`inscription_children`) REFERENCES `inscriptions` (`id`) ON DELETE SET NULL);
+-- copy rows from old table "inscriptions" to new temporary table "new_inscriptions"
+INSERT INTO `new_inscriptions` (`id`, `created_at`, `updated_at`, `network`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset`, `sat`, `sat_rarity`, `cursed`) SELECT `id`, `created_at`, `updated_at`, `network`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset`, `sat`, `sat_rarity`, `cursed` FROM `inscriptions`;
+-- drop "inscriptions" table after copying rows
+DROP TABLE `inscriptions`;
+-- rename temporary table "new_inscriptions" to "inscriptions"
+ALTER TABLE `new_inscriptions` RENAME TO `inscriptions`;
+-- create index "inscription_network_inscription_id" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_inscription_id` ON `inscriptions` (`network`, `inscription_id`);
+-- create index "inscription_network_uid" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_uid` ON `inscriptions` (`network`, `uid`);
+-- create index "inscription_network_address_inscription_id" to table: "inscriptions"
+CREATE INDEX `inscription_network_address_inscription_id` ON `inscriptions` (`network`, `address`, `inscription_id`);
+-- create index "inscription_network_content_type" to table: "inscriptions"
+CREATE INDEX `inscription_network_content_type` ON `inscriptions` (`network`, `content_type`);
+-- create index "inscription_network_genesis_height" to table: "inscriptions"
+CREATE INDEX `inscription_network_genesis_height` ON `inscriptions` (`network`, `genesis_height`);
+-- create index "inscription_network_timestamp" to table: "inscriptions"
+CREATE INDEX `inscription_network_timestamp` ON `inscriptions` (`network`, `timestamp`);
+-- create index "inscription_network_sat" to table: "inscriptions"
+CREATE INDEX `inscription_network_sat` ON `inscriptions` (`network`, `sat`);
+-- create index "inscription_network_sat_rarity_inscription_id" to table: "inscriptions"
+CREATE INDEX `inscription_network_sat_rarity_inscription_id` ON `inscriptions` (`network`, `sat_rarity`, `inscription_id`);
+-- create index "inscription_network_parent_uid" to table: "inscriptions"
+CREATE INDEX `inscription_network_parent_uid` ON `inscriptions` (`network`, `parent_uid`);
+-- enable back the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = on;
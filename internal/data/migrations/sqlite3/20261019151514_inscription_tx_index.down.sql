@@ -0,0 +1,6 @@
+-- reverse: create index "inscription_network_genesis_height_tx_index" to table: "inscriptions"
+DROP INDEX `inscription_network_genesis_height_tx_index`;
+-- reverse: modify "inscriptions" table
+ALTER TABLE `inscriptions` DROP COLUMN `tx_index`;
+-- reverse: drop index "inscription_network_genesis_height" from table: "inscriptions"
+CREATE INDEX `inscription_network_genesis_height` ON `inscriptions` (`network`, `genesis_height`);
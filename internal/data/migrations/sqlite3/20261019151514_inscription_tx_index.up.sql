@@ -0,0 +1,30 @@
+-- disable the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = off;
+-- create "new_inscriptions" table
+CREATE TABLE `new_inscriptions` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `inscription_id` integer NOT NULL, `uid` text NOT NULL, `address` text NOT NULL, `output_value` integer NOT NULL, `content_length` integer NOT NULL, `content_type` text NOT NULL, `timestamp` datetime NOT NULL, `genesis_height` integer NOT NULL, `tx_index` integer NOT NULL DEFAULT 0, `genesis_fee` integer NOT NULL, `genesis_tx` text NOT NULL, `location` text NOT NULL, `output` text NOT NULL, `offset` integer NOT NULL, `sat` integer NULL, `sat_rarity` text NOT NULL DEFAULT '', `cursed` bool NOT NULL DEFAULT false, `parent_uid` text NOT NULL DEFAULT '', `inscription_children` integer NULL, CONSTRAINT `inscriptions_inscriptions_children` FOREIGN KEY (`inscription_children`) REFERENCES `inscriptions` (`id`) ON DELETE SET NULL);
+-- copy rows from old table "inscriptions" to new temporary table "new_inscriptions"
+INSERT INTO `new_inscriptions` (`id`, `created_at`, `updated_at`, `network`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset`, `sat`, `sat_rarity`, `cursed`, `parent_uid`, `inscription_children`) SELECT `id`, `created_at`, `updated_at`, `network`, `inscription_id`, `uid`, `address`, `output_value`, `content_length`, `content_type`, `timestamp`, `genesis_height`, `genesis_fee`, `genesis_tx`, `location`, `output`, `offset`, `sat`, `sat_rarity`, `cursed`, `parent_uid`, `inscription_children` FROM `inscriptions`;
+-- drop "inscriptions" table after copying rows
+DROP TABLE `inscriptions`;
+-- rename temporary table "new_inscriptions" to "inscriptions"
+ALTER TABLE `new_inscriptions` RENAME TO `inscriptions`;
+-- create index "inscription_network_inscription_id" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_inscription_id` ON `inscriptions` (`network`, `inscription_id`);
+-- create index "inscription_network_uid" to table: "inscriptions"
+CREATE UNIQUE INDEX `inscription_network_uid` ON `inscriptions` (`network`, `uid`);
+-- create index "inscription_network_address_inscription_id" to table: "inscriptions"
+CREATE INDEX `inscription_network_address_inscription_id` ON `inscriptions` (`network`, `address`, `inscription_id`);
+-- create index "inscription_network_content_type" to table: "inscriptions"
+CREATE INDEX `inscription_network_content_type` ON `inscriptions` (`network`, `content_type`);
+-- create index "inscription_network_genesis_height_tx_index" to table: "inscriptions"
+CREATE INDEX `inscription_network_genesis_height_tx_index` ON `inscriptions` (`network`, `genesis_height`, `tx_index`);
+-- create index "inscription_network_timestamp" to table: "inscriptions"
+CREATE INDEX `inscription_network_timestamp` ON `inscriptions` (`network`, `timestamp`);
+-- create index "inscription_network_sat" to table: "inscriptions"
+CREATE INDEX `inscription_network_sat` ON `inscriptions` (`network`, `sat`);
+-- create index "inscription_network_sat_rarity_inscription_id" to table: "inscriptions"
+CREATE INDEX `inscription_network_sat_rarity_inscription_id` ON `inscriptions` (`network`, `sat_rarity`, `inscription_id`);
+-- create index "inscription_network_parent_uid" to table: "inscriptions"
+CREATE INDEX `inscription_network_parent_uid` ON `inscriptions` (`network`, `parent_uid`);
+-- enable back the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = on;
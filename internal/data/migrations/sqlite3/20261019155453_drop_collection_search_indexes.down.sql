@@ -0,0 +1,6 @@
+-- reverse: drop index "collection_description_trgm" from table: "collections"
+CREATE INDEX `collection_description_trgm` ON `collections` (`description`);
+-- reverse: drop index "collection_name_trgm" from table: "collections"
+CREATE INDEX `collection_name_trgm` ON `collections` (`name`);
+-- reverse: drop index "collection_tick_trgm" from table: "collections"
+CREATE INDEX `collection_tick_trgm` ON `collections` (`tick`);
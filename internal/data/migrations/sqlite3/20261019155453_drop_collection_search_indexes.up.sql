@@ -0,0 +1,6 @@
+-- drop index "collection_tick_trgm" from table: "collections"
+DROP INDEX `collection_tick_trgm`;
+-- drop index "collection_name_trgm" from table: "collections"
+DROP INDEX `collection_name_trgm`;
+-- drop index "collection_description_trgm" from table: "collections"
+DROP INDEX `collection_description_trgm`;
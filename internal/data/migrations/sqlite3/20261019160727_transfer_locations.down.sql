@@ -0,0 +1,8 @@
+-- reverse: create index "inscription_network_output" to table: "inscriptions"
+DROP INDEX `inscription_network_output`;
+-- reverse: create index "transfer_token_transfers_block_height" to table: "transfers"
+DROP INDEX `transfer_token_transfers_block_height`;
+-- reverse: modify "transfers" table
+ALTER TABLE `transfers` DROP COLUMN `from_output_value`;
+ALTER TABLE `transfers` DROP COLUMN `to_location`;
+ALTER TABLE `transfers` DROP COLUMN `from_location`;
@@ -0,0 +1,8 @@
+-- modify "transfers" table
+ALTER TABLE `transfers` ADD COLUMN `from_location` text NOT NULL DEFAULT '';
+ALTER TABLE `transfers` ADD COLUMN `to_location` text NOT NULL DEFAULT '';
+ALTER TABLE `transfers` ADD COLUMN `from_output_value` integer NOT NULL DEFAULT 0;
+-- create index "transfer_token_transfers_block_height" to table: "transfers"
+CREATE INDEX `transfer_token_transfers_block_height` ON `transfers` (`token_transfers`, `block_height`);
+-- create index "inscription_network_output" to table: "inscriptions"
+CREATE INDEX `inscription_network_output` ON `inscriptions` (`network`, `output`);
@@ -0,0 +1,4 @@
+-- create "fences" table
+CREATE TABLE `fences` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `name` text NOT NULL, `token` integer NOT NULL);
+-- create index "fence_network_name" to table: "fences"
+CREATE UNIQUE INDEX `fence_network_name` ON `fences` (`network`, `name`);
@@ -0,0 +1,4 @@
+-- reverse: create index "checkpoint_network_name" to table: "checkpoints"
+DROP INDEX `checkpoint_network_name`;
+-- reverse: create "checkpoints" table
+DROP TABLE `checkpoints`;
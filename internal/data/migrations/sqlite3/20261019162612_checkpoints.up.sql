@@ -0,0 +1,4 @@
+-- create "checkpoints" table
+CREATE TABLE `checkpoints` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `network` text NOT NULL DEFAULT 'mainnet', `name` text NOT NULL, `value` text NOT NULL);
+-- create index "checkpoint_network_name" to table: "checkpoints"
+CREATE UNIQUE INDEX `checkpoint_network_name` ON `checkpoints` (`network`, `name`);
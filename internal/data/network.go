@@ -0,0 +1,52 @@
+package data
+
+import (
+	"context"
+
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/intercept"
+)
+
+// fieldNetwork is the network column of all the tables.
+const fieldNetwork = "network"
+
+// NewNetwork returns the network of the ord server.
+func NewNetwork(c *conf.Ord) (*biz.Network, error) {
+	return biz.ParseNetwork(c.GetNetwork())
+}
+
+// networkOf returns the network carried by the context, or the network of the data.
+func (d *Data) networkOf(ctx context.Context) string {
+	if network, ok := biz.NetworkFromContext(ctx); ok {
+		return network
+	}
+	return d.network
+}
+
+// scopeByNetwork filters the queries and the bulk updates and deletes by the
+// network of the context, and creates the rows in it.
+func (d *Data) scopeByNetwork() {
+	d.db.Intercept(intercept.TraverseFunc(func(ctx context.Context, q intercept.Query) error {
+		q.WhereP(sql.FieldEQ(fieldNetwork, d.networkOf(ctx)))
+		return nil
+	}))
+	d.db.Use(func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			switch {
+			case m.Op().Is(ent.OpCreate):
+				if err := m.SetField(fieldNetwork, d.networkOf(ctx)); err != nil {
+					return nil, err
+				}
+			case m.Op().Is(ent.OpUpdate | ent.OpDelete):
+				if w, ok := m.(interface{ WhereP(...func(*sql.Selector)) }); ok {
+					w.WhereP(sql.FieldEQ(fieldNetwork, d.networkOf(ctx)))
+				}
+			}
+			return next.Mutate(ctx, m)
+		})
+	})
+}
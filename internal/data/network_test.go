@@ -0,0 +1,117 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func TestNetworkIsolation(t *testing.T) {
+	r := require.New(t)
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	collections := NewCollectionRepo(d, log.GetLogger())
+	tokens := NewTokenRepo(d, log.GetLogger())
+	mainnet := context.Background()
+	testnet := biz.NewNetworkContext(mainnet, biz.NetworkTestnet)
+
+	// the same collection and token are indexed from both networks.
+	ids := make(map[context.Context]int)
+	for ctx, max := range map[context.Context]uint64{mainnet: 1000, testnet: 10} {
+		collection, err := collections.Create(ctx, &biz.Collection{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           "ordinals",
+			Max:            max,
+			InscriptionID:  1,
+			InscriptionUID: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
+		})
+		r.NoError(err)
+		_, err = tokens.Create(ctx, &biz.Token{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           "ordinals",
+			TokenID:        1,
+			InscriptionID:  2,
+			InscriptionUID: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i0",
+			CollectionID:   collection.ID,
+		})
+		r.NoError(err)
+		ids[ctx] = collection.ID
+	}
+
+	collection, err := collections.FindByTick(testnet, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(biz.NetworkTestnet, collection.Network)
+	r.Equal(uint64(10), collection.Max)
+	collection, err = collections.FindByTick(mainnet, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(biz.NetworkMainnet, collection.Network)
+	r.Equal(uint64(1000), collection.Max)
+	count, err := tokens.Count(testnet)
+	r.NoError(err)
+	r.Equal(1, count)
+
+	// the rows of the other networks are not found, nor deleted.
+	found, err := collections.FindByID(testnet, ids[mainnet])
+	r.NoError(err)
+	r.Nil(found)
+	r.NoError(collections.Delete(testnet, ids[testnet]))
+	found, err = collections.FindByTick(testnet, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Nil(found)
+	found, err = collections.FindByTick(mainnet, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(ids[mainnet], found.ID)
+	count, err = tokens.Count(mainnet)
+	r.NoError(err)
+	r.Equal(1, count)
+
+	// the snapshots are only imported into their network.
+	uc := biz.NewSnapshotUsecase(NewSnapshotRepo(d, log.GetLogger()), log.GetLogger())
+	var snapshot bytes.Buffer
+	_, err = uc.ExportSnapshot(biz.NewNetworkContext(mainnet, biz.NetworkMainnet), &snapshot, 0, 0)
+	r.NoError(err)
+	_, err = uc.ImportSnapshot(testnet, bytes.NewReader(snapshot.Bytes()))
+	r.EqualError(err, "snapshot of mainnet cannot be imported into testnet")
+}
+
+func TestNetworkValidateAddress(t *testing.T) {
+	r := require.New(t)
+	for _, c := range []struct {
+		network string
+		address string
+		err     string
+	}{
+		{biz.NetworkMainnet, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", ""},
+		{biz.NetworkMainnet, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", ""},
+		{biz.NetworkMainnet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", ""},
+		{biz.NetworkMainnet, "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", ""},
+		{biz.NetworkMainnet, "bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf", ""},
+		{biz.NetworkTestnet, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", ""},
+		{biz.NetworkSignet, "tb1pqqqsyqcyq5rqwzqfpg9scrgwpugpzysnzs23v9ccrydpk8qarc0slua5fd", ""},
+		{biz.NetworkTestnet, "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", ""},
+		{biz.NetworkRegtest, "bcrt1qqqqsyqcyq5rqwzqfpg9scrgwpugpzysnard0ew", ""},
+		{biz.NetworkTestnet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "address bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4 is not a testnet address"},
+		{biz.NetworkRegtest, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", "address tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx is not a regtest address"},
+		{biz.NetworkMainnet, "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", "invalid address mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn: unknown address type"},
+		{biz.NetworkMainnet, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", "invalid address 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb: checksum mismatch"},
+		{biz.NetworkMainnet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", "invalid address bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5: invalid checksum"},
+		{biz.NetworkMainnet, "bc1palice", "invalid address bc1palice: invalid character"},
+		{biz.NetworkMainnet, "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "invalid address bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs: unsupported witness version"},
+	} {
+		network, err := biz.ParseNetwork(c.network)
+		r.NoError(err)
+		err = network.ValidateAddress(c.address)
+		if c.err == "" {
+			r.NoError(err, c.address)
+		} else {
+			r.ErrorContains(err, c.err, c.address)
+		}
+	}
+	_, err := biz.ParseNetwork("testnet3")
+	r.EqualError(err, `unknown network "testnet3", expected mainnet, testnet, signet or regtest`)
+}
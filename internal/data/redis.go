@@ -1,17 +1,33 @@
 package data
 
 import (
-	// "context"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
 )
 
 var (
 	lastInscriptionIdKey = "lastInscriptionId"
 )
 
-func collectionTickKey(tick string) string {
-	return "collection:" + tick
+// cacheKeyPatterns are the patterns of all the cached keys of the network.
+func cacheKeyPatterns(network string) []string {
+	return []string{"collection:" + network + ":*", "token:" + network + ":*"}
+}
+
+func collectionTickKey(network, p, tick string) string {
+	return "collection:" + network + ":" + p + ":" + tick
+}
+
+func tokenKey(network, p, tick string, tokenID uint64) string {
+	return fmt.Sprintf("token:%s:%s:%s:%d", network, p, tick, tokenID)
 }
 
 type redisRepo struct {
@@ -42,3 +58,102 @@ func NewRedisRepo(data *Data, logger log.Logger) *redisRepo {
 // func (r *redisRepo) SetCollectionIDByTick(ctx context.Context, tick string, id uint64) error {
 // 	return r.data.rdb.SetUint64(ctx, collectionTickKey(tick), id)
 // }
+
+// cache is a read-through cache of the hot lookups, it's disabled if nil.
+// Errors are logged and treated as cache misses, the database is the source of truth.
+type cache struct {
+	rdb *redis.Client
+	ttl time.Duration
+	log *log.Helper
+}
+
+func newCache(rdb *redis.Client, ttl time.Duration, logger log.Logger) *cache {
+	if rdb == nil || ttl <= 0 {
+		return nil
+	}
+	return &cache{
+		rdb: rdb,
+		ttl: ttl,
+		log: log.NewHelper(logger),
+	}
+}
+
+// get unmarshals the cached value of key into v, and reports whether it was found.
+// It always misses for the contexts bypassing the cache, and in the transactions, as
+// the records they write are not cached until they are committed.
+func (c *cache) get(ctx context.Context, key string, v interface{}) bool {
+	if c == nil || biz.NoCacheFromContext(ctx) || ent.TxFromContext(ctx) != nil {
+		return false
+	}
+	b, err := c.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			c.log.WithContext(ctx).Warnf("failed getting %s from cache: %v", key, err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		c.log.WithContext(ctx).Warnf("failed unmarshaling cached %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+func (c *cache) set(ctx context.Context, key string, v interface{}) {
+	if c == nil || biz.NoCacheFromContext(ctx) || ent.TxFromContext(ctx) != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		c.log.WithContext(ctx).Warnf("failed marshaling %s for cache: %v", key, err)
+		return
+	}
+	if err := c.rdb.Set(ctx, key, b, c.ttl).Err(); err != nil {
+		c.log.WithContext(ctx).Warnf("failed setting %s to cache: %v", key, err)
+	}
+}
+
+// del invalidates the keys, it must be called after every write of the cached records.
+// The keys written in a transaction are invalidated once it is committed.
+func (c *cache) del(ctx context.Context, keys ...string) {
+	if c == nil {
+		return
+	}
+	if tx := ent.TxFromContext(ctx); tx != nil {
+		tx.OnCommit(func(next ent.Committer) ent.Committer {
+			return ent.CommitFunc(func(txCtx context.Context, tx *ent.Tx) error {
+				if err := next.Commit(txCtx, tx); err != nil {
+					return err
+				}
+				c.del(txCtx, keys...)
+				return nil
+			})
+		})
+		return
+	}
+	if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+		c.log.WithContext(ctx).Errorf("failed invalidating %v from cache: %v", keys, err)
+	}
+}
+
+// flush invalidates the keys matching the patterns, eg: after the records are written
+// in bulk.
+func (c *cache) flush(ctx context.Context, patterns ...string) {
+	if c == nil {
+		return
+	}
+	for _, pattern := range patterns {
+		iter := c.rdb.Scan(ctx, 0, pattern, 1000).Iterator()
+		keys := make([]string, 0)
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			c.log.WithContext(ctx).Errorf("failed scanning %s from cache: %v", pattern, err)
+			continue
+		}
+		if len(keys) > 0 {
+			c.del(ctx, keys...)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+// newTCachedData returns a test Data with the cache backed by an in-memory redis.
+func newTCachedData(t *testing.T) (*Data, *miniredis.Miniredis, func()) {
+	mr := miniredis.RunT(t)
+	d, cleanup := NewTData(t)
+	d.rdb = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	d.cache = newCache(d.rdb, time.Minute, log.GetLogger())
+	return d, mr, func() {
+		d.rdb.Close()
+		cleanup()
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	r := require.New(t)
+	rdb := redis.NewClient(&redis.Options{})
+	defer rdb.Close()
+	r.Nil(newCache(rdb, 0, log.GetLogger()))
+	r.Nil(newCache(nil, time.Minute, log.GetLogger()))
+	r.NotNil(newCache(rdb, time.Minute, log.GetLogger()))
+
+	// a disabled cache always misses.
+	var c *cache
+	var v biz.Collection
+	c.set(context.Background(), "key", &v)
+	r.False(c.get(context.Background(), "key", &v))
+	c.del(context.Background(), "key")
+}
+
+func TestCollectionCache(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, mr, cleanup := newTCachedData(t)
+	defer cleanup()
+	repo := NewCollectionRepo(d, log.GetLogger())
+
+	collection, err := repo.Create(ctx, &biz.Collection{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "ordinals",
+		Max:            1000,
+		Attributes:     []map[string]interface{}{{"trait_type": "eyes", "value": "blue"}},
+		BlockTime:      time.Unix(1624296000, 0),
+		InscriptionID:  4984402,
+		InscriptionUID: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
+	})
+	r.NoError(err)
+	key := collectionTickKey(biz.NetworkMainnet, biz.ProtocolTypeBRC721, "ordinals")
+	r.False(mr.Exists(key))
+
+	// the first lookup fills the cache.
+	found, err := repo.FindByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(collection.ID, found.ID)
+	r.True(mr.Exists(key))
+	r.Equal(time.Minute, mr.TTL(key))
+
+	// the next lookups are served from the cache, even if the database changed behind its back.
+	_, err = d.db.Collection.UpdateOneID(collection.ID).SetSupply(10).Save(ctx)
+	r.NoError(err)
+	found, err = repo.FindByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(0), found.Supply)
+	r.Equal(collection.Attributes, found.Attributes)
+	r.Equal(collection.BlockTime.Unix(), found.BlockTime.Unix())
+
+	// writes through the repo invalidate the cache.
+	found.Supply = 11
+	_, err = repo.Update(ctx, found)
+	r.NoError(err)
+	r.False(mr.Exists(key))
+	found, err = repo.FindByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(11), found.Supply)
+
+	// the syncer reads the database only, so a collection cached by the API after
+	// the update is never seen by the next mint.
+	_, err = d.db.Collection.UpdateOneID(collection.ID).SetSupply(12).Save(ctx)
+	r.NoError(err)
+	found, err = repo.FindByTick(biz.NewNoCacheContext(ctx), biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(12), found.Supply)
+	found, err = repo.FindByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(11), found.Supply)
+
+	r.NoError(repo.Delete(ctx, collection.ID))
+	r.False(mr.Exists(key))
+	found, err = repo.FindByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Nil(found)
+}
+
+func TestTokenCache(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, mr, cleanup := newTCachedData(t)
+	defer cleanup()
+	collection, err := NewCollectionRepo(d, log.GetLogger()).Create(ctx, &biz.Collection{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "ordinals",
+		Max:            1000,
+		InscriptionID:  4984402,
+		InscriptionUID: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
+	})
+	r.NoError(err)
+	repo := NewTokenRepo(d, log.GetLogger())
+
+	// missing tokens are not cached, a following mint must be visible.
+	found, err := repo.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 1)
+	r.NoError(err)
+	r.Nil(found)
+	key := tokenKey(biz.NetworkMainnet, biz.ProtocolTypeBRC721, "ordinals", 1)
+	r.False(mr.Exists(key))
+
+	token, err := repo.Create(ctx, &biz.Token{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "ordinals",
+		TokenID:        1,
+		Address:        "bc1pfirst",
+		InscriptionID:  4984403,
+		InscriptionUID: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i0",
+		CollectionID:   collection.ID,
+	})
+	r.NoError(err)
+	found, err = repo.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 1)
+	r.NoError(err)
+	r.Equal(token.ID, found.ID)
+	r.Equal(collection.ID, found.CollectionID)
+	r.True(mr.Exists(key))
+
+	found.Address = "bc1psecond"
+	_, err = repo.Update(ctx, found)
+	r.NoError(err)
+	r.False(mr.Exists(key))
+	found, err = repo.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 1)
+	r.NoError(err)
+	r.Equal("bc1psecond", found.Address)
+
+	// entries expire after the ttl.
+	mr.FastForward(time.Minute)
+	r.False(mr.Exists(key))
+
+	// a broken redis falls back to the database.
+	mr.Close()
+	found, err = repo.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 1)
+	r.NoError(err)
+	r.Equal(token.ID, found.ID)
+}
+
+func TestCacheFlush(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, mr, cleanup := newTCachedData(t)
+	defer cleanup()
+	collection := &biz.Collection{Tick: "ordinals"}
+	for _, key := range []string{
+		collectionTickKey(biz.NetworkMainnet, biz.ProtocolTypeBRC721, "ordinals"),
+		tokenKey(biz.NetworkMainnet, biz.ProtocolTypeBRC721, "ordinals", 1),
+		collectionTickKey(biz.NetworkTestnet, biz.ProtocolTypeBRC721, "ordinals"),
+	} {
+		d.cache.set(ctx, key, collection)
+	}
+	d.cache.flush(ctx, cacheKeyPatterns(biz.NetworkMainnet)...)
+	r.Equal([]string{collectionTickKey(biz.NetworkTestnet, biz.ProtocolTypeBRC721, "ordinals")}, mr.Keys())
+}
@@ -0,0 +1,442 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/collection"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/inscription"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/token"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/transfer"
+)
+
+// snapshotBatchSize is the number of the rows read or created at once.
+const snapshotBatchSize = 1000
+
+type snapshotRepo struct {
+	data         *Data
+	log          *log.Helper
+	collections  *collectionRepo
+	tokens       *tokenRepo
+	traits       *traitRepo
+	inscriptions *inscriptionRepo
+}
+
+// NewSnapshotRepo .
+func NewSnapshotRepo(data *Data, logger log.Logger) biz.SnapshotRepo {
+	return &snapshotRepo{
+		data:         data,
+		log:          log.NewHelper(logger),
+		collections:  NewCollectionRepo(data, logger).(*collectionRepo),
+		tokens:       NewTokenRepo(data, logger).(*tokenRepo),
+		traits:       NewTraitRepo(data, logger).(*traitRepo),
+		inscriptions: NewInscriptionRepo(data, logger).(*inscriptionRepo),
+	}
+}
+
+func (r *snapshotRepo) LastInscriptionID(ctx context.Context, height uint64) (int64, error) {
+	var last int64
+	c, err := r.data.client(ctx).Collection.Query().
+		Where(collection.BlockHeightLTE(height)).
+		Order(ent.Desc(collection.FieldInscriptionID)).
+		First(ctx)
+	if err == nil && c.InscriptionID > last {
+		last = c.InscriptionID
+	} else if err != nil && !ent.IsNotFound(err) {
+		return 0, err
+	}
+	t, err := r.data.client(ctx).Token.Query().
+		Where(token.BlockHeightLTE(height)).
+		Order(ent.Desc(token.FieldInscriptionID)).
+		First(ctx)
+	if err == nil && t.InscriptionID > last {
+		last = t.InscriptionID
+	} else if err != nil && !ent.IsNotFound(err) {
+		return 0, err
+	}
+	i, err := r.data.client(ctx).Inscription.Query().
+		Where(inscription.GenesisHeightLTE(height)).
+		Order(ent.Desc(inscription.FieldInscriptionID)).
+		First(ctx)
+	if err == nil && i.InscriptionID > last {
+		last = i.InscriptionID
+	} else if err != nil && !ent.IsNotFound(err) {
+		return 0, err
+	}
+	return last, nil
+}
+
+func (r *snapshotRepo) Export(ctx context.Context, height uint64, fn func(*biz.SnapshotRecord) error) error {
+	supplies, err := r.supplies(ctx, height)
+	if err != nil {
+		return err
+	}
+	owners, err := r.owners(ctx, height)
+	if err != nil {
+		return err
+	}
+	for lastID := 0; ; {
+		q := r.data.client(ctx).Collection.Query().Where(collection.IDGT(lastID))
+		if height > 0 {
+			q = q.Where(collection.BlockHeightLTE(height))
+		}
+		res, err := q.Order(ent.Asc(collection.FieldID)).Limit(snapshotBatchSize).All(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range res {
+			item := r.collections.fromDbCollection(c)
+			item.ID = 0
+			if supplies != nil {
+				item.Supply = supplies[item.P+"/"+item.Tick]
+			}
+			if err := fn(&biz.SnapshotRecord{Collection: item}); err != nil {
+				return err
+			}
+			lastID = c.ID
+		}
+		if len(res) < snapshotBatchSize {
+			break
+		}
+	}
+	for lastID := 0; ; {
+		q := r.data.client(ctx).Token.Query().Where(token.IDGT(lastID))
+		if height > 0 {
+			q = q.Where(token.BlockHeightLTE(height))
+		}
+		res, err := q.Order(ent.Asc(token.FieldID)).Limit(snapshotBatchSize).All(ctx)
+		if err != nil {
+			return err
+		}
+		ids := make([]int, 0, len(res))
+		for _, t := range res {
+			ids = append(ids, t.ID)
+		}
+		traits, err := r.traits.FindByTokenIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		transfers, err := r.transfers(ctx, ids, height)
+		if err != nil {
+			return err
+		}
+		for _, t := range res {
+			item := r.tokens.fromDbToken(t)
+			item.ID = 0
+			if owner, ok := owners[t.ID]; ok {
+				item.Address = owner.Address
+			}
+			if err := fn(&biz.SnapshotRecord{Token: item, Traits: traits[t.ID], Transfers: transfers[t.ID]}); err != nil {
+				return err
+			}
+			lastID = t.ID
+		}
+		if len(res) < snapshotBatchSize {
+			break
+		}
+	}
+	for lastID := 0; ; {
+		q := r.data.client(ctx).Inscription.Query().Where(inscription.IDGT(lastID))
+		if height > 0 {
+			q = q.Where(inscription.GenesisHeightLTE(height))
+		}
+		res, err := q.Order(ent.Asc(inscription.FieldID)).Limit(snapshotBatchSize).All(ctx)
+		if err != nil {
+			return err
+		}
+		for _, i := range res {
+			item := r.inscriptions.fromDbInscription(i)
+			item.ID = 0
+			if err := fn(&biz.SnapshotRecord{Inscription: item}); err != nil {
+				return err
+			}
+			lastID = i.ID
+		}
+		if len(res) < snapshotBatchSize {
+			break
+		}
+	}
+	return nil
+}
+
+// supplies counts the tokens of the collections minted at the height, or returns
+// nil for the latest state.
+func (r *snapshotRepo) supplies(ctx context.Context, height uint64) (map[string]uint64, error) {
+	if height == 0 {
+		return nil, nil
+	}
+	var rows []struct {
+		P     string `json:"p"`
+		Tick  string `json:"tick"`
+		Count uint64 `json:"count"`
+	}
+	err := r.data.client(ctx).Token.Query().
+		Where(token.BlockHeightLTE(height)).
+		GroupBy(token.FieldP, token.FieldTick).
+		Aggregate(ent.Count()).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+	supplies := make(map[string]uint64, len(rows))
+	for _, row := range rows {
+		supplies[row.P+"/"+row.Tick] = row.Count
+	}
+	return supplies, nil
+}
+
+// owners returns the owners of the tokens at the height keyed by the token id,
+// or nil for the latest state.
+func (r *snapshotRepo) owners(ctx context.Context, height uint64) (map[int]*biz.TokenOwner, error) {
+	if height == 0 {
+		return nil, nil
+	}
+	_, owners, err := r.tokens.listOwners(ctx, "", "", height)
+	return owners, err
+}
+
+// transfers finds the transfers of the tokens at the height keyed by the token id.
+func (r *snapshotRepo) transfers(ctx context.Context, ids []int, height uint64) (map[int][]*biz.Transfer, error) {
+	q := r.data.client(ctx).Transfer.Query().Where(transfer.HasTokenWith(token.IDIn(ids...)))
+	if height > 0 {
+		q = q.Where(transfer.BlockHeightLTE(height))
+	}
+	res, err := q.Order(ent.Asc(transfer.FieldBlockHeight), ent.Asc(transfer.FieldID)).
+		WithToken(func(q *ent.TokenQuery) { q.Select(token.FieldID, token.FieldTokenID) }).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[int][]*biz.Transfer)
+	for _, t := range res {
+		item := fromDbTransfer(t)
+		item.ID = 0
+		item.TokenID = t.Edges.Token.TokenID
+		ret[t.Edges.Token.ID] = append(ret[t.Edges.Token.ID], item)
+	}
+	return ret, nil
+}
+
+func (r *snapshotRepo) Import(ctx context.Context, next func() (*biz.SnapshotRecord, error)) error {
+	err := r.data.InTx(ctx, func(ctx context.Context) error {
+		tx := ent.TxFromContext(ctx)
+		imp := &snapshotImport{tx: tx, collectionIDs: make(map[string]int)}
+		if err := imp.checkEmpty(ctx); err != nil {
+			return err
+		}
+		for {
+			record, err := next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := imp.add(ctx, record); err != nil {
+				return err
+			}
+		}
+		if err := imp.flush(ctx); err != nil {
+			return err
+		}
+		// the inscriptions are linked to their parents once all of them are imported.
+		return linkInscriptionParents(ctx, tx.Inscription)
+	})
+	if err != nil {
+		return err
+	}
+	// the collections and the tokens of the network may be cached before the import.
+	r.data.cache.flush(ctx, cacheKeyPatterns(r.data.networkOf(ctx))...)
+	return nil
+}
+
+// snapshotImport creates the snapshot records in batches.
+type snapshotImport struct {
+	tx            *ent.Tx
+	collections   []*biz.Collection
+	tokens        []*biz.SnapshotRecord
+	inscriptions  []*biz.Inscription
+	collectionIDs map[string]int
+}
+
+func (imp *snapshotImport) checkEmpty(ctx context.Context) error {
+	for _, exist := range []func(context.Context) (bool, error){
+		imp.tx.Collection.Query().Exist,
+		imp.tx.Token.Query().Exist,
+		imp.tx.Inscription.Query().Exist,
+	} {
+		ok, err := exist(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return errors.New("the snapshot can only be imported into an empty database")
+		}
+	}
+	return nil
+}
+
+func (imp *snapshotImport) add(ctx context.Context, record *biz.SnapshotRecord) error {
+	switch {
+	case record.Collection != nil:
+		imp.collections = append(imp.collections, record.Collection)
+	case record.Token != nil:
+		imp.tokens = append(imp.tokens, record)
+	case record.Inscription != nil:
+		imp.inscriptions = append(imp.inscriptions, record.Inscription)
+	default:
+		return nil
+	}
+	if len(imp.collections)+len(imp.tokens)+len(imp.inscriptions) < snapshotBatchSize {
+		return nil
+	}
+	return imp.flush(ctx)
+}
+
+// flush creates the pending collections before the tokens referencing them.
+func (imp *snapshotImport) flush(ctx context.Context) error {
+	if len(imp.collections) > 0 {
+		builders := make([]*ent.CollectionCreate, 0, len(imp.collections))
+		for _, g := range imp.collections {
+			c := imp.tx.Collection.Create().
+				SetP(g.P).
+				SetTick(g.Tick).
+				SetMax(g.Max).
+				SetSupply(g.Supply).
+				SetBaseURI(g.BaseURI).
+				SetName(g.Name).
+				SetDescription(g.Description).
+				SetImage(g.Image).
+				SetAttributes(g.Attributes).
+				SetTxHash(g.TxHash).
+				SetBlockHeight(g.BlockHeight).
+				SetBlockTime(g.BlockTime).
+				SetAddress(g.Address).
+				SetInscriptionID(g.InscriptionID).
+				SetInscriptionUID(g.InscriptionUID).
+				SetChildMints(g.ChildMints)
+			if g.Sig.PubKey != "" {
+				c.SetSig(g.Sig)
+			}
+			builders = append(builders, c)
+		}
+		res, err := imp.tx.Collection.CreateBulk(builders...).Save(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range res {
+			imp.collectionIDs[c.P+"/"+c.Tick] = c.ID
+		}
+		imp.collections = imp.collections[:0]
+	}
+	if len(imp.tokens) > 0 {
+		builders := make([]*ent.TokenCreate, 0, len(imp.tokens))
+		for _, record := range imp.tokens {
+			g := record.Token
+			collectionID, ok := imp.collectionIDs[g.P+"/"+g.Tick]
+			if !ok {
+				return fmt.Errorf("collection %s of token %d is not in the snapshot", g.Tick, g.TokenID)
+			}
+			builders = append(builders, imp.tx.Token.Create().
+				SetP(g.P).
+				SetTick(g.Tick).
+				SetTokenID(g.TokenID).
+				SetTxHash(g.TxHash).
+				SetBlockHeight(g.BlockHeight).
+				SetInscriptionID(g.InscriptionID).
+				SetInscriptionUID(g.InscriptionUID).
+				SetCollectionID(collectionID).
+				SetBlockTime(g.BlockTime).
+				SetAddress(g.Address).
+				SetSig(g.Sig).
+				SetSigUID(g.Sig.Uid))
+		}
+		res, err := imp.tx.Token.CreateBulk(builders...).Save(ctx)
+		if err != nil {
+			return err
+		}
+		traits := make([]*ent.TraitCreate, 0)
+		transfers := make([]*ent.TransferCreate, 0)
+		for i, t := range res {
+			for _, tr := range imp.tokens[i].Traits {
+				traits = append(traits, imp.tx.Trait.Create().
+					SetP(t.P).
+					SetTick(t.Tick).
+					SetTraitType(tr.TraitType).
+					SetValue(tr.Value).
+					SetTokenID(t.ID))
+			}
+			for _, tr := range imp.tokens[i].Transfers {
+				transfers = append(transfers, imp.tx.Transfer.Create().
+					SetP(t.P).
+					SetTick(t.Tick).
+					SetInscriptionUID(t.InscriptionUID).
+					SetFromAddress(tr.From).
+					SetToAddress(tr.To).
+					SetTxHash(tr.TxHash).
+					SetBlockHeight(tr.BlockHeight).
+					SetBlockTime(tr.BlockTime).
+					SetFromLocation(tr.FromLocation).
+					SetToLocation(tr.ToLocation).
+					SetFromOutputValue(tr.FromOutputValue).
+					SetTokenID(t.ID))
+			}
+		}
+		for len(traits) > 0 {
+			n := len(traits)
+			if n > snapshotBatchSize {
+				n = snapshotBatchSize
+			}
+			if _, err := imp.tx.Trait.CreateBulk(traits[:n]...).Save(ctx); err != nil {
+				return err
+			}
+			traits = traits[n:]
+		}
+		for len(transfers) > 0 {
+			n := len(transfers)
+			if n > snapshotBatchSize {
+				n = snapshotBatchSize
+			}
+			if _, err := imp.tx.Transfer.CreateBulk(transfers[:n]...).Save(ctx); err != nil {
+				return err
+			}
+			transfers = transfers[n:]
+		}
+		imp.tokens = imp.tokens[:0]
+	}
+	if len(imp.inscriptions) > 0 {
+		builders := make([]*ent.InscriptionCreate, 0, len(imp.inscriptions))
+		for _, g := range imp.inscriptions {
+			builders = append(builders, imp.tx.Inscription.Create().
+				SetInscriptionID(g.InscriptionID).
+				SetUID(g.UID).
+				SetAddress(g.Address).
+				SetOutputValue(g.OutputValue).
+				SetContentLength(g.ContentLength).
+				SetContentType(g.ContentType).
+				SetTimestamp(g.Timestamp).
+				SetGenesisHeight(g.GenesisHeight).
+				SetTxIndex(g.TxIndex).
+				SetGenesisFee(g.GenesisFee).
+				SetGenesisTx(g.GenesisTx).
+				SetLocation(g.Location).
+				SetOutput(g.Output).
+				SetOffset(g.Offset).
+				SetNillableSat(g.Sat).
+				SetSatRarity(g.SatRarity).
+				SetCursed(g.Cursed).
+				SetParentUID(g.ParentUID))
+		}
+		if _, err := imp.tx.Inscription.CreateBulk(builders...).Save(ctx); err != nil {
+			return err
+		}
+		imp.inscriptions = imp.inscriptions[:0]
+	}
+	return nil
+}
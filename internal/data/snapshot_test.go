@@ -0,0 +1,197 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func createSnapshotState(t *testing.T, d *Data) {
+	r := require.New(t)
+	ctx := context.Background()
+	collections := NewCollectionRepo(d, log.GetLogger())
+	tokens := NewTokenRepo(d, log.GetLogger())
+	traits := NewTraitRepo(d, log.GetLogger())
+	inscriptions := NewInscriptionRepo(d, log.GetLogger())
+	for i, tick := range []string{"ordinals", "later"} {
+		c, err := collections.Create(ctx, &biz.Collection{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           tick,
+			Max:            1000,
+			Supply:         3,
+			BaseURI:        "https://ordinals.com/",
+			Attributes:     []map[string]interface{}{{"trait_type": "eyes", "value": "blue"}},
+			BlockHeight:    uint64(100 + i*200),
+			BlockTime:      time.Unix(1690000000, 0).UTC(),
+			InscriptionID:  int64(1 + i*100),
+			InscriptionUID: fmt.Sprintf("%064di0", 1+i*100),
+		})
+		r.NoError(err)
+		if tick != "ordinals" {
+			continue
+		}
+		for j := 1; j <= 3; j++ {
+			token, err := tokens.Create(ctx, &biz.Token{
+				P:              biz.ProtocolTypeBRC721,
+				Tick:           tick,
+				TokenID:        uint64(j),
+				BlockHeight:    uint64(100 * j),
+				BlockTime:      time.Unix(1690000000, 0).UTC(),
+				Address:        "bc1palice",
+				InscriptionID:  int64(10 + j),
+				InscriptionUID: fmt.Sprintf("%064di0", 10+j),
+				CollectionID:   c.ID,
+			})
+			r.NoError(err)
+			r.NoError(traits.Replace(ctx, token, []*biz.Trait{{TraitType: "eyes", Value: "blue"}, {TraitType: "hat", Value: fmt.Sprint(j)}}))
+		}
+	}
+	// token 2 is transferred at 150 and 300, token 1 at 300.
+	for _, transfer := range []*biz.Transfer{
+		{TokenID: 2, From: "bc1palice", To: "bc1pcarol", BlockHeight: 150},
+		{TokenID: 2, From: "bc1pcarol", To: "bc1pdave", BlockHeight: 300},
+		{TokenID: 1, From: "bc1palice", To: "bc1pdave", BlockHeight: 300},
+	} {
+		transfer.P = biz.ProtocolTypeBRC721
+		transfer.Tick = "ordinals"
+		transfer.TxHash = fmt.Sprintf("%064d", transfer.BlockHeight)
+		transfer.BlockTime = time.Unix(1690000000, 0).UTC()
+		_, err := tokens.Transfer(ctx, transfer)
+		r.NoError(err)
+	}
+	for j := 1; j <= 3; j++ {
+		// the inscriptions are the children of the first one.
+		var parentUID string
+		if j > 1 {
+			parentUID = fmt.Sprintf("%064di0", 11)
+		}
+		_, err := inscriptions.Create(ctx, &biz.Inscription{
+			InscriptionID: int64(10 + j),
+			UID:           fmt.Sprintf("%064di0", 10+j),
+			ContentType:   "text/plain;charset=utf-8",
+			GenesisHeight: uint64(100 * j),
+			Timestamp:     time.Unix(1690000000, 0).UTC(),
+			ParentUID:     parentUID,
+		})
+		r.NoError(err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, cleanup := NewTData(t)
+	createSnapshotState(t, d)
+	uc := biz.NewSnapshotUsecase(NewSnapshotRepo(d, log.GetLogger()), log.GetLogger())
+
+	var latest, snapshot bytes.Buffer
+	trailer, err := uc.ExportSnapshot(ctx, &latest, 0, 1000)
+	r.NoError(err)
+	r.Equal(2, trailer.Collections)
+	r.Equal(3, trailer.Tokens)
+	r.Equal(3, trailer.Inscriptions)
+	// the state and checkpoint at the height.
+	trailer, err = uc.ExportSnapshot(ctx, &snapshot, 200, 1000)
+	r.NoError(err)
+	r.Equal(1, trailer.Collections)
+	r.Equal(2, trailer.Tokens)
+	r.Equal(2, trailer.Inscriptions)
+	// the database is not empty.
+	_, err = uc.ImportSnapshot(ctx, bytes.NewReader(snapshot.Bytes()))
+	r.ErrorContains(err, "empty database")
+	cleanup()
+
+	d, cleanup = NewTData(t)
+	defer cleanup()
+	uc = biz.NewSnapshotUsecase(NewSnapshotRepo(d, log.GetLogger()), log.GetLogger())
+	header, err := uc.ImportSnapshot(ctx, &snapshot)
+	r.NoError(err)
+	r.Equal(uint64(200), header.Height)
+	r.Equal(int64(12), header.Checkpoint)
+
+	collection, err := NewCollectionRepo(d, log.GetLogger()).FindByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(2), collection.Supply)
+	r.Equal("https://ordinals.com/", collection.BaseURI)
+	r.Equal([]map[string]interface{}{{"trait_type": "eyes", "value": "blue"}}, collection.Attributes)
+	tokens, err := NewTokenRepo(d, log.GetLogger()).List(ctx, biz.TokenListOption{Order: "token_id"})
+	r.NoError(err)
+	r.Len(tokens, 2)
+	r.Equal(collection.ID, tokens[1].CollectionID)
+	r.Equal(uint64(200), tokens[1].BlockHeight)
+	r.Equal(fmt.Sprintf("%064di0", 12), tokens[1].InscriptionUID)
+	// the owners and transfers at the height.
+	r.Equal("bc1palice", tokens[0].Address)
+	r.Equal("bc1pcarol", tokens[1].Address)
+	holders, err := biz.NewTokenUsecase(NewTokenRepo(d, log.GetLogger()), log.GetLogger()).ListHolders(ctx, &biz.HolderListOption{Height: 100})
+	r.NoError(err)
+	r.Equal([]*biz.Holder{{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "bc1palice", Count: 1, TokenIDs: []uint64{1}}}, holders)
+	transfers, err := d.db.Transfer.Query().All(ctx)
+	r.NoError(err)
+	r.Len(transfers, 1)
+	r.Equal("bc1pcarol", transfers[0].ToAddress)
+	r.Equal(tokens[1].InscriptionUID, transfers[0].InscriptionUID)
+	traits, err := NewTraitRepo(d, log.GetLogger()).FindByTokenIDs(ctx, []int{tokens[1].ID})
+	r.NoError(err)
+	r.Equal([]*biz.Trait{{TraitType: "eyes", Value: "blue"}, {TraitType: "hat", Value: "2"}}, traits[tokens[1].ID])
+	count, err := NewInscriptionRepo(d, log.GetLogger()).Count(ctx)
+	r.NoError(err)
+	r.Equal(2, count)
+	children, err := NewInscriptionRepo(d, log.GetLogger()).List(ctx, biz.InscriptionListOption{ParentUID: fmt.Sprintf("%064di0", 11)})
+	r.NoError(err)
+	r.Len(children, 1)
+	r.Equal(int64(12), children[0].InscriptionID)
+}
+
+func TestSnapshotCorrupted(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, cleanup := NewTData(t)
+	createSnapshotState(t, d)
+	var snapshot bytes.Buffer
+	_, err := biz.NewSnapshotUsecase(NewSnapshotRepo(d, log.GetLogger()), log.GetLogger()).ExportSnapshot(ctx, &snapshot, 0, 0)
+	r.NoError(err)
+	cleanup()
+
+	gz, err := gzip.NewReader(&snapshot)
+	r.NoError(err)
+	content, err := io.ReadAll(gz)
+	r.NoError(err)
+	compress := func(b []byte) io.Reader {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(b)
+		w.Close()
+		return &buf
+	}
+	last := bytes.LastIndex(content[:len(content)-1], []byte("\n"))
+
+	d, cleanup = NewTData(t)
+	defer cleanup()
+	uc := biz.NewSnapshotUsecase(NewSnapshotRepo(d, log.GetLogger()), log.GetLogger())
+	for name, c := range map[string]struct {
+		content []byte
+		err     string
+	}{
+		"tampered":  {bytes.Replace(content, []byte("bc1palice"), []byte("bc1pmallory"), 1), "checksum mismatch"},
+		"truncated": {content[:last+1], "missing trailer"},
+		"trailing":  {append(append([]byte{}, content...), content...), "trailing data"},
+		"no header": {content[bytes.IndexByte(content, '\n')+1:], "missing header"},
+	} {
+		_, err := uc.ImportSnapshot(ctx, compress(c.content))
+		r.ErrorContains(err, c.err, name)
+		// nothing is imported.
+		count, err := NewCollectionRepo(d, log.GetLogger()).Count(ctx)
+		r.NoError(err)
+		r.Equal(0, count, name)
+	}
+}
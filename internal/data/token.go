@@ -4,6 +4,9 @@ import (
 	"context"
 	"strings"
 
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqljson"
+
 	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/data/ent"
 	"github.com/adshao/ordinals-indexer/internal/data/ent/token"
@@ -31,7 +34,7 @@ func NewTokenRepo(data *Data, logger log.Logger) biz.TokenRepo {
 }
 
 func (r *tokenRepo) Create(ctx context.Context, g *biz.Token) (*biz.Token, error) {
-	res, err := r.data.db.Token.Create().
+	res, err := r.data.client(ctx).Token.Create().
 		SetP(g.P).
 		SetTick(g.Tick).
 		SetTokenID(g.TokenID).
@@ -48,12 +51,14 @@ func (r *tokenRepo) Create(ctx context.Context, g *biz.Token) (*biz.Token, error
 	if err != nil {
 		return nil, err
 	}
+	r.data.cache.del(ctx, tokenKey(res.Network, res.P, res.Tick, res.TokenID))
 	return r.fromDbToken(res), nil
 }
 
 func (r *tokenRepo) fromDbToken(t *ent.Token) *biz.Token {
 	token := &biz.Token{
 		ID:             t.ID,
+		Network:        t.Network,
 		Tick:           t.Tick,
 		P:              t.P,
 		TokenID:        t.TokenID,
@@ -72,7 +77,7 @@ func (r *tokenRepo) fromDbToken(t *ent.Token) *biz.Token {
 }
 
 func (r *tokenRepo) Update(ctx context.Context, g *biz.Token) (*biz.Token, error) {
-	u := r.data.db.Token.UpdateOneID(g.ID).
+	u := r.data.client(ctx).Token.UpdateOneID(g.ID).
 		SetP(g.P).
 		SetTick(g.Tick).
 		SetTokenID(g.TokenID).
@@ -91,13 +96,21 @@ func (r *tokenRepo) Update(ctx context.Context, g *biz.Token) (*biz.Token, error
 	if err != nil {
 		return nil, err
 	}
+	r.data.cache.del(ctx, tokenKey(res.Network, res.P, res.Tick, res.TokenID))
 	return r.fromDbToken(res), nil
 }
 
 func (r *tokenRepo) FindByTickTokenID(ctx context.Context, p, tick string, tokenID uint64) (*biz.Token, error) {
-	res, err := r.data.db.Token.Query().Where(token.P(p), token.Tick(tick), token.TokenID(tokenID)).WithCollection().Only(ctx)
+	key := tokenKey(r.data.networkOf(ctx), p, tick, tokenID)
+	var cached biz.Token
+	if r.data.cache.get(ctx, key, &cached) {
+		return &cached, nil
+	}
+	res, err := r.data.client(ctx).Token.Query().Where(token.P(p), token.Tick(tick), token.TokenID(tokenID)).WithCollection().Only(ctx)
 	if err == nil {
-		return r.fromDbToken(res), nil
+		t := r.fromDbToken(res)
+		r.data.cache.set(ctx, key, t)
+		return t, nil
 	}
 	if ent.IsNotFound(err) {
 		return nil, nil
@@ -106,7 +119,7 @@ func (r *tokenRepo) FindByTickTokenID(ctx context.Context, p, tick string, token
 }
 
 func (r *tokenRepo) FindByInscriptionID(ctx context.Context, id int64) ([]*biz.Token, error) {
-	res, err := r.data.db.Token.Query().Where(token.InscriptionID(id)).WithCollection().All(ctx)
+	res, err := r.data.client(ctx).Token.Query().Where(token.InscriptionID(id)).WithCollection().All(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +131,7 @@ func (r *tokenRepo) FindByInscriptionID(ctx context.Context, id int64) ([]*biz.T
 }
 
 func (r *tokenRepo) FindByTickSigUID(ctx context.Context, p, tick, sigUID string) (*biz.Token, error) {
-	res, err := r.data.db.Token.Query().Where(token.P(p), token.Tick(tick), token.SigUID(sigUID)).WithCollection().Only(ctx)
+	res, err := r.data.client(ctx).Token.Query().Where(token.P(p), token.Tick(tick), token.SigUID(sigUID)).WithCollection().Only(ctx)
 	if err == nil {
 		return r.fromDbToken(res), nil
 	}
@@ -129,7 +142,7 @@ func (r *tokenRepo) FindByTickSigUID(ctx context.Context, p, tick, sigUID string
 }
 
 func (r *tokenRepo) List(ctx context.Context, opts ...biz.TokenListOption) ([]*biz.Token, error) {
-	q := r.data.db.Token.Query()
+	q := r.data.client(ctx).Token.Query()
 	var opt biz.TokenListOption
 	if len(opts) > 0 {
 		opt = opts[0]
@@ -142,12 +155,7 @@ func (r *tokenRepo) List(ctx context.Context, opts ...biz.TokenListOption) ([]*b
 	if opt.Offset != 0 {
 		q = q.Offset(opt.Offset)
 	}
-	if opt.P != "" {
-		q = q.Where(token.P(opt.P))
-	}
-	if opt.Tick != "" {
-		q = q.Where(token.Tick(opt.Tick))
-	}
+	q = r.filter(q, opt)
 	// order format: field1,-field2
 	if opt.Order != "" {
 		orders := strings.Split(opt.Order, ",")
@@ -180,6 +188,55 @@ func (r *tokenRepo) List(ctx context.Context, opts ...biz.TokenListOption) ([]*b
 	return ret, nil
 }
 
+// filter filters the tokens by the list option.
+func (r *tokenRepo) filter(q *ent.TokenQuery, opt biz.TokenListOption) *ent.TokenQuery {
+	if opt.P != "" {
+		q = q.Where(token.P(opt.P))
+	}
+	if opt.Tick != "" {
+		q = q.Where(token.Tick(opt.Tick))
+	}
+	if len(opt.Traits) > 0 {
+		q = q.Where(hasTraits(opt.Traits)...)
+	}
+	if opt.InscriptionIDFrom != 0 {
+		q = q.Where(token.InscriptionIDGTE(opt.InscriptionIDFrom))
+	}
+	if opt.BlockHeightFrom != 0 {
+		q = q.Where(token.BlockHeightGTE(opt.BlockHeightFrom))
+	}
+	if opt.SigUID != "" {
+		q = q.Where(token.SigUID(opt.SigUID))
+	}
+	if opt.SigReceiver != "" {
+		q = q.Where(func(s *sql.Selector) {
+			s.Where(sqljson.ValueEQ(s.C(token.FieldSig), opt.SigReceiver, sqljson.Path("rec"), sqljson.Unquote(true)))
+		})
+	}
+	q = filterSigRange(q, "expt", opt.SigExpiredTimeFrom, opt.SigExpiredTimeTo)
+	q = filterSigRange(q, "exph", opt.SigExpiredHeightFrom, opt.SigExpiredHeightTo)
+	return q
+}
+
+// filterSigRange filters the tokens signed with the field in the inclusive range,
+// the tokens without the signed field are excluded.
+func filterSigRange(q *ent.TokenQuery, field string, from, to uint64) *ent.TokenQuery {
+	if from == 0 && to == 0 {
+		return q
+	}
+	if from == 0 {
+		from = 1
+	}
+	return q.Where(func(s *sql.Selector) {
+		// the unix times do not fit into the int cast on postgres.
+		preds := []*sql.Predicate{sqljson.ValueGTE(s.C(token.FieldSig), from, sqljson.Path(field), sqljson.Cast("bigint"))}
+		if to != 0 {
+			preds = append(preds, sqljson.ValueLTE(s.C(token.FieldSig), to, sqljson.Path(field), sqljson.Cast("bigint")))
+		}
+		s.Where(sql.And(preds...))
+	})
+}
+
 func (r *tokenRepo) inOrderFieldsWhiteList(field string) bool {
 	for _, f := range r.orderFieldsWhiteList {
 		if f == field {
@@ -190,20 +247,23 @@ func (r *tokenRepo) inOrderFieldsWhiteList(field string) bool {
 }
 
 func (r *tokenRepo) Delete(ctx context.Context, id int) error {
-	return r.data.db.Token.DeleteOneID(id).Exec(ctx)
+	res, err := r.data.client(ctx).Token.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.data.client(ctx).Token.DeleteOneID(id).Exec(ctx); err != nil {
+		return err
+	}
+	r.data.cache.del(ctx, tokenKey(res.Network, res.P, res.Tick, res.TokenID))
+	return nil
 }
 
 func (r *tokenRepo) Count(ctx context.Context, opts ...biz.TokenListOption) (int, error) {
-	q := r.data.db.Token.Query()
+	q := r.data.client(ctx).Token.Query()
 	var opt biz.TokenListOption
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
-	if opt.P != "" {
-		q = q.Where(token.P(opt.P))
-	}
-	if opt.Tick != "" {
-		q = q.Where(token.Tick(opt.Tick))
-	}
+	q = r.filter(q, opt)
 	return q.Count(ctx)
 }
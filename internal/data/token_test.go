@@ -0,0 +1,69 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/adshao/go-brc721/sig"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func TestTokenSigFilters(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	collection, err := NewCollectionRepo(d, log.GetLogger()).Create(ctx, &biz.Collection{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "ordinals",
+		Max:            1000,
+		InscriptionID:  1,
+		InscriptionUID: fmt.Sprintf("%064di0", 1),
+	})
+	r.NoError(err)
+	repo := NewTokenRepo(d, log.GetLogger())
+	for i, s := range []sig.MintSig{
+		{Signature: "s1", Receiver: "bc1palice", Uid: "uid1", ExpiredTime: 1690000000, ExpiredHeight: 800000},
+		{Signature: "s2", Receiver: "bc1pbob", Uid: "uid2", ExpiredTime: 4102444800},
+		{Signature: "s3", Receiver: "bc1palice", Uid: "uid3", ExpiredHeight: 900000},
+		{},
+	} {
+		_, err := repo.Create(ctx, &biz.Token{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           "ordinals",
+			TokenID:        uint64(i + 1),
+			InscriptionID:  int64(i + 2),
+			InscriptionUID: fmt.Sprintf("%064di0", i+2),
+			CollectionID:   collection.ID,
+			Sig:            s,
+		})
+		r.NoError(err)
+	}
+
+	list := func(opt biz.TokenListOption) []uint64 {
+		opt.Order = "token_id"
+		res, err := repo.List(ctx, opt)
+		r.NoError(err)
+		count, err := repo.Count(ctx, opt)
+		r.NoError(err)
+		r.Equal(len(res), count)
+		ids := make([]uint64, 0)
+		for _, token := range res {
+			ids = append(ids, token.TokenID)
+		}
+		return ids
+	}
+	r.Equal([]uint64{2}, list(biz.TokenListOption{SigUID: "uid2"}))
+	r.Equal([]uint64{1, 3}, list(biz.TokenListOption{SigReceiver: "bc1palice"}))
+	r.Empty(list(biz.TokenListOption{SigReceiver: "bc1palice", SigUID: "uid2"}))
+	// the tokens without the signed expiry are excluded.
+	r.Equal([]uint64{1, 2}, list(biz.TokenListOption{SigExpiredTimeTo: 4102444800}))
+	r.Equal([]uint64{2}, list(biz.TokenListOption{SigExpiredTimeFrom: 1700000000}))
+	r.Equal([]uint64{1}, list(biz.TokenListOption{SigExpiredTimeFrom: 1600000000, SigExpiredTimeTo: 1700000000}))
+	r.Equal([]uint64{1, 3}, list(biz.TokenListOption{SigExpiredHeightFrom: 800000}))
+	r.Equal([]uint64{3}, list(biz.TokenListOption{SigExpiredHeightFrom: 800001, SigReceiver: "bc1palice"}))
+}
@@ -0,0 +1,118 @@
+package data
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/predicate"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/token"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/trait"
+)
+
+type traitRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewTraitRepo .
+func NewTraitRepo(data *Data, logger log.Logger) biz.TraitRepo {
+	return &traitRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+func (r *traitRepo) Replace(ctx context.Context, t *biz.Token, traits []*biz.Trait) error {
+	return r.data.InTx(ctx, func(ctx context.Context) error {
+		client := r.data.client(ctx)
+		_, err := client.Trait.Delete().Where(trait.HasTokenWith(token.ID(t.ID))).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		builders := make([]*ent.TraitCreate, 0, len(traits))
+		for _, tr := range traits {
+			builders = append(builders, client.Trait.Create().
+				SetP(t.P).
+				SetTick(t.Tick).
+				SetTraitType(tr.TraitType).
+				SetValue(tr.Value).
+				SetTokenID(t.ID))
+		}
+		if len(builders) == 0 {
+			return nil
+		}
+		_, err = client.Trait.CreateBulk(builders...).Save(ctx)
+		return err
+	})
+}
+
+func (r *traitRepo) FindByTokenIDs(ctx context.Context, ids []int) (map[int][]*biz.Trait, error) {
+	traits := make(map[int][]*biz.Trait)
+	if len(ids) == 0 {
+		return traits, nil
+	}
+	res, err := r.data.client(ctx).Trait.Query().
+		Where(trait.HasTokenWith(token.IDIn(ids...))).
+		WithToken(func(q *ent.TokenQuery) {
+			q.Select(token.FieldID)
+		}).
+		Order(ent.Asc(trait.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range res {
+		id := t.Edges.Token.ID
+		traits[id] = append(traits[id], &biz.Trait{TraitType: t.TraitType, Value: t.Value})
+	}
+	return traits, nil
+}
+
+func (r *traitRepo) CountByTick(ctx context.Context, p, tick string) ([]*biz.TraitStat, error) {
+	var rows []struct {
+		TraitType string `json:"trait_type"`
+		Value     string `json:"value"`
+		Count     int    `json:"count"`
+	}
+	err := r.data.client(ctx).Trait.Query().
+		Where(trait.P(p), trait.Tick(tick)).
+		GroupBy(trait.FieldTraitType, trait.FieldValue).
+		Aggregate(ent.Count()).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].TraitType != rows[j].TraitType {
+			return rows[i].TraitType < rows[j].TraitType
+		}
+		return rows[i].Value < rows[j].Value
+	})
+	stats := make([]*biz.TraitStat, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, &biz.TraitStat{TraitType: row.TraitType, Value: row.Value, Count: row.Count})
+	}
+	return stats, nil
+}
+
+// hasTraits filters the tokens having the traits, the values of the same
+// trait type are OR'ed, and the different trait types are AND'ed.
+func hasTraits(traits []*biz.Trait) []predicate.Token {
+	values := make(map[string][]string)
+	types := make([]string, 0)
+	for _, t := range traits {
+		if _, ok := values[t.TraitType]; !ok {
+			types = append(types, t.TraitType)
+		}
+		values[t.TraitType] = append(values[t.TraitType], t.Value)
+	}
+	preds := make([]predicate.Token, 0, len(types))
+	for _, traitType := range types {
+		preds = append(preds, token.HasTraitsWith(trait.TraitType(traitType), trait.ValueIn(values[traitType]...)))
+	}
+	return preds
+}
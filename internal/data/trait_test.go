@@ -0,0 +1,95 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func TestTraits(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	collection, err := NewCollectionRepo(d, log.GetLogger()).Create(ctx, &biz.Collection{
+		P:              biz.ProtocolTypeBRC721,
+		Tick:           "ordinals",
+		Max:            1000,
+		InscriptionID:  1,
+		InscriptionUID: fmt.Sprintf("%064di0", 1),
+	})
+	r.NoError(err)
+	tokenRepo := NewTokenRepo(d, log.GetLogger())
+	repo := NewTraitRepo(d, log.GetLogger())
+	uc := biz.NewTraitUsecase(repo, tokenRepo, log.GetLogger())
+
+	tokens := make([]*biz.Token, 0)
+	for i, traits := range [][]*biz.Trait{
+		{{TraitType: "eyes", Value: "blue"}, {TraitType: "hat", Value: "cap"}},
+		{{TraitType: "eyes", Value: "blue"}, {TraitType: "hat", Value: "crown"}},
+		{{TraitType: "eyes", Value: "red"}, {TraitType: "hat", Value: "cap"}},
+		{{TraitType: "eyes", Value: "blue"}},
+	} {
+		token, err := tokenRepo.Create(ctx, &biz.Token{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           "ordinals",
+			TokenID:        uint64(i + 1),
+			InscriptionID:  int64(i + 2),
+			InscriptionUID: fmt.Sprintf("%064di0", i+2),
+			CollectionID:   collection.ID,
+		})
+		r.NoError(err)
+		r.NoError(uc.SetTokenTraits(ctx, token, traits))
+		tokens = append(tokens, token)
+	}
+	// replacing the traits is idempotent.
+	r.NoError(uc.SetTokenTraits(ctx, tokens[3], []*biz.Trait{{TraitType: "eyes", Value: "blue"}}))
+
+	stats, err := uc.GetCollectionTraits(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal([]*biz.TraitStat{
+		{TraitType: "eyes", Value: "blue", Count: 3, Frequency: 0.75, RarityScore: 4.0 / 3},
+		{TraitType: "eyes", Value: "red", Count: 1, Frequency: 0.25, RarityScore: 4},
+		{TraitType: "hat", Value: "cap", Count: 2, Frequency: 0.5, RarityScore: 2},
+		{TraitType: "hat", Value: "crown", Count: 1, Frequency: 0.25, RarityScore: 4},
+	}, stats)
+
+	rarities, err := uc.GetTokensRarity(ctx, tokens)
+	r.NoError(err)
+	r.Len(rarities, 4)
+	r.Equal(4.0/3+4, rarities[tokens[1].ID].RarityScore)
+	r.Equal(6.0, rarities[tokens[2].ID].RarityScore)
+	r.Len(rarities[tokens[3].ID].Traits, 1)
+
+	list := func(traits ...*biz.Trait) []uint64 {
+		opt := biz.TokenListOption{Tick: "ordinals", Order: "token_id", Traits: traits}
+		res, err := tokenRepo.List(ctx, opt)
+		r.NoError(err)
+		count, err := tokenRepo.Count(ctx, opt)
+		r.NoError(err)
+		r.Equal(len(res), count)
+		ids := make([]uint64, 0)
+		for _, token := range res {
+			ids = append(ids, token.TokenID)
+		}
+		return ids
+	}
+	r.Equal([]uint64{1, 2, 4}, list(&biz.Trait{TraitType: "eyes", Value: "blue"}))
+	// the values of the same trait type are OR'ed.
+	r.Equal([]uint64{1, 2, 3}, list(&biz.Trait{TraitType: "hat", Value: "cap"}, &biz.Trait{TraitType: "hat", Value: "crown"}))
+	// the trait types are AND'ed.
+	r.Equal([]uint64{1}, list(&biz.Trait{TraitType: "eyes", Value: "blue"}, &biz.Trait{TraitType: "hat", Value: "cap"}))
+	r.Empty(list(&biz.Trait{TraitType: "eyes", Value: "green"}))
+
+	// the traits are deleted with the token.
+	r.NoError(tokenRepo.Delete(ctx, tokens[2].ID))
+	traits, err := repo.FindByTokenIDs(ctx, []int{tokens[0].ID, tokens[2].ID})
+	r.NoError(err)
+	r.Len(traits, 1)
+	r.Equal([]*biz.Trait{{TraitType: "eyes", Value: "blue"}, {TraitType: "hat", Value: "cap"}}, traits[tokens[0].ID])
+}
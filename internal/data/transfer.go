@@ -0,0 +1,251 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data/ent"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/predicate"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/token"
+	"github.com/adshao/ordinals-indexer/internal/data/ent/transfer"
+)
+
+func (r *tokenRepo) Transfer(ctx context.Context, g *biz.Transfer) (*biz.Transfer, error) {
+	var ret *biz.Transfer
+	err := r.data.InTx(ctx, func(ctx context.Context) error {
+		client := r.data.client(ctx)
+		t, err := client.Token.Query().Where(token.P(g.P), token.Tick(g.Tick), token.TokenID(g.TokenID)).Only(ctx)
+		if ent.IsNotFound(err) {
+			return fmt.Errorf("token %s #%d not found", g.Tick, g.TokenID)
+		}
+		if err != nil {
+			return err
+		}
+		// the block of the transfer may be synced again, eg: after a crash.
+		res, err := client.Transfer.Query().Where(transfer.TxHash(g.TxHash), transfer.HasTokenWith(token.ID(t.ID))).Only(ctx)
+		if err == nil {
+			ret = fromDbTransfer(res)
+			ret.TokenID = t.TokenID
+			return nil
+		}
+		if !ent.IsNotFound(err) {
+			return err
+		}
+		res, err = client.Transfer.Create().
+			SetP(t.P).
+			SetTick(t.Tick).
+			SetInscriptionUID(t.InscriptionUID).
+			SetFromAddress(g.From).
+			SetToAddress(g.To).
+			SetTxHash(g.TxHash).
+			SetBlockHeight(g.BlockHeight).
+			SetBlockTime(g.BlockTime).
+			SetFromLocation(g.FromLocation).
+			SetToLocation(g.ToLocation).
+			SetFromOutputValue(g.FromOutputValue).
+			SetToken(t).
+			Save(ctx)
+		if err != nil {
+			return err
+		}
+		// the transfers may be recorded out of order.
+		later, err := client.Transfer.Query().
+			Where(transfer.HasTokenWith(token.ID(t.ID)), transfer.BlockHeightGT(g.BlockHeight)).
+			Exist(ctx)
+		if err != nil {
+			return err
+		}
+		if !later {
+			if err := client.Token.UpdateOneID(t.ID).SetAddress(g.To).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		r.data.cache.del(ctx, tokenKey(t.Network, t.P, t.Tick, t.TokenID))
+		ret = fromDbTransfer(res)
+		ret.TokenID = t.TokenID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *tokenRepo) DeleteTransfersFrom(ctx context.Context, height uint64) ([]*biz.Transfer, error) {
+	var ret []*biz.Transfer
+	err := r.data.InTx(ctx, func(ctx context.Context) error {
+		client := r.data.client(ctx)
+		res, err := client.Transfer.Query().
+			Where(transfer.BlockHeightGTE(height)).
+			Order(ent.Desc(transfer.FieldBlockHeight), ent.Desc(transfer.FieldID)).
+			WithToken().
+			All(ctx)
+		if err != nil {
+			return err
+		}
+		ret = make([]*biz.Transfer, 0, len(res))
+		// the owner before the height is the sender of the first transfer deleted.
+		owners := make(map[int]string)
+		tokens := make(map[int]*ent.Token)
+		for _, t := range res {
+			item := fromDbTransfer(t)
+			item.TokenID = t.Edges.Token.TokenID
+			ret = append(ret, item)
+			owners[t.Edges.Token.ID] = t.FromAddress
+			tokens[t.Edges.Token.ID] = t.Edges.Token
+		}
+		if _, err := client.Transfer.Delete().Where(transfer.BlockHeightGTE(height)).Exec(ctx); err != nil {
+			return err
+		}
+		for id, address := range owners {
+			if err := client.Token.UpdateOneID(id).SetAddress(address).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		for _, t := range tokens {
+			r.data.cache.del(ctx, tokenKey(t.Network, t.P, t.Tick, t.TokenID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func fromDbTransfer(t *ent.Transfer) *biz.Transfer {
+	return &biz.Transfer{
+		ID:              t.ID,
+		Network:         t.Network,
+		P:               t.P,
+		Tick:            t.Tick,
+		InscriptionUID:  t.InscriptionUID,
+		From:            t.FromAddress,
+		To:              t.ToAddress,
+		TxHash:          t.TxHash,
+		BlockHeight:     t.BlockHeight,
+		BlockTime:       t.BlockTime,
+		FromLocation:    t.FromLocation,
+		ToLocation:      t.ToLocation,
+		FromOutputValue: t.FromOutputValue,
+	}
+}
+
+func (r *tokenRepo) ListOwners(ctx context.Context, p, tick string, height uint64) ([]*biz.TokenOwner, error) {
+	owners, _, err := r.listOwners(ctx, p, tick, height)
+	return owners, err
+}
+
+// listOwners lists the owners of the tokens at the height, and the owners keyed
+// by the token id in the database.
+func (r *tokenRepo) listOwners(ctx context.Context, p, tick string, height uint64) ([]*biz.TokenOwner, map[int]*biz.TokenOwner, error) {
+	owners := make([]*biz.TokenOwner, 0)
+	ids := make(map[int]*biz.TokenOwner)
+	for lastID := 0; ; {
+		q := r.data.client(ctx).Token.Query().Where(token.IDGT(lastID))
+		if p != "" {
+			q = q.Where(token.P(p))
+		}
+		if tick != "" {
+			q = q.Where(token.Tick(tick))
+		}
+		if height > 0 {
+			q = q.Where(token.BlockHeightLTE(height))
+		}
+		res, err := q.Order(ent.Asc(token.FieldID)).
+			Select(token.FieldP, token.FieldTick, token.FieldTokenID, token.FieldAddress).
+			Limit(snapshotBatchSize).
+			All(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, t := range res {
+			owner := &biz.TokenOwner{P: t.P, Tick: t.Tick, TokenID: t.TokenID, Address: t.Address}
+			owners = append(owners, owner)
+			ids[t.ID] = owner
+			lastID = t.ID
+		}
+		if len(res) < snapshotBatchSize {
+			break
+		}
+	}
+	// the latest owners are up to date with the transfers.
+	if height == 0 || len(owners) == 0 {
+		return owners, ids, nil
+	}
+	// the owner at the height is the receiver of the last transfer at the height,
+	// or the sender of the first transfer after the height.
+	tokenIDs := make([]int, 0, len(ids))
+	for id := range ids {
+		tokenIDs = append(tokenIDs, id)
+	}
+	sort.Ints(tokenIDs)
+	for len(tokenIDs) > 0 {
+		n := len(tokenIDs)
+		if n > snapshotBatchSize {
+			n = snapshotBatchSize
+		}
+		batch := tokenIDs[:n]
+		tokenIDs = tokenIDs[n:]
+		// the first transfers after the height are overridden by the last ones at the height.
+		for _, f := range []struct {
+			op      sql.Op
+			order   func(string) string
+			address string
+		}{
+			{sql.OpGT, sql.Asc, transfer.FieldFromAddress},
+			{sql.OpLTE, sql.Desc, transfer.FieldToAddress},
+		} {
+			var rows []struct {
+				TokenID     int    `json:"token_transfers"`
+				FromAddress string `json:"from_address"`
+				ToAddress   string `json:"to_address"`
+			}
+			err := r.data.client(ctx).Transfer.Query().
+				Where(tokenIn(batch), firstTransfer(f.op, height, f.order)).
+				Select(transfer.TokenColumn, f.address).
+				Scan(ctx, &rows)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				if f.address == transfer.FieldFromAddress {
+					ids[row.TokenID].Address = row.FromAddress
+				} else {
+					ids[row.TokenID].Address = row.ToAddress
+				}
+			}
+		}
+	}
+	return owners, ids, nil
+}
+
+// tokenIn filters the transfers of the tokens by id.
+func tokenIn(ids []int) predicate.Transfer {
+	return func(s *sql.Selector) {
+		s.Where(sql.InInts(s.C(transfer.TokenColumn), ids...))
+	}
+}
+
+// firstTransfer filters the first transfer of each token in the order of the block
+// height, then the id, among the transfers matching the op of the height. The rows
+// are picked by the index of the token and the block height.
+func firstTransfer(op sql.Op, height uint64, order func(string) string) predicate.Transfer {
+	return func(s *sql.Selector) {
+		t := sql.Table(transfer.Table).As("first_transfers")
+		sub := sql.Select(t.C(transfer.FieldID)).From(t).
+			Where(sql.And(
+				sql.ColumnsEQ(t.C(transfer.TokenColumn), s.C(transfer.TokenColumn)),
+				sql.P(func(b *sql.Builder) { b.Ident(t.C(transfer.FieldBlockHeight)).WriteOp(op).Arg(height) }),
+			)).
+			OrderBy(order(t.C(transfer.FieldBlockHeight)), order(t.C(transfer.FieldID))).
+			Limit(1)
+		s.Where(sql.P(func(b *sql.Builder) {
+			b.Ident(s.C(transfer.FieldID)).WriteOp(sql.OpEQ).Wrap(func(b *sql.Builder) { b.Join(sub) })
+		}))
+	}
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func TestHolders(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, cleanup := NewTData(t)
+	defer cleanup()
+	collections := NewCollectionRepo(d, log.GetLogger())
+	tokenRepo := NewTokenRepo(d, log.GetLogger())
+	uc := biz.NewTokenUsecase(tokenRepo, log.GetLogger())
+	for i, tick := range []string{"ordinals", "other"} {
+		collection, err := collections.Create(ctx, &biz.Collection{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           tick,
+			Max:            1000,
+			InscriptionID:  int64(i + 1),
+			InscriptionUID: fmt.Sprintf("%064di0", i+1),
+		})
+		r.NoError(err)
+		// alice mints 3 tokens at 100, 200 and 300, bob mints 1 token at 100.
+		for j, address := range []string{"alice", "alice", "alice", "bob"} {
+			_, err := tokenRepo.Create(ctx, &biz.Token{
+				P:              biz.ProtocolTypeBRC721,
+				Tick:           tick,
+				TokenID:        uint64(j + 1),
+				BlockHeight:    uint64(100 * (j%3 + 1)),
+				Address:        address,
+				InscriptionID:  int64(10*(i+1) + j),
+				InscriptionUID: fmt.Sprintf("%064di0", 10*(i+1)+j),
+				CollectionID:   collection.ID,
+			})
+			r.NoError(err)
+		}
+	}
+	transfer := func(tokenID uint64, from, to string, height uint64) {
+		_, err := uc.TransferToken(ctx, &biz.Transfer{
+			P:           biz.ProtocolTypeBRC721,
+			Tick:        "ordinals",
+			TokenID:     tokenID,
+			From:        from,
+			To:          to,
+			TxHash:      fmt.Sprintf("%064d", height),
+			BlockHeight: height,
+		})
+		r.NoError(err)
+	}
+	// the transfers recorded out of order.
+	transfer(1, "carol", "dave", 400)
+	transfer(1, "alice", "carol", 150)
+	transfer(2, "alice", "bob", 250)
+	_, err := uc.TransferToken(ctx, &biz.Transfer{P: biz.ProtocolTypeBRC721, Tick: "ordinals", TokenID: 5, To: "bob"})
+	r.ErrorContains(err, "token ordinals #5 not found")
+
+	token, err := tokenRepo.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 1)
+	r.NoError(err)
+	r.Equal("dave", token.Address)
+	// the transfer of the same tx is recorded once, eg: the block is synced again.
+	transfer(2, "alice", "bob", 250)
+
+	for _, c := range []struct {
+		height   uint64
+		minCount int
+		holders  []*biz.Holder
+	}{
+		{0, 0, []*biz.Holder{
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "bob", Count: 2, TokenIDs: []uint64{2, 4}},
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "alice", Count: 1, TokenIDs: []uint64{3}},
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "dave", Count: 1, TokenIDs: []uint64{1}},
+		}},
+		{100, 0, []*biz.Holder{
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "alice", Count: 1, TokenIDs: []uint64{1}},
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "bob", Count: 1, TokenIDs: []uint64{4}},
+		}},
+		{200, 0, []*biz.Holder{
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "alice", Count: 1, TokenIDs: []uint64{2}},
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "bob", Count: 1, TokenIDs: []uint64{4}},
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "carol", Count: 1, TokenIDs: []uint64{1}},
+		}},
+		{300, 2, []*biz.Holder{
+			{P: biz.ProtocolTypeBRC721, Tick: "ordinals", Address: "bob", Count: 2, TokenIDs: []uint64{2, 4}},
+		}},
+	} {
+		holders, err := uc.ListHolders(ctx, &biz.HolderListOption{Tick: "ordinals", Height: c.height, MinCount: c.minCount})
+		r.NoError(err)
+		r.Equal(c.holders, holders, c.height)
+	}
+
+	// the holders of all the collections.
+	holders, err := uc.ListHolders(ctx, &biz.HolderListOption{Height: 100})
+	r.NoError(err)
+	r.Len(holders, 4)
+	r.Equal("other", holders[2].Tick)
+
+	// the transfers are deleted with the token.
+	r.NoError(tokenRepo.Delete(ctx, token.ID))
+	count, err := d.db.Transfer.Query().Count(ctx)
+	r.NoError(err)
+	r.Equal(1, count)
+
+	// the transfers from the height on are rolled back to the owner before the height.
+	deleted, err := uc.DeleteTransfersFrom(ctx, 200)
+	r.NoError(err)
+	r.Len(deleted, 1)
+	r.Equal(uint64(2), deleted[0].TokenID)
+	r.Equal("alice", deleted[0].From)
+	token, err = tokenRepo.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 2)
+	r.NoError(err)
+	r.Equal("alice", token.Address)
+	count, err = d.db.Transfer.Query().Count(ctx)
+	r.NoError(err)
+	r.Equal(0, count)
+}
@@ -0,0 +1,250 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+// The sync modes of the syncer.
+const (
+	// SyncModeInscriptions walks the paginated inscriptions listing of ord.
+	SyncModeInscriptions = "inscriptions"
+	// SyncModeBlocks walks the blocks, the inscriptions of a block are processed
+	// as one unit.
+	SyncModeBlocks = "blocks"
+)
+
+// maxBlockCheckpoints is the number of the recent blocks checkpointed, a reorg
+// deeper than it can't be rolled back automatically.
+const maxBlockCheckpoints = 12
+
+// blockCheckpoint is a processed block.
+type blockCheckpoint struct {
+	Height uint64
+	// Hash is empty if the block is unknown, eg: after a rollback.
+	Hash string
+}
+
+// parseSyncMode checks the sync mode, the inscriptions mode by default.
+func parseSyncMode(mode string) (string, error) {
+	switch mode {
+	case "", SyncModeInscriptions:
+		return SyncModeInscriptions, nil
+	case SyncModeBlocks:
+		return SyncModeBlocks, nil
+	default:
+		return "", fmt.Errorf("unknown sync mode %q, expected %s or %s", mode, SyncModeInscriptions, SyncModeBlocks)
+	}
+}
+
+// syncBlocks processes the blocks from the checkpoint to the latest block of ord,
+// the checkpoint is moved after each block.
+func (s *Syncer) syncBlocks(ctx context.Context) error {
+	checkpoints, err := s.readBlockCheckpoints(ctx)
+	if err != nil {
+		return err
+	}
+	checkpoints, err = s.checkReorg(ctx, checkpoints)
+	if err != nil {
+		return err
+	}
+	data, err := s.pageParser.Parse(ctx, page.NewBlockHeightPage())
+	if err != nil {
+		return err
+	}
+	tip, ok := data.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid block height: %T", data)
+	}
+	height := s.c.Server.HeightStart
+	if len(checkpoints) > 0 {
+		height = checkpoints[len(checkpoints)-1].Height + 1
+	}
+	// the transfers are indexed from the first block synced in the blocks mode.
+	_, indexed, err := s.readTransfersHeight(ctx)
+	if err != nil {
+		return err
+	}
+	if !indexed && height <= tip {
+		if err := s.saveTransfersHeight(ctx, height); err != nil {
+			return err
+		}
+	}
+	// the blocks are not started after ctx is done, the block in flight is drained.
+	for ; height <= tip && ctx.Err() == nil; height++ {
+		// the records of the block and its checkpoint are committed together in a
+		// transaction, a block failed is synced again from scratch.
+		var processed []blockCheckpoint
+		err := s.data.InTx(ctx, func(ctx context.Context) error {
+			block, err := s.processBlock(ctx, height)
+			if err != nil {
+				return err
+			}
+			if err := s.fence(ctx); err != nil {
+				return err
+			}
+			processed = appendBlockCheckpoint(checkpoints, block)
+			return s.saveBlockCheckpoints(ctx, processed)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to process block %d: %w", height, err)
+		}
+		checkpoints = processed
+	}
+	return nil
+}
+
+// appendBlockCheckpoint returns the checkpoints with the block, the oldest ones are
+// dropped beyond maxBlockCheckpoints.
+func appendBlockCheckpoint(checkpoints []blockCheckpoint, block *page.Block) []blockCheckpoint {
+	ret := append(append(make([]blockCheckpoint, 0, len(checkpoints)+1), checkpoints...), blockCheckpoint{Height: block.Height, Hash: block.Hash})
+	if len(ret) > maxBlockCheckpoints {
+		ret = ret[len(ret)-maxBlockCheckpoints:]
+	}
+	return ret
+}
+
+// processBlock processes all the inscriptions of the block, then the transfers of the
+// token inscriptions by its txs.
+func (s *Syncer) processBlock(ctx context.Context, height uint64) (*page.Block, error) {
+	blockPage := page.NewBlockPage(height)
+	s.logger.Infof("parsing block page %s", blockPage.URL())
+	data, err := s.pageParser.Parse(ctx, blockPage)
+	if err != nil {
+		return nil, err
+	}
+	block, ok := data.(*page.Block)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type: %T for URL %s", data, blockPage.URL())
+	}
+	s.cacheBlock(block)
+
+	insUids := make(uids, 0)
+	for p := uint64(0); ; {
+		inscriptionsPage := page.NewBlockInscriptionsPage(height, p)
+		data, err := s.pageParser.Parse(ctx, inscriptionsPage)
+		if err != nil {
+			return nil, err
+		}
+		inscriptions, ok := data.(*page.BlockInscriptions)
+		if !ok {
+			return nil, fmt.Errorf("invalid data type: %T for URL %s", data, inscriptionsPage.URL())
+		}
+		insUids = append(insUids, inscriptions.UIDs...)
+		if inscriptions.NextPage == nil {
+			break
+		}
+		p = *inscriptions.NextPage
+	}
+	// the inscriptions of the blocks are not skipped by their numbers.
+	if err := s.processBatch(ctx, insUids, math.MinInt64); err != nil {
+		return nil, err
+	}
+	// the inscriptions minted by the block may be moved by the txs after them.
+	if err := s.processTransfers(ctx, block); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("processed block %d %s with %d inscriptions", block.Height, block.Hash, len(insUids))
+	return block, nil
+}
+
+// checkReorg compares the checkpoints with the blocks of ord from the latest one,
+// and rolls back the blocks after the fork. It returns the checkpoints kept.
+func (s *Syncer) checkReorg(ctx context.Context, checkpoints []blockCheckpoint) ([]blockCheckpoint, error) {
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		checkpoint := checkpoints[i]
+		if checkpoint.Hash != "" {
+			data, err := s.pageParser.Parse(ctx, page.NewBlockPage(checkpoint.Height))
+			if err != nil {
+				return nil, err
+			}
+			block, ok := data.(*page.Block)
+			if !ok {
+				return nil, fmt.Errorf("invalid block page: %T", data)
+			}
+			if block.Hash != checkpoint.Hash {
+				s.logger.Warnf("block %d %s is replaced by %s", checkpoint.Height, checkpoint.Hash, block.Hash)
+				continue
+			}
+		}
+		if i == len(checkpoints)-1 {
+			return checkpoints, nil
+		}
+		s.logger.Warnf("reorg detected, rolling back from block %d", checkpoint.Height+1)
+		if err := s.fence(ctx); err != nil {
+			return nil, err
+		}
+		if err := s.RollbackBlocks(ctx, checkpoint.Height+1); err != nil {
+			return nil, err
+		}
+		return checkpoints[:i+1], nil
+	}
+	if len(checkpoints) == 0 {
+		return checkpoints, nil
+	}
+	return nil, fmt.Errorf("reorg is deeper than the checkpoint of block %d", checkpoints[0].Height)
+}
+
+// RollbackBlocks reverts the state of the blocks from the height on, inclusive, and
+// rewinds the block checkpoint to the previous block. The inscriptions are rolled back
+// by their genesis heights, as the cursed ones are numbered out of the block order.
+func (s *Syncer) RollbackBlocks(ctx context.Context, height uint64) error {
+	// the state of the blocks is rolled back together in a transaction.
+	return s.data.InTx(biz.NewNoCacheContext(ctx), func(ctx context.Context) error {
+		if err := s.rollbackTransfers(ctx, height); err != nil {
+			return err
+		}
+		blessed := false
+		inscriptions, err := s.inscriptionUc.ListInscriptions(ctx, &biz.InscriptionListOption{
+			GenesisHeightFrom: height,
+			Cursed:            &blessed,
+			Limit:             1,
+		})
+		if err != nil {
+			return err
+		}
+		for i := len(s.handlers) - 1; i >= 0; i-- {
+			handler := s.handlers[i]
+			if err := handler.RollbackBlocks(ctx, height); err != nil {
+				return fmt.Errorf("failed to rollback protocol %s: %w", handler.Name(), err)
+			}
+			s.logger.Infof("rolled back protocol %s from block %d", handler.Name(), height)
+		}
+		count, err := s.inscriptionUc.DeleteInscriptionsFromHeight(ctx, height)
+		if err != nil {
+			return fmt.Errorf("failed to delete inscriptions: %w", err)
+		}
+		s.logger.Infof("deleted %d inscriptions from block %d", count, height)
+		// the first blessed inscription from the height on is the oldest one.
+		if len(inscriptions) > 0 && inscriptions[0].GenesisHeight >= height {
+			if err := s.rewindLastInscriptionId(ctx, inscriptions[0].InscriptionID); err != nil {
+				return err
+			}
+		}
+		return s.rewindBlockCheckpoints(ctx, height)
+	})
+}
+
+// rewindBlockCheckpoints drops the checkpoints from the height on, it is a no-op
+// without the block checkpoints, eg: in the inscriptions mode.
+func (s *Syncer) rewindBlockCheckpoints(ctx context.Context, height uint64) error {
+	s.resetBlocks()
+	checkpoints, err := s.readBlockCheckpoints(ctx)
+	if err != nil || checkpoints == nil {
+		return err
+	}
+	kept := make([]blockCheckpoint, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Height < height {
+			kept = append(kept, checkpoint)
+		}
+	}
+	if len(kept) == 0 && height > 0 {
+		kept = append(kept, blockCheckpoint{Height: height - 1})
+	}
+	return s.saveBlockCheckpoints(ctx, kept)
+}
@@ -0,0 +1,357 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+// fakePageParser serves the parsed pages by URL, and records the URLs parsed.
+type fakePageParser struct {
+	sync.Mutex
+	pages  map[string]interface{}
+	parsed []string
+}
+
+func (p *fakePageParser) Parse(ctx context.Context, pg page.Page) (interface{}, error) {
+	p.Lock()
+	defer p.Unlock()
+	p.parsed = append(p.parsed, pg.URL())
+	data, ok := p.pages[pg.URL()]
+	if !ok {
+		return nil, fmt.Errorf("page %s not found", pg.URL())
+	}
+	// the inscriptions are filled with their content by the workers.
+	if inscription, ok := data.(*page.Inscription); ok {
+		copied := *inscription
+		return &copied, nil
+	}
+	return data, nil
+}
+
+func (p *fakePageParser) set(url string, data interface{}) {
+	p.Lock()
+	defer p.Unlock()
+	p.pages[url] = data
+}
+
+// parsedURLs returns the URLs parsed so far.
+func (p *fakePageParser) parsedURLs() []string {
+	p.Lock()
+	defer p.Unlock()
+	return append([]string(nil), p.parsed...)
+}
+
+func (s *brc721SigTestSuite) TestSyncBlocks() {
+	r := s.Require()
+	s.c.Server.HeightStart = 788903
+	defer func() { s.c.Server.HeightStart = 0 }()
+
+	deployInfo, mintInfo := *s.deployInfo, *s.mintInfo
+	deployInfo.Content, mintInfo.Content = nil, nil
+	// the inscriptions of a block are listed in pages.
+	nextPage := uint64(1)
+	parser := &fakePageParser{pages: map[string]interface{}{
+		"/blockheight":                   uint64(788905),
+		"/block/788903":                  &page.Block{Height: 788903, Hash: "hash788903"},
+		"/inscriptions/block/788903/0":   &page.BlockInscriptions{Height: 788903},
+		"/block/788904":                  &page.Block{Height: 788904, Hash: "hash788904", TxIDs: []string{s.deployInfo.GenesisTx, s.mintInfo.GenesisTx}},
+		"/inscriptions/block/788904/0":   &page.BlockInscriptions{Height: 788904, UIDs: []string{s.mintInfo.UID}, NextPage: &nextPage},
+		"/inscriptions/block/788904/1":   &page.BlockInscriptions{Height: 788904, UIDs: []string{s.deployInfo.UID}},
+		"/block/788905":                  &page.Block{Height: 788905, Hash: "hash788905"},
+		"/inscriptions/block/788905/0":   &page.BlockInscriptions{Height: 788905},
+		"/inscription/" + deployInfo.UID: &deployInfo,
+		"/inscription/" + mintInfo.UID:   &mintInfo,
+		"/content/" + deployInfo.UID:     s.deployInfo.Content,
+		"/content/" + mintInfo.UID:       s.mintInfo.Content,
+		"/tx/" + mintInfo.GenesisTx:      &page.Tx{TxID: mintInfo.GenesisTx},
+	}}
+	s.syncer.pageParser = parser
+
+	ctx := context.Background()
+	r.NoError(s.syncer.syncBlocks(ctx))
+	collection, err := s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.NotNil(collection)
+	r.Equal(uint64(1), collection.Supply)
+	ins, err := s.inscriptionUc.FindByUID(ctx, s.mintInfo.UID)
+	r.NoError(err)
+	r.Equal(uint64(1), ins.TxIndex)
+	b, err := s.checkpointUc.GetCheckpoint(s.syncer.checkpointContext(ctx), lastBlockCheckpoint)
+	r.NoError(err)
+	r.Equal("788903 hash788903\n788904 hash788904\n788905 hash788905\n", b)
+
+	// the blocks are synced from the checkpoint on.
+	parser.set("/blockheight", uint64(788906))
+	parser.set("/block/788906", &page.Block{Height: 788906, Hash: "hash788906"})
+	parser.set("/inscriptions/block/788906/0", &page.BlockInscriptions{Height: 788906})
+	r.NoError(s.syncer.syncBlocks(ctx))
+	b, err = s.checkpointUc.GetCheckpoint(s.syncer.checkpointContext(ctx), lastBlockCheckpoint)
+	r.NoError(err)
+	r.Equal("788903 hash788903\n788904 hash788904\n788905 hash788905\n788906 hash788906\n", b)
+
+	// the blocks after the fork are rolled back and synced again on a reorg.
+	for height := 788904; height <= 788906; height++ {
+		parser.set(fmt.Sprintf("/block/%d", height), &page.Block{Height: uint64(height), Hash: fmt.Sprintf("fork%d", height)})
+		parser.set(fmt.Sprintf("/inscriptions/block/%d/0", height), &page.BlockInscriptions{Height: uint64(height)})
+	}
+	r.NoError(s.syncer.syncBlocks(ctx))
+	collection, err = s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Nil(collection)
+	count, err := s.inscriptionUc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(0, count)
+	b, err = s.checkpointUc.GetCheckpoint(s.syncer.checkpointContext(ctx), lastBlockCheckpoint)
+	r.NoError(err)
+	r.Equal("788903 hash788903\n788904 fork788904\n788905 fork788905\n788906 fork788906\n", b)
+
+	// the reorgs deeper than the checkpoints are not rolled back.
+	parser.set("/block/788903", &page.Block{Height: 788903, Hash: "fork788903"})
+	for height := 788904; height <= 788906; height++ {
+		parser.set(fmt.Sprintf("/block/%d", height), &page.Block{Height: uint64(height), Hash: fmt.Sprintf("again%d", height)})
+	}
+	r.ErrorContains(s.syncer.syncBlocks(ctx), "deeper")
+}
+
+func (s *brc721SigTestSuite) TestSyncBlocksInTx() {
+	r := s.Require()
+	s.c.Server.HeightStart = 788904
+	defer func() { s.c.Server.HeightStart = 0 }()
+
+	deployInfo, mintInfo := *s.deployInfo, *s.mintInfo
+	deployInfo.Content, mintInfo.Content = nil, nil
+	parser := &fakePageParser{pages: map[string]interface{}{
+		"/blockheight":                   uint64(788904),
+		"/block/788904":                  &page.Block{Height: 788904, Hash: "hash788904", TxIDs: []string{s.deployInfo.GenesisTx, s.mintInfo.GenesisTx}},
+		"/inscriptions/block/788904/0":   &page.BlockInscriptions{Height: 788904, UIDs: []string{s.mintInfo.UID, s.deployInfo.UID}},
+		"/inscription/" + deployInfo.UID: &deployInfo,
+		"/inscription/" + mintInfo.UID:   &mintInfo,
+		"/content/" + deployInfo.UID:     s.deployInfo.Content,
+		"/content/" + mintInfo.UID:       s.mintInfo.Content,
+	}}
+	s.syncer.pageParser = parser
+
+	// the block failing on its transfers commits none of its inscriptions.
+	ctx := context.Background()
+	r.ErrorContains(s.syncer.syncBlocks(ctx), "page /tx/"+mintInfo.GenesisTx+" not found")
+	count, err := s.inscriptionUc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(0, count)
+	collection, err := s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Nil(collection)
+	b, err := s.checkpointUc.GetCheckpoint(s.syncer.checkpointContext(ctx), lastBlockCheckpoint)
+	r.NoError(err)
+	r.Equal("", b)
+
+	// the block is synced again from scratch.
+	parser.set("/tx/"+mintInfo.GenesisTx, &page.Tx{TxID: mintInfo.GenesisTx})
+	r.NoError(s.syncer.syncBlocks(ctx))
+	count, err = s.inscriptionUc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(2, count)
+	collection, err = s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(1), collection.Supply)
+	b, err = s.checkpointUc.GetCheckpoint(s.syncer.checkpointContext(ctx), lastBlockCheckpoint)
+	r.NoError(err)
+	r.Equal("788904 hash788904\n", b)
+}
+
+func (s *brc721SigTestSuite) TestSyncBlocksRollbackCursed() {
+	r := s.Require()
+	s.c.Server.HeightStart = 788904
+	s.c.Brc721 = &conf.Ord_Brc721{Cursed: true}
+	defer func() {
+		s.c.Server.HeightStart = 0
+		s.c.Brc721 = nil
+	}()
+
+	deployInfo, mintInfo, cursedMint := *s.deployInfo, *s.mintInfo, *s.mintInfo
+	deployInfo.Content, mintInfo.Content, cursedMint.Content = nil, nil, nil
+	// the cursed mint is numbered before the blessed ones, but inscribed in the next block.
+	cursedMint.ID = -5
+	cursedMint.UID = "e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0"
+	cursedMint.GenesisTx = "e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562"
+	cursedMint.GenesisHeight = 788905
+	cursedMint.Cursed = true
+	parser := &fakePageParser{pages: map[string]interface{}{
+		"/blockheight":                   uint64(788905),
+		"/block/788904":                  &page.Block{Height: 788904, Hash: "hash788904", TxIDs: []string{s.deployInfo.GenesisTx, s.mintInfo.GenesisTx}},
+		"/inscriptions/block/788904/0":   &page.BlockInscriptions{Height: 788904, UIDs: []string{deployInfo.UID, mintInfo.UID}},
+		"/block/788905":                  &page.Block{Height: 788905, Hash: "hash788905", TxIDs: []string{cursedMint.GenesisTx}},
+		"/inscriptions/block/788905/0":   &page.BlockInscriptions{Height: 788905, UIDs: []string{cursedMint.UID}},
+		"/inscription/" + deployInfo.UID: &deployInfo,
+		"/inscription/" + mintInfo.UID:   &mintInfo,
+		"/inscription/" + cursedMint.UID: &cursedMint,
+		"/content/" + deployInfo.UID:     s.deployInfo.Content,
+		"/content/" + mintInfo.UID:       s.mintInfo.Content,
+		"/content/" + cursedMint.UID:     s.mintInfo.Content,
+		"/tx/" + mintInfo.GenesisTx:      &page.Tx{TxID: mintInfo.GenesisTx},
+	}}
+	s.syncer.pageParser = parser
+
+	ctx := context.Background()
+	r.NoError(s.syncer.syncBlocks(ctx))
+	collection, err := s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(2), collection.Supply)
+
+	// the cursed mint of the orphaned block is rolled back with the block.
+	parser.set("/block/788905", &page.Block{Height: 788905, Hash: "fork788905"})
+	parser.set("/inscriptions/block/788905/0", &page.BlockInscriptions{Height: 788905})
+	r.NoError(s.syncer.syncBlocks(ctx))
+	ins, err := s.inscriptionUc.FindByUID(ctx, cursedMint.UID)
+	r.NoError(err)
+	r.Nil(ins)
+	ins, err = s.inscriptionUc.FindByUID(ctx, mintInfo.UID)
+	r.NoError(err)
+	r.NotNil(ins)
+	tokens, err := s.tokenUc.ListTokens(ctx, &biz.TokenListOption{P: biz.ProtocolTypeBRC721, Tick: "ordinals"})
+	r.NoError(err)
+	r.Len(tokens, 1)
+	r.Equal(mintInfo.ID, tokens[0].InscriptionID)
+	collection, err = s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(1), collection.Supply)
+}
+
+func (s *brc721SigTestSuite) TestSyncBlocksTransfers() {
+	r := s.Require()
+	s.c.Server.HeightStart = 788904
+	defer func() { s.c.Server.HeightStart = 0 }()
+	s.syncer.syncMode = SyncModeBlocks
+	defer func() { s.syncer.syncMode = SyncModeInscriptions }()
+
+	const (
+		coinbaseTx = "0000000000000000000000000000000000000000000000000000000000000001"
+		transferTx = "0000000000000000000000000000000000000000000000000000000000000002"
+		minter     = "bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf"
+		receiver   = "bc1p5d7rjq7g6rdk2yhzks9smlaqtedr4dekq08ge8ztwac72sfr9rusxg3297"
+	)
+	deployInfo, mintInfo := *s.deployInfo, *s.mintInfo
+	deployInfo.Content, mintInfo.Content = nil, nil
+	// the first inscription of the genesis tx.
+	mintInfo.UID = mintInfo.GenesisTx + "i0"
+	// ord shows the latest location of the token inscription, after the transfer.
+	mintInfo.Address = receiver
+	mintInfo.Location = transferTx + ":1:54"
+	mintInfo.Output = transferTx + ":1"
+	mintInfo.Offset = 54
+	mintOutput := mintInfo.GenesisTx + ":0"
+	parser := &fakePageParser{pages: map[string]interface{}{
+		"/blockheight":                   uint64(788905),
+		"/block/788904":                  &page.Block{Height: 788904, Hash: "hash788904", TxIDs: []string{coinbaseTx, s.deployInfo.GenesisTx, s.mintInfo.GenesisTx}},
+		"/inscriptions/block/788904/0":   &page.BlockInscriptions{Height: 788904, UIDs: []string{mintInfo.UID, s.deployInfo.UID}},
+		"/inscription/" + deployInfo.UID: &deployInfo,
+		"/inscription/" + mintInfo.UID:   &mintInfo,
+		"/content/" + deployInfo.UID:     s.deployInfo.Content,
+		"/content/" + mintInfo.UID:       s.mintInfo.Content,
+		"/tx/" + s.deployInfo.GenesisTx:  &page.Tx{TxID: s.deployInfo.GenesisTx},
+		"/tx/" + s.mintInfo.GenesisTx: &page.Tx{
+			TxID:    s.mintInfo.GenesisTx,
+			Outputs: []*page.TxOutput{{Outpoint: mintOutput, Value: 10000, Address: minter}},
+		},
+		"/block/788905": &page.Block{
+			Height:    788905,
+			Hash:      "hash788905",
+			Timestamp: time.Date(2023, 5, 7, 12, 14, 37, 0, time.UTC),
+			TxIDs:     []string{coinbaseTx, transferTx},
+		},
+		"/inscriptions/block/788905/0": &page.BlockInscriptions{Height: 788905},
+		// the inscription on the 2nd input is moved to the 54th sat of the 2nd output.
+		"/tx/" + transferTx: &page.Tx{
+			TxID:   transferTx,
+			Inputs: []string{coinbaseTx + ":0", mintOutput},
+			Outputs: []*page.TxOutput{
+				{Outpoint: transferTx + ":0", Value: 546, Address: minter},
+				{Outpoint: transferTx + ":1", Value: 10000, Address: receiver},
+			},
+		},
+		"/output/" + coinbaseTx + ":0": &page.TxOutput{Outpoint: coinbaseTx + ":0", Value: 600},
+	}}
+	s.syncer.pageParser = parser
+
+	ctx := context.Background()
+	r.NoError(s.syncer.syncBlocks(ctx))
+	ins, err := s.inscriptionUc.FindByUID(ctx, mintInfo.UID)
+	r.NoError(err)
+	r.Equal(receiver, ins.Address)
+	r.Equal(transferTx+":1:54", ins.Location)
+	r.Equal(transferTx+":1", ins.Output)
+	r.Equal(uint64(54), ins.Offset)
+	r.Equal(uint64(10000), ins.OutputValue)
+	holders := func(height uint64) []string {
+		holders, err := s.syncer.ListHolders(ctx, &biz.HolderListOption{Tick: "ordinals", Height: height})
+		r.NoError(err)
+		addresses := make([]string, 0, len(holders))
+		for _, h := range holders {
+			addresses = append(addresses, h.Address)
+		}
+		return addresses
+	}
+	// the token is minted to the owner of the genesis output, and transferred after.
+	_, err = s.syncer.ListHolders(ctx, &biz.HolderListOption{Tick: "ordinals", Height: 788903})
+	r.ErrorIs(err, ErrTransfersNotIndexed)
+	r.Equal([]string{minter}, holders(788904))
+	r.Equal([]string{receiver}, holders(788905))
+	r.Equal([]string{receiver}, holders(0))
+
+	// the transfers of the block are rolled back with the inscription location.
+	r.NoError(s.syncer.RollbackBlocks(ctx, 788905))
+	r.Equal([]string{minter}, holders(0))
+	ins, err = s.inscriptionUc.FindByUID(ctx, mintInfo.UID)
+	r.NoError(err)
+	r.Equal(minter, ins.Address)
+	r.Equal(mintOutput+":0", ins.Location)
+	r.Equal(mintOutput, ins.Output)
+	r.Equal(uint64(0), ins.Offset)
+
+	// the block is synced again.
+	r.NoError(s.syncer.syncBlocks(ctx))
+	r.Equal([]string{receiver}, holders(0))
+	r.Equal([]string{minter}, holders(788904))
+}
+
+func (s *brc721SigTestSuite) TestResolveGenesisLocation() {
+	r := s.Require()
+	const (
+		genesisTx = "0000000000000000000000000000000000000000000000000000000000000003"
+		laterTx   = "0000000000000000000000000000000000000000000000000000000000000004"
+		minter    = "bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf"
+	)
+	s.syncer.pageParser = &fakePageParser{pages: map[string]interface{}{
+		"/tx/" + genesisTx: &page.Tx{
+			TxID: genesisTx,
+			Outputs: []*page.TxOutput{
+				{Outpoint: genesisTx + ":0", Value: 0},
+				{Outpoint: genesisTx + ":1", Value: 546, Address: minter},
+			},
+		},
+	}}
+	ctx := context.Background()
+
+	// the first inscription is on the first sat of the inputs, after the outputs without sats.
+	info := &page.Inscription{ID: 1, UID: genesisTx + "i0", GenesisTx: genesisTx, Location: laterTx + ":0:0", Output: laterTx + ":0"}
+	r.NoError(s.syncer.resolveGenesisLocation(ctx, info))
+	r.Equal(minter, info.Address)
+	r.Equal(genesisTx+":1:0", info.Location)
+	r.Equal(genesisTx+":1", info.Output)
+	r.Equal(uint64(546), info.OutputValue)
+
+	// the location shown by ord is the genesis satpoint while it is in the genesis tx.
+	info = &page.Inscription{ID: 2, UID: genesisTx + "i1", GenesisTx: genesisTx, Location: genesisTx + ":1:100", Output: genesisTx + ":1", Offset: 100}
+	r.NoError(s.syncer.resolveGenesisLocation(ctx, info))
+	r.Equal(genesisTx+":1:100", info.Location)
+
+	// the other inscriptions of the tx are followed from the location shown by ord.
+	info = &page.Inscription{ID: 3, UID: genesisTx + "i2", GenesisTx: genesisTx, Location: laterTx + ":0:0", Output: laterTx + ":0"}
+	r.NoError(s.syncer.resolveGenesisLocation(ctx, info))
+	r.Equal(laterTx+":0:0", info.Location)
+}
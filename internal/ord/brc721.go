@@ -0,0 +1,536 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+	"github.com/adshao/ordinals-indexer/internal/ord/parser"
+
+	"github.com/adshao/go-brc721/sig"
+)
+
+func init() {
+	RegisterProtocol(parser.BRC721, newBRC721Handler)
+}
+
+var (
+	_ ProtocolHandler  = (*brc721Handler)(nil)
+	_ ReprocessChecker = (*brc721Handler)(nil)
+)
+
+// brc721Handler indexes the BRC-721 collections and tokens.
+type brc721Handler struct {
+	*HandlerContext
+	parsers []parser.Parser
+}
+
+func newBRC721Handler(hc *HandlerContext) ProtocolHandler {
+	parsers := parser.BRC721Parsers()
+	if hc.Conf.Parser != nil && hc.Conf.Parser.Strict {
+		parsers = parser.BRC721StrictParsers()
+	}
+	return &brc721Handler{HandlerContext: hc, parsers: parsers}
+}
+
+func (h *brc721Handler) Name() string {
+	return parser.BRC721
+}
+
+func (h *brc721Handler) Parsers() []parser.Parser {
+	return h.parsers
+}
+
+func (h *brc721Handler) Process(ctx context.Context, info *page.Inscription) error {
+	if info.Cursed && !h.Conf.GetBrc721().GetCursed() {
+		h.Logger.Infof("cursed inscription %d does not count for brc-721, ignore", info.ID)
+		return nil
+	}
+	switch info.Content.Type {
+	case parser.NameBRC721Deploy:
+		return h.processDeploy(ctx, info)
+	case parser.NameBRC721Mint:
+		return h.processMint(ctx, info)
+	case parser.NameBRC721Update:
+		// TODO: enable this after we have a way to identify the owner of the collection
+		// return h.processUpdate(ctx, info)
+	}
+	return nil
+}
+
+// Rollback deletes the tokens and collections from the inscription on.
+func (h *brc721Handler) Rollback(ctx context.Context, inscriptionID int64) error {
+	return h.rollback(ctx,
+		biz.TokenListOption{InscriptionIDFrom: inscriptionID},
+		biz.CollectionListOption{InscriptionIDFrom: inscriptionID},
+	)
+}
+
+// RollbackBlocks deletes the tokens and collections inscribed from the block height on,
+// the cursed ones included.
+func (h *brc721Handler) RollbackBlocks(ctx context.Context, height uint64) error {
+	return h.rollback(ctx,
+		biz.TokenListOption{BlockHeightFrom: height},
+		biz.CollectionListOption{BlockHeightFrom: height},
+	)
+}
+
+// rollback deletes the tokens and collections of the options. The supply of the
+// collections left is recomputed from their tokens left, as the token ids follow
+// the order the mints are indexed in, not the inscription order, see CheckReprocess.
+func (h *brc721Handler) rollback(ctx context.Context, tokenOpt biz.TokenListOption, collectionOpt biz.CollectionListOption) error {
+	tokenOpt.P = biz.ProtocolTypeBRC721
+	tokenOpt.Order = "-inscription_id"
+	ticks := make(map[string]bool)
+	for {
+		tokens, err := h.TokenUc.ListTokens(ctx, &tokenOpt)
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		for _, token := range tokens {
+			if err := h.TokenUc.DeleteToken(ctx, token.ID); err != nil {
+				return err
+			}
+			ticks[token.Tick] = true
+			h.Logger.Infof("rolled back token %d of collection %s minted by inscription %d", token.TokenID, token.Tick, token.InscriptionID)
+		}
+	}
+	collectionOpt.P = biz.ProtocolTypeBRC721
+	for {
+		collections, err := h.CollectionUc.ListCollections(ctx, &collectionOpt)
+		if err != nil {
+			return err
+		}
+		if len(collections) == 0 {
+			break
+		}
+		for _, collection := range collections {
+			if err := h.CollectionUc.DeleteCollection(ctx, collection.ID); err != nil {
+				return err
+			}
+			h.Logger.Infof("rolled back collection %s deployed by inscription %d", collection.Tick, collection.InscriptionID)
+		}
+	}
+	for tick := range ticks {
+		collection, err := h.CollectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, tick)
+		if err != nil {
+			return err
+		}
+		if collection == nil {
+			continue
+		}
+		count, err := h.TokenUc.CountTokens(ctx, &biz.TokenListOption{P: biz.ProtocolTypeBRC721, Tick: tick})
+		if err != nil {
+			return err
+		}
+		collection.Supply = uint64(count)
+		if _, err := h.CollectionUc.UpdateCollection(ctx, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckReprocess skips the inscriptions that are older than the latest indexed one of their collection.
+// The token ids are assigned in the order the mints are indexed in, so a cursed mint indexed
+// after a later blessed one has a greater token id, the latest mint is found by the positions
+// of the tokens instead.
+func (h *brc721Handler) CheckReprocess(ctx context.Context, info *page.Inscription) (string, error) {
+	switch info.Content.Type {
+	case parser.NameBRC721Deploy:
+		o := info.Content.Data.(*parser.BRC721Deploy)
+		collection, err := h.CollectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, o.Tick)
+		if err != nil {
+			return "", err
+		}
+		if collection == nil {
+			return "", nil
+		}
+		before, err := h.isBefore(ctx, info, collection.InscriptionUID, collection.InscriptionID, collection.BlockHeight)
+		if err != nil {
+			return "", err
+		}
+		if !before {
+			return fmt.Sprintf("collection %s was deployed by later inscription %d", o.Tick, collection.InscriptionID), nil
+		}
+	case parser.NameBRC721Mint:
+		o := info.Content.Data.(*parser.BRC721Mint)
+		// the tokens of the later blocks are later, whatever their token ids.
+		tokens, err := h.TokenUc.ListTokens(ctx, &biz.TokenListOption{
+			P:               biz.ProtocolTypeBRC721,
+			Tick:            o.Tick,
+			BlockHeightFrom: info.GenesisHeight + 1,
+			Order:           "token_id",
+			Limit:           1,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(tokens) > 0 {
+			return fmt.Sprintf("token %d of collection %s was minted by later inscription %d", tokens[0].TokenID, o.Tick, tokens[0].InscriptionID), nil
+		}
+		// the tokens of the same block are compared by their positions.
+		for offset := 0; ; offset += len(tokens) {
+			tokens, err = h.TokenUc.ListTokens(ctx, &biz.TokenListOption{
+				P:               biz.ProtocolTypeBRC721,
+				Tick:            o.Tick,
+				BlockHeightFrom: info.GenesisHeight,
+				Order:           "token_id",
+				Offset:          offset,
+			})
+			if err != nil {
+				return "", err
+			}
+			if len(tokens) == 0 {
+				break
+			}
+			for _, token := range tokens {
+				before, err := h.isBefore(ctx, info, token.InscriptionUID, token.InscriptionID, token.BlockHeight)
+				if err != nil {
+					return "", err
+				}
+				if !before {
+					return fmt.Sprintf("token %d of collection %s was minted by later inscription %d", token.TokenID, o.Tick, token.InscriptionID), nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+func (h *brc721Handler) processDeploy(ctx context.Context, info *page.Inscription) error {
+	o := info.Content.Data.(*parser.BRC721Deploy)
+	// check if the collection already exists
+	collection, err := h.CollectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, o.Tick)
+	if err != nil {
+		return err
+	}
+	if collection != nil {
+		before, err := h.isBefore(ctx, info, collection.InscriptionUID, collection.InscriptionID, collection.BlockHeight)
+		if err != nil {
+			return err
+		}
+		if !before {
+			// TODO: need to check if the collection is valid
+			h.Logger.Warnf("collection %s already exists, but inscription %d is after %d, ignore inscription %d", o.Tick, collection.InscriptionID, info.ID, info.ID)
+		} else {
+			h.Logger.Infof("collection %s already exists, ignore inscription %d", o.Tick, info.ID)
+		}
+		return nil
+	}
+	// check deploy sig
+	if o.Sig != nil {
+		err = o.Sig.Validate()
+		if err != nil {
+			h.Logger.Warnf("invalid deploy sig for collection %s, ignore inscription %d", o.Tick, info.ID)
+			return nil
+		}
+	}
+	// create the collection
+	collection = &biz.Collection{
+		P:      biz.ProtocolTypeBRC721,
+		Tick:   o.Tick,
+		Supply: 0,
+	}
+	max, err := strconv.ParseUint(o.Max, 10, 64)
+	if err != nil {
+		h.Logger.Warnf("invalid max %s, ignore inscription %d", o.Max, info.ID)
+		return nil
+	}
+	collection.Max = max
+	if o.BaseURI != nil {
+		collection.BaseURI = *o.BaseURI
+	}
+	if o.Meta != nil {
+		collection.Name = o.Meta.Name
+		collection.Description = o.Meta.Description
+		collection.Image = o.Meta.Image
+		collection.Attributes = o.Meta.Attributes
+	}
+	collection.TxHash = info.GenesisTx
+	collection.BlockHeight = info.GenesisHeight
+	collection.BlockTime = info.Timestamp
+	collection.Address = info.Address
+	collection.InscriptionID = info.ID
+	collection.InscriptionUID = info.UID
+	if o.Sig != nil {
+		collection.Sig = *o.Sig
+	}
+	collection.ChildMints = o.Child
+	collection, err = h.CollectionUc.CreateCollection(ctx, collection)
+	if err != nil {
+		return err
+	}
+	h.Logger.Infof("created collection %s for inscription %d", o.Tick, info.ID)
+	return nil
+}
+
+func (h *brc721Handler) processMint(ctx context.Context, info *page.Inscription) error {
+	inscriptionId := info.ID
+	o := info.Content.Data.(*parser.BRC721Mint)
+	// check if the collection exists
+	collection, err := h.CollectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, o.Tick)
+	if err != nil {
+		return err
+	}
+	if collection == nil {
+		h.Logger.Infof("collection %s not found, ignore mint inscription %d", o.Tick, inscriptionId)
+		return nil
+	}
+	deployed, err := h.isBefore(ctx, info, collection.InscriptionUID, collection.InscriptionID, collection.BlockHeight)
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		h.Logger.Warnf("collection %s was deployed by inscription %d after %d, ignore mint inscription %d", o.Tick, collection.InscriptionID, inscriptionId, inscriptionId)
+		return nil
+	}
+	h.Logger.Debugf("collection: %+v", collection)
+	for _, verdict := range h.mintVerdicts(collection, info.Cursed, info.ParentUID) {
+		if !verdict.Valid {
+			h.Logger.Infof("%s, ignore mint inscription %d", verdict.Reason, inscriptionId)
+			return nil
+		}
+	}
+	t, err := h.TokenUc.FindByInscriptionID(ctx, inscriptionId)
+	if err != nil {
+		return err
+	}
+	if len(t) > 0 {
+		h.Logger.Infof("token with inscription %d already processed, ignore mint inscription", inscriptionId)
+		return nil
+	}
+	valid, mintSig, err := h.checkMintSig(ctx, info, collection, o)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return nil
+	}
+	// create token
+	token := &biz.Token{
+		Tick:           o.Tick,
+		P:              biz.ProtocolTypeBRC721,
+		TokenID:        collection.Supply + 1,
+		TxHash:         info.GenesisTx,
+		BlockHeight:    info.GenesisHeight,
+		BlockTime:      info.Timestamp,
+		Address:        info.Address,
+		InscriptionID:  inscriptionId,
+		InscriptionUID: info.UID,
+		CollectionID:   collection.ID,
+	}
+	if mintSig != nil {
+		token.Sig = *mintSig
+	}
+	token, err = h.TokenUc.CreateToken(ctx, token)
+	if err != nil {
+		h.Logger.Errorf("failed to create token: %T: %v", err, err)
+		return err
+	}
+	h.Logger.Infof("created token %d for inscription %d", token.TokenID, inscriptionId)
+	h.ingestTraits(ctx, collection, token)
+
+	collection.Supply++
+	collection, err = h.CollectionUc.UpdateCollection(ctx, collection)
+	if err != nil {
+		return err
+	}
+	h.Logger.Infof("updated collection %s supply to %d", o.Tick, collection.Supply)
+	return nil
+}
+
+// mintVerdicts checks the mint against the rules of its collection other than the
+// order and the sig: the cursed policy, the parent of the child mints and the supply.
+// They are shared by the syncer and VerifyMint, so that both agree on the mints.
+func (h *brc721Handler) mintVerdicts(collection *biz.Collection, cursed bool, parentUID string) []*biz.MintSigVerdict {
+	verdicts := make([]*biz.MintSigVerdict, 0, 3)
+	verdict := func(field, reason string, args ...interface{}) {
+		v := &biz.MintSigVerdict{Field: field, Valid: reason == ""}
+		if reason != "" {
+			v.Reason = fmt.Sprintf(reason, args...)
+		}
+		verdicts = append(verdicts, v)
+	}
+	if cursed && !h.Conf.GetBrc721().GetCursed() {
+		verdict(biz.MintFieldCursed, "cursed inscriptions do not count for brc-721")
+	} else {
+		verdict(biz.MintFieldCursed, "")
+	}
+	// the mints of the child mint collections are the children of the deploy inscription
+	if collection.ChildMints && parentUID != collection.InscriptionUID {
+		verdict(biz.MintFieldParent, "mint is not a child of collection %s", collection.Tick)
+	} else {
+		verdict(biz.MintFieldParent, "")
+	}
+	if collection.Supply >= collection.Max {
+		verdict(biz.MintFieldSupply, "collection %s supply is full", collection.Tick)
+	} else {
+		verdict(biz.MintFieldSupply, "")
+	}
+	return verdicts
+}
+
+// isBefore reports whether the indexed inscription of the uid is before the inscription
+// on the chain. The positions are compared if the inscription of the uid is indexed,
+// otherwise, eg: the collections imported from a snapshot, the heights and then the
+// numbers are compared.
+func (h *brc721Handler) isBefore(ctx context.Context, info *page.Inscription, uid string, inscriptionID int64, height uint64) (bool, error) {
+	if uid == info.UID {
+		return false, nil
+	}
+	if h.InscriptionUc != nil {
+		indexed, err := h.InscriptionUc.FindByUID(ctx, uid)
+		if err != nil {
+			return false, err
+		}
+		if indexed != nil {
+			pos, err := info.Position()
+			if err != nil {
+				return false, err
+			}
+			indexedPos, err := indexed.Position()
+			if err != nil {
+				return false, err
+			}
+			return indexedPos.Less(pos), nil
+		}
+	}
+	if height != info.GenesisHeight {
+		return height < info.GenesisHeight, nil
+	}
+	return inscriptionID < info.ID, nil
+}
+
+func (h *brc721Handler) checkMintSig(ctx context.Context, info *page.Inscription, collection *biz.Collection, o *parser.BRC721Mint) (bool, *sig.MintSig, error) {
+	report, err := h.TokenUc.VerifyMintSig(ctx, collection, &biz.MintSigCandidate{
+		Sig:         o.Sig,
+		Receiver:    info.Address,
+		BlockHeight: info.GenesisHeight,
+		BlockTime:   info.Timestamp,
+	})
+	if err != nil {
+		h.Logger.Errorf("failed to verify mint sig for collection %s, inscription %d: %v", o.Tick, info.ID, err)
+		return false, nil, err
+	}
+	if !report.Required {
+		return true, nil, nil
+	}
+	if !report.Valid {
+		for _, verdict := range report.Verdicts {
+			if !verdict.Valid {
+				h.Logger.Warnf("%s for collection %s, ignore mint inscription %d", verdict.Reason, o.Tick, info.ID)
+			}
+		}
+		return false, nil, nil
+	}
+	return true, report.Sig, nil
+}
+
+func (h *brc721Handler) processUpdate(ctx context.Context, info *page.Inscription) error {
+	inscriptionId := info.ID
+	o := info.Content.Data.(*parser.BRC721Update)
+	// check if the collection exists
+	collection, err := h.CollectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, o.Tick)
+	if err != nil {
+		return err
+	}
+	if collection == nil {
+		h.Logger.Infof("collection %s not found, ignore inscription %d", o.Tick, inscriptionId)
+		return nil
+	}
+	// update collection
+	if o.BaseURI != nil {
+		collection.BaseURI = *o.BaseURI
+	}
+	_, err = h.CollectionUc.UpdateCollection(ctx, collection)
+	if err != nil {
+		return err
+	}
+	h.Logger.Infof("updated collection %s", o.Tick)
+	return nil
+}
+
+// MintCandidate is a BRC-721 mint to verify without inscribing it. The Receiver,
+// BlockHeight, BlockTime, ParentUID and Cursed are the ones of the mint inscription.
+type MintCandidate struct {
+	Mint        *parser.BRC721Mint
+	Receiver    string
+	BlockHeight uint64
+	BlockTime   time.Time
+	ParentUID   string
+	Cursed      bool
+}
+
+// MintReport is the result of verifying a mint, nil if the collection is not found.
+type MintReport struct {
+	Collection *biz.Collection
+	// Eligible is false if the syncer would ignore the mint before checking its sig.
+	Eligible    bool
+	Eligibility []*biz.MintSigVerdict
+	Sig         *biz.MintSigReport
+}
+
+// ParseMint parses the BRC-721 mint content by the same parsers as the syncer, the
+// strict ones if ord.parser.strict is set. It returns nil if the content is not a valid mint.
+func (s *Syncer) ParseMint(content []byte) *parser.BRC721Mint {
+	h, ok := s.protocol(parser.BRC721).(*brc721Handler)
+	if !ok {
+		return nil
+	}
+	for _, p := range h.Parsers() {
+		if p.Name() != parser.NameBRC721Mint {
+			continue
+		}
+		data, valid, err := p.Parse(content)
+		if err != nil || !valid {
+			return nil
+		}
+		return data.(*parser.BRC721Mint)
+	}
+	return nil
+}
+
+// VerifyMint runs the same checks as the syncer on the mint against its deployed
+// collection, the eligibility of the mint and the verdicts of its sig.
+func (s *Syncer) VerifyMint(ctx context.Context, mint *MintCandidate) (*MintReport, error) {
+	h, ok := s.protocol(parser.BRC721).(*brc721Handler)
+	if !ok {
+		return nil, fmt.Errorf("protocol %s is not registered", parser.BRC721)
+	}
+	collection, err := h.CollectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, mint.Mint.Tick)
+	if err != nil {
+		return nil, err
+	}
+	if collection == nil {
+		return nil, nil
+	}
+	report := &MintReport{Collection: collection, Eligible: true}
+	// the order in the block of a mint to inscribe is unknown, only the height is checked.
+	deploy := &biz.MintSigVerdict{Field: biz.MintFieldDeploy, Valid: collection.BlockHeight <= mint.BlockHeight}
+	if !deploy.Valid {
+		deploy.Reason = fmt.Sprintf("collection %s is deployed at block %d after the mint", collection.Tick, collection.BlockHeight)
+	}
+	report.Eligibility = append([]*biz.MintSigVerdict{deploy}, h.mintVerdicts(collection, mint.Cursed, mint.ParentUID)...)
+	for _, verdict := range report.Eligibility {
+		if !verdict.Valid {
+			report.Eligible = false
+		}
+	}
+	report.Sig, err = h.TokenUc.VerifyMintSig(ctx, collection, &biz.MintSigCandidate{
+		Sig:         mint.Mint.Sig,
+		Receiver:    mint.Receiver,
+		BlockHeight: mint.BlockHeight,
+		BlockTime:   mint.BlockTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
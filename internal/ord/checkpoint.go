@@ -0,0 +1,126 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+// The names of the sync checkpoints in the database.
+const (
+	// lastInscriptionIdCheckpoint is the next inscription id to sync in the inscriptions mode.
+	lastInscriptionIdCheckpoint = "last_inscription_id"
+	// lastBlockCheckpoint is the recent blocks synced in the blocks mode.
+	lastBlockCheckpoint = "last_block"
+	// transfersHeightCheckpoint is the block height the transfers are indexed from in the blocks mode.
+	transfersHeightCheckpoint = "transfers_height"
+)
+
+// checkpointContext scopes the checkpoints by the network of the syncer.
+func (s *Syncer) checkpointContext(ctx context.Context) context.Context {
+	return biz.NewNetworkContext(ctx, s.network.Name)
+}
+
+// getLastInscriptionId returns the sync checkpoint, or the inscription id to start
+// from of the config without one.
+func (s *Syncer) getLastInscriptionId(ctx context.Context) (int64, error) {
+	lastInscriptionId, ok, err := s.readLastInscriptionId(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		s.logger.Infof("get lastInscriptionId from checkpoint: %d", lastInscriptionId)
+		return lastInscriptionId, nil
+	}
+	lastInscriptionId = s.c.Server.InscriptionIdStart
+	s.logger.Infof("get lastInscriptionId from config: %d", lastInscriptionId)
+	return lastInscriptionId, nil
+}
+
+// readLastInscriptionId reads the sync checkpoint, ok is false if there is none.
+func (s *Syncer) readLastInscriptionId(ctx context.Context) (int64, bool, error) {
+	value, err := s.checkpointUc.GetCheckpoint(s.checkpointContext(ctx), lastInscriptionIdCheckpoint)
+	if err != nil || value == "" {
+		return 0, false, err
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid checkpoint %s %q: %v", lastInscriptionIdCheckpoint, value, err)
+	}
+	return id, true, nil
+}
+
+// saveLastInscriptionId moves the sync checkpoint forward to the inscription.
+func (s *Syncer) saveLastInscriptionId(ctx context.Context, inscriptionId int64) error {
+	lastInscriptionId, ok, err := s.readLastInscriptionId(ctx)
+	if err != nil || (ok && lastInscriptionId >= inscriptionId) {
+		return err
+	}
+	return s.rewindLastInscriptionId(ctx, inscriptionId)
+}
+
+// rewindLastInscriptionId moves the sync checkpoint back to the inscription.
+func (s *Syncer) rewindLastInscriptionId(ctx context.Context, inscriptionId int64) error {
+	return s.checkpointUc.SaveCheckpoint(s.checkpointContext(ctx), lastInscriptionIdCheckpoint, strconv.FormatInt(inscriptionId, 10))
+}
+
+// readBlockCheckpoints reads the checkpoints in the ascending order of the heights,
+// nil if there is none.
+func (s *Syncer) readBlockCheckpoints(ctx context.Context) ([]blockCheckpoint, error) {
+	value, err := s.checkpointUc.GetCheckpoint(s.checkpointContext(ctx), lastBlockCheckpoint)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	checkpoints := make([]blockCheckpoint, 0)
+	for _, line := range strings.Split(strings.TrimSpace(value), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		height, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block checkpoint %q: %v", line, err)
+		}
+		checkpoint := blockCheckpoint{Height: height}
+		if len(fields) > 1 {
+			checkpoint.Hash = fields[1]
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, nil
+}
+
+// saveBlockCheckpoints saves the checkpoints one per line, eg: "788904 <hash>".
+func (s *Syncer) saveBlockCheckpoints(ctx context.Context, checkpoints []blockCheckpoint) error {
+	var b strings.Builder
+	for _, checkpoint := range checkpoints {
+		fmt.Fprintf(&b, "%d %s\n", checkpoint.Height, checkpoint.Hash)
+	}
+	return s.checkpointUc.SaveCheckpoint(s.checkpointContext(ctx), lastBlockCheckpoint, b.String())
+}
+
+// readTransfersHeight reads the block height the transfers are indexed from, ok is false
+// if they are not indexed, eg: in the inscriptions mode. The checkpoint is read in the
+// network of ctx, as the holders are served for the networks of the requests.
+func (s *Syncer) readTransfersHeight(ctx context.Context) (uint64, bool, error) {
+	if _, ok := biz.NetworkFromContext(ctx); !ok {
+		ctx = s.checkpointContext(ctx)
+	}
+	value, err := s.checkpointUc.GetCheckpoint(ctx, transfersHeightCheckpoint)
+	if err != nil || value == "" {
+		return 0, false, err
+	}
+	height, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid checkpoint %s %q: %v", transfersHeightCheckpoint, value, err)
+	}
+	return height, true, nil
+}
+
+// saveTransfersHeight saves the block height the transfers are indexed from.
+func (s *Syncer) saveTransfersHeight(ctx context.Context, height uint64) error {
+	return s.checkpointUc.SaveCheckpoint(s.checkpointContext(ctx), transfersHeightCheckpoint, strconv.FormatUint(height, 10))
+}
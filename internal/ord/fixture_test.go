@@ -0,0 +1,177 @@
+package ord
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/data"
+)
+
+// ordRecordEnv is the url of the ord server to record the missing pages from, eg:
+// ORD_RECORD=http://127.0.0.1:80 go test ./internal/ord -run TestSyncerFixture
+const ordRecordEnv = "ORD_RECORD"
+
+// newOrdFixtureServer serves the recorded ord pages of the dir by their paths, eg:
+// /inscription/<uid> is served from <dir>/inscription/<uid>. The missing pages are
+// fetched from the ord server of ORD_RECORD and recorded to the dir if it is set,
+// otherwise they are not found.
+func newOrdFixtureServer(t *testing.T, dir string) *httptest.Server {
+	record := os.Getenv(ordRecordEnv)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(r.URL.Path, "/")))
+		b, err := os.ReadFile(name)
+		if os.IsNotExist(err) && record != "" {
+			b, err = recordOrdPage(record, r.URL.Path, name)
+		}
+		if os.IsNotExist(err) {
+			t.Logf("ord page %s is not recorded", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(b)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// recordOrdPage fetches the page from the ord server, and saves it to the file.
+func recordOrdPage(ordURL, path, name string) ([]byte, error) {
+	u, err := url.JoinPath(ordURL, path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, os.ErrNotExist
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+	return b, os.WriteFile(name, b, 0644)
+}
+
+// TestSyncerFixture syncs the recorded ord corpus of testdata/ord into SQLite in both
+// sync modes. The corpus has 12 inscriptions #0-#11 in the blocks 800000-800002:
+//   - #1 deploys ordinals of max 3, #2, #4 and #7 mint it, #8 is over the max supply
+//     and #5 deploys it again
+//   - #9 deploys punks, and #10 mints it
+//   - #6 mints an unknown tick, #11 is an invalid mint, #3 is plain text and #0 is
+//     an image that is not fetched
+//   - the genesis tx of #11 transfers the ordinals token #1 minted by #2
+func TestSyncerFixture(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("testdata", "ord"))
+	require.NoError(t, err)
+	server := newOrdFixtureServer(t, dir)
+
+	for _, mode := range []string{SyncModeInscriptions, SyncModeBlocks} {
+		t.Run(mode, func(t *testing.T) {
+			r := require.New(t)
+			d, cleanup := data.NewTData(t)
+			defer cleanup()
+			logger := log.GetLogger()
+			collectionUc := biz.NewCollectionUsecase(data.NewCollectionRepo(d, logger), logger)
+			tokenRepo := data.NewTokenRepo(d, logger)
+			tokenUc := biz.NewTokenUsecase(tokenRepo, logger)
+			inscriptionUc := biz.NewInscriptionUsecase(data.NewInscriptionRepo(d, logger), logger)
+			c := &conf.Ord{
+				Server: &conf.Ord_Server{
+					Addr:        server.URL,
+					SyncMode:    mode,
+					HeightStart: 800000,
+				},
+				Worker: &conf.Ord_Worker{
+					Concurrency: 3,
+				},
+			}
+			syncer, _, err := NewSyncer(c, d, collectionUc, inscriptionUc, tokenUc,
+				biz.NewTraitUsecase(data.NewTraitRepo(d, logger), tokenRepo, logger),
+				biz.NewSnapshotUsecase(data.NewSnapshotRepo(d, logger), logger),
+				biz.NewCheckpointUsecase(data.NewCheckpointRepo(d, logger), logger), nil, logger)
+			r.NoError(err)
+			ctx := context.Background()
+			r.NoError(syncer.sync(ctx))
+
+			count, err := inscriptionUc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+			r.NoError(err)
+			r.Equal(12, count)
+			ins, err := inscriptionUc.FindByInscriptionID(ctx, 7)
+			r.NoError(err)
+			r.Equal(uint64(800001), ins.GenesisHeight)
+			r.Equal(uint64(4), ins.TxIndex)
+			r.Equal("bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", ins.Address)
+
+			collections, err := collectionUc.ListCollections(ctx, &biz.CollectionListOption{P: biz.ProtocolTypeBRC721})
+			r.NoError(err)
+			r.Len(collections, 2)
+			supplies := make(map[string]uint64)
+			deploys := make(map[string]int64)
+			for _, collection := range collections {
+				supplies[collection.Tick] = collection.Supply
+				deploys[collection.Tick] = collection.InscriptionID
+			}
+			r.Equal(map[string]uint64{"ordinals": 3, "punks": 1}, supplies)
+			r.Equal(map[string]int64{"ordinals": 1, "punks": 9}, deploys)
+			punks, err := collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "punks")
+			r.NoError(err)
+			r.Equal("Punks", punks.Name)
+			r.Equal(uint64(10), punks.Max)
+
+			for tick, expected := range map[string][]int64{"ordinals": {2, 4, 7}, "punks": {10}} {
+				tokens, err := tokenUc.ListTokens(ctx, &biz.TokenListOption{P: biz.ProtocolTypeBRC721, Tick: tick, Order: "token_id"})
+				r.NoError(err)
+				var minted []int64
+				for i, token := range tokens {
+					r.Equal(uint64(i+1), token.TokenID)
+					minted = append(minted, token.InscriptionID)
+				}
+				r.Equal(expected, minted, tick)
+			}
+
+			// the transfers are ingested in the blocks mode only.
+			token, err := tokenUc.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 1)
+			r.NoError(err)
+			holders, err := tokenUc.ListHolders(ctx, &biz.HolderListOption{Tick: "ordinals", Height: 800001})
+			r.NoError(err)
+			r.Equal("bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf", holders[0].Address)
+			r.Equal([]uint64{1, 2}, holders[0].TokenIDs)
+			if mode == SyncModeBlocks {
+				r.Equal("bc1qm34lsc65zpw79lxes69zkqmk6ee3ewf0j77s3h", token.Address)
+				ins, err := inscriptionUc.FindByInscriptionID(ctx, 2)
+				r.NoError(err)
+				r.Equal("306d1b40b08dc36c1adfb4b22004244cb1159c26929e208c34052a8d66d9c388:1:0", ins.Location)
+			} else {
+				r.Equal("bc1phwdjdq59tqlszsd4gljqqsgvrygpasre4dj4ant98wvc30lqgqzsxxgkvf", token.Address)
+			}
+
+			// the second sync is a no-op from the checkpoint.
+			r.NoError(syncer.sync(ctx))
+			count, err = tokenUc.CountTokens(ctx, &biz.TokenListOption{})
+			r.NoError(err)
+			r.Equal(4, count)
+		})
+	}
+}
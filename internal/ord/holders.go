@@ -0,0 +1,73 @@
+package ord
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+const (
+	HoldersFormatCSV  = "csv"
+	HoldersFormatJSON = "json"
+)
+
+// ErrTransfersNotIndexed is returned for the holders at a block height the transfers are
+// not indexed at, as the tokens would be listed by the owners they were indexed with.
+var ErrTransfersNotIndexed = errors.New("transfers are not indexed")
+
+// ListHolders lists the holders of the collections at the block height, the transfers
+// must be indexed at the height, ie: synced in the blocks mode from a height before.
+func (s *Syncer) ListHolders(ctx context.Context, opt *biz.HolderListOption) ([]*biz.Holder, error) {
+	height, indexed, err := s.readTransfersHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !indexed {
+		return nil, fmt.Errorf("%w, the holders are only served in the %s sync mode", ErrTransfersNotIndexed, SyncModeBlocks)
+	}
+	if opt.Height != 0 && opt.Height < height {
+		return nil, fmt.Errorf("%w before block %d", ErrTransfersNotIndexed, height)
+	}
+	return s.tokenUc.ListHolders(ctx, opt)
+}
+
+// ExportHolders writes the holders of the collections at the block height in CSV
+// or JSON, and returns the number of the holders. The token ids of a holder are
+// separated by spaces in CSV.
+func (s *Syncer) ExportHolders(ctx context.Context, w io.Writer, opt *biz.HolderListOption, format string) (int, error) {
+	if format != HoldersFormatCSV && format != HoldersFormatJSON {
+		return 0, fmt.Errorf("unknown holders format: %s", format)
+	}
+	holders, err := s.ListHolders(ctx, opt)
+	if err != nil {
+		return 0, err
+	}
+	if format == HoldersFormatJSON {
+		enc := jsoniter.ConfigCompatibleWithStandardLibrary.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return len(holders), enc.Encode(holders)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"p", "tick", "address", "count", "token_ids"}); err != nil {
+		return 0, err
+	}
+	for _, h := range holders {
+		tokenIDs := make([]string, 0, len(h.TokenIDs))
+		for _, id := range h.TokenIDs {
+			tokenIDs = append(tokenIDs, strconv.FormatUint(id, 10))
+		}
+		if err := cw.Write([]string{h.P, h.Tick, h.Address, strconv.Itoa(h.Count), strings.Join(tokenIDs, " ")}); err != nil {
+			return 0, err
+		}
+	}
+	cw.Flush()
+	return len(holders), cw.Error()
+}
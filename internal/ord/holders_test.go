@@ -0,0 +1,51 @@
+package ord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func (s *brc721SigTestSuite) TestExportHolders() {
+	s.createVerifyState()
+	r := s.Require()
+	ctx := context.Background()
+	_, err := s.tokenUc.TransferToken(ctx, &biz.Transfer{
+		P:           biz.ProtocolTypeBRC721,
+		Tick:        "ordinals",
+		TokenID:     2,
+		From:        "bc1palice",
+		To:          "bc1pbob",
+		TxHash:      fmt.Sprintf("%064d", 800000),
+		BlockHeight: 800000,
+	})
+	r.NoError(err)
+
+	// the tokens are held by the owners they were indexed with until the transfers are indexed.
+	var buf bytes.Buffer
+	_, err = s.syncer.ExportHolders(ctx, &buf, &biz.HolderListOption{Tick: "ordinals"}, HoldersFormatCSV)
+	r.ErrorIs(err, ErrTransfersNotIndexed)
+	r.NoError(s.syncer.saveTransfersHeight(ctx, 799000))
+	_, err = s.syncer.ExportHolders(ctx, &buf, &biz.HolderListOption{Tick: "ordinals", Height: 798999}, HoldersFormatCSV)
+	r.ErrorIs(err, ErrTransfersNotIndexed)
+	r.Empty(buf.String())
+
+	count, err := s.syncer.ExportHolders(ctx, &buf, &biz.HolderListOption{Tick: "ordinals"}, HoldersFormatCSV)
+	r.NoError(err)
+	r.Equal(2, count)
+	r.Equal("p,tick,address,count,token_ids\n"+
+		"brc-721,ordinals,bc1palice,2,1 3\n"+
+		"brc-721,ordinals,bc1pbob,1,2\n", buf.String())
+
+	// the holders before the transfer.
+	buf.Reset()
+	count, err = s.syncer.ExportHolders(ctx, &buf, &biz.HolderListOption{Tick: "ordinals", Height: 799999, MinCount: 2}, HoldersFormatJSON)
+	r.NoError(err)
+	r.Equal(1, count)
+	r.JSONEq(`[{"p": "brc-721", "tick": "ordinals", "address": "bc1palice", "count": 3, "token_ids": [1, 2, 3]}]`, buf.String())
+
+	_, err = s.syncer.ExportHolders(ctx, &buf, &biz.HolderListOption{}, "xml")
+	r.ErrorContains(err, "unknown holders format")
+}
@@ -0,0 +1,74 @@
+package ord
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+// ErrNotLeader is returned by the writes out of the sync, eg: Reprocess, if the leader
+// election is enabled and the syncer doesn't hold the lease.
+var ErrNotLeader = errors.New("not the leader of the syncers")
+
+// defaultLeaseTTL is the ttl of the leader lease by default.
+const defaultLeaseTTL = 15 * time.Second
+
+// leaseName is the name of the leader lease, the syncers of the same network elect
+// one leader by default.
+func (s *Syncer) leaseName() string {
+	if name := s.c.GetLeader().GetName(); name != "" {
+		return name
+	}
+	return "sync:" + s.network.Name
+}
+
+func (s *Syncer) leaseTTL() time.Duration {
+	if ttl := s.c.GetLeader().GetTtl(); ttl != nil {
+		return ttl.AsDuration()
+	}
+	return defaultLeaseTTL
+}
+
+// fence checks that the syncer is still the leader before a commit, so that a stale
+// leader stops early after its lease is taken over. The commits themselves are fenced
+// by the token of the context in the database. It is a no-op without the leader
+// election.
+func (s *Syncer) fence(ctx context.Context) error {
+	leadership := s.currentLeadership()
+	if leadership == nil {
+		return nil
+	}
+	return leadership.Check(ctx)
+}
+
+// leaderContext fences the commits of the context by the lease of the syncer, it
+// returns ErrNotLeader if the leader election is enabled and the syncer is a standby.
+// With the leader election disabled, the commits hold the lock of the lease instead,
+// so they are serialized with the ones of the sync.
+func (s *Syncer) leaderContext(ctx context.Context) (context.Context, error) {
+	if !s.c.GetLeader().GetEnabled() {
+		return biz.NewLockContext(ctx, s.leaseName()), nil
+	}
+	leadership := s.currentLeadership()
+	if leadership == nil {
+		return nil, ErrNotLeader
+	}
+	if err := leadership.Check(ctx); err != nil {
+		return nil, err
+	}
+	return biz.NewFenceContext(ctx, leadership.Fence()), nil
+}
+
+func (s *Syncer) setLeadership(leadership *biz.Leadership) {
+	s.leadershipLock.Lock()
+	defer s.leadershipLock.Unlock()
+	s.leadership = leadership
+}
+
+func (s *Syncer) currentLeadership() *biz.Leadership {
+	s.leadershipLock.RLock()
+	defer s.leadershipLock.RUnlock()
+	return s.leadership
+}
@@ -0,0 +1,162 @@
+package ord
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+// fakeLeaseRepo holds one lease in memory, the leases never expire.
+type fakeLeaseRepo struct {
+	sync.Mutex
+	holder string
+	token  int64
+}
+
+func (r *fakeLeaseRepo) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*biz.Lease, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.holder != "" {
+		return nil, nil
+	}
+	r.token++
+	r.holder = holder
+	return &biz.Lease{Name: name, Holder: holder, Token: r.token}, nil
+}
+
+func (r *fakeLeaseRepo) RenewLease(ctx context.Context, lease *biz.Lease, ttl time.Duration) error {
+	return r.CheckLease(ctx, lease)
+}
+
+func (r *fakeLeaseRepo) ReleaseLease(ctx context.Context, lease *biz.Lease) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.holder == lease.Holder && r.token == lease.Token {
+		r.holder = ""
+	}
+	return nil
+}
+
+func (r *fakeLeaseRepo) CheckLease(ctx context.Context, lease *biz.Lease) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.holder != lease.Holder || r.token != lease.Token {
+		return biz.ErrLeaseLost
+	}
+	return nil
+}
+
+// takeOver hands the lease over to the holder, as if the lease of the leader had expired.
+func (r *fakeLeaseRepo) takeOver(holder string) {
+	r.Lock()
+	defer r.Unlock()
+	r.token++
+	r.holder = holder
+}
+
+func (r *fakeLeaseRepo) leader() string {
+	r.Lock()
+	defer r.Unlock()
+	return r.holder
+}
+
+func (s *brc721SigTestSuite) TestLeaderFencesCommits() {
+	r := s.Require()
+	repo := &fakeLeaseRepo{}
+	leaderUc := biz.NewLeaderUsecase(repo, s.logger)
+	ctx := context.Background()
+	leadership, err := leaderUc.Campaign(ctx, "sync:mainnet", 300*time.Millisecond)
+	r.NoError(err)
+	defer leadership.Resign()
+	r.Equal(int64(1), leadership.Token())
+	s.syncer.leadership = leadership
+
+	s.syncer.pageParser = &fakePageParser{pages: map[string]interface{}{
+		"/block/788904": &page.Block{Height: 788904, TxIDs: []string{s.deployInfo.GenesisTx, s.mintInfo.GenesisTx}},
+	}}
+	deployInfo := *s.deployInfo
+	count, err := s.syncer.processResults(ctx, []*result{{info: &deployInfo}}, 0)
+	r.NoError(err)
+	r.Equal(1, count)
+
+	// the stale leader can't commit after the lease is taken over.
+	repo.takeOver("standby")
+	mintInfo := *s.mintInfo
+	count, err = s.syncer.processResults(ctx, []*result{{info: &mintInfo}}, 0)
+	r.ErrorIs(err, biz.ErrLeaseLost)
+	r.Equal(0, count)
+	tokens, err := s.tokenUc.CountTokens(ctx, &biz.TokenListOption{})
+	r.NoError(err)
+	r.Equal(0, tokens)
+	select {
+	case <-leadership.Context().Done():
+	case <-time.After(5 * time.Second):
+		r.Fail("the leadership is not lost")
+	}
+}
+
+func (s *brc721SigTestSuite) TestLeaderFencesResultsInTx() {
+	r := s.Require()
+	ctx := context.Background()
+	s.syncer.pageParser = &fakePageParser{pages: map[string]interface{}{
+		"/block/788904": &page.Block{Height: 788904, TxIDs: []string{s.deployInfo.GenesisTx, s.mintInfo.GenesisTx}},
+	}}
+	deployInfo, mintInfo := *s.deployInfo, *s.mintInfo
+	_, err := s.syncer.processResults(ctx, []*result{{info: &deployInfo}}, 0)
+	r.NoError(err)
+	// a newer leader has committed, the result of the stale one is not committed at all.
+	r.NoError(s.d.InTx(biz.NewFenceContext(ctx, &biz.Fence{Name: "sync:mainnet", Token: 2}), func(ctx context.Context) error {
+		return nil
+	}))
+	stale := biz.NewFenceContext(ctx, &biz.Fence{Name: "sync:mainnet", Token: 1})
+	count, err := s.syncer.processResults(stale, []*result{{info: &mintInfo}}, 0)
+	r.ErrorIs(err, biz.ErrLeaseLost)
+	r.Equal(0, count)
+	ins, err := s.inscriptionUc.FindByUID(ctx, mintInfo.UID)
+	r.NoError(err)
+	r.Nil(ins)
+	tokens, err := s.tokenUc.CountTokens(ctx, &biz.TokenListOption{})
+	r.NoError(err)
+	r.Equal(0, tokens)
+}
+
+func (s *brc721SigTestSuite) TestLeaderStandbyTakesOver() {
+	r := s.Require()
+	s.c.Leader = &conf.Ord_Leader{Enabled: true, Ttl: durationpb.New(300 * time.Millisecond)}
+	defer func() { s.c.Leader = nil }()
+
+	repo := &fakeLeaseRepo{}
+	repo.takeOver("leader")
+	s.syncer.leaderUc = biz.NewLeaderUsecase(repo, s.logger)
+	parser := &fakePageParser{pages: map[string]interface{}{
+		"/inscriptions/0": &page.Inscriptions{},
+	}}
+	s.syncer.pageParser = parser
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() { errC <- s.syncer.Run(ctx) }()
+
+	// the standby doesn't sync while the lease is held by the leader.
+	time.Sleep(300 * time.Millisecond)
+	r.Empty(parser.parsedURLs())
+	repo.takeOver("")
+	r.Eventually(func() bool { return len(parser.parsedURLs()) > 0 }, 5*time.Second, 10*time.Millisecond)
+	r.NotEqual("", repo.leader())
+
+	// the lease is released on shutdown.
+	cancel()
+	select {
+	case err := <-errC:
+		r.NoError(err)
+	case <-time.After(5 * time.Second):
+		r.Fail("the syncer is not stopped")
+	}
+	r.Equal("", repo.leader())
+}
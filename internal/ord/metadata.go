@@ -0,0 +1,226 @@
+package ord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord/parser"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// metadataTimeout is the timeout of fetching the metadata of a token.
+	metadataTimeout = 10 * time.Second
+	// maxMetadataLength is the max length of the metadata of a token in bytes.
+	maxMetadataLength = 1 << 20
+	// maxMetadataRedirects is the max number of redirects of the metadata uri.
+	maxMetadataRedirects = 3
+)
+
+var (
+	// errNonPublicAddress is returned when the metadata uri is not on a public address.
+	errNonPublicAddress = errors.New("not a public address")
+
+	// publicClient fetches the metadata from the base uris chosen by the inscribers,
+	// it connects to the public addresses only, so that the syncer can't be used to
+	// reach the internal hosts.
+	publicClient = newMetadataClient(&net.Dialer{Timeout: metadataTimeout, Control: dialPublicOnly})
+	// gatewayClient fetches the metadata from the configured ipfs gateway, which may
+	// be a local one.
+	gatewayClient = newMetadataClient(&net.Dialer{Timeout: metadataTimeout})
+
+	// fetchMetadata gets the metadata from the uri, the uri is trusted if it is on the
+	// ipfs gateway.
+	fetchMetadata = func(ctx context.Context, uri string, trusted bool) ([]byte, error) {
+		client := publicClient
+		if trusted {
+			client = gatewayClient
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMetadataURL(req.URL); err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("status code %d", resp.StatusCode)
+		}
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxMetadataLength+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > maxMetadataLength {
+			return nil, fmt.Errorf("metadata is larger than %d bytes", maxMetadataLength)
+		}
+		return body, nil
+	}
+)
+
+func newMetadataClient(dialer *net.Dialer) *http.Client {
+	return &http.Client{
+		Timeout: metadataTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   metadataTimeout,
+			ResponseHeaderTimeout: metadataTimeout,
+			MaxIdleConnsPerHost:   2,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxMetadataRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxMetadataRedirects)
+			}
+			return checkMetadataURL(req.URL)
+		},
+	}
+}
+
+// checkMetadataURL accepts the http and https urls only.
+func checkMetadataURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+// dialPublicOnly rejects the connections to the loopback, private, link local and
+// other non-public addresses, it is checked on the resolved address of every dial.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%s: %w", address, errNonPublicAddress)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT space of RFC 6598.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// resolveTraits resolves the traits of the token. The tokens of a collection
+// without base uri share the metadata of the collection, otherwise the metadata
+// is fetched from the base uri followed by the token id, if enabled.
+func (h *brc721Handler) resolveTraits(ctx context.Context, collection *biz.Collection, tokenID uint64) ([]*biz.Trait, error) {
+	if collection.BaseURI == "" {
+		return biz.ParseTraits(collection.Attributes), nil
+	}
+	if h.Conf.Metadata == nil || !h.Conf.Metadata.Resolve {
+		return nil, nil
+	}
+	uri, trusted := h.metadataURI(collection.BaseURI + strconv.FormatUint(tokenID, 10))
+	body, err := fetchMetadata(ctx, uri, trusted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata %s: %w", uri, err)
+	}
+	var meta parser.BRC721Meta
+	if err := jsoniter.Unmarshal(body, &meta); err != nil {
+		return nil, fmt.Errorf("invalid metadata %s: %v", uri, err)
+	}
+	return biz.ParseTraits(meta.Attributes), nil
+}
+
+// metadataURI rewrites the ipfs:// uri to the ipfs gateway, and reports whether it is rewritten.
+func (h *brc721Handler) metadataURI(uri string) (string, bool) {
+	gateway := h.Conf.Metadata.IpfsGateway
+	if gateway == "" || !strings.HasPrefix(uri, "ipfs://") {
+		return uri, false
+	}
+	return strings.TrimSuffix(gateway, "/") + "/" + strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/"), true
+}
+
+// ingestTraits saves the traits of the minted token from the metadata of its collection.
+// The metadata of the base uri is never fetched on the mint, as the base uri is chosen
+// by the inscriber, it is resolved by RefreshTraits.
+func (h *brc721Handler) ingestTraits(ctx context.Context, collection *biz.Collection, token *biz.Token) {
+	if h.TraitUc == nil || collection.BaseURI != "" {
+		return
+	}
+	traits := biz.ParseTraits(collection.Attributes)
+	if len(traits) == 0 {
+		return
+	}
+	if err := h.TraitUc.SetTokenTraits(ctx, token, traits); err != nil {
+		h.Logger.Warnf("failed to save traits of token %s %d: %v", token.Tick, token.TokenID, err)
+	}
+}
+
+// RefreshTraits resolves the traits of all the tokens of the BRC-721 collection again,
+// and returns the number of the tokens with traits, and of the tokens whose metadata
+// failed to resolve. The traits of the failed tokens are kept as they were.
+func (s *Syncer) RefreshTraits(ctx context.Context, tick string) (int, int, error) {
+	h, ok := s.protocol(parser.BRC721).(*brc721Handler)
+	if !ok {
+		return 0, 0, fmt.Errorf("protocol %s is not registered", parser.BRC721)
+	}
+	return h.refreshTraits(ctx, tick)
+}
+
+func (h *brc721Handler) refreshTraits(ctx context.Context, tick string) (int, int, error) {
+	collection, err := h.CollectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, tick)
+	if err != nil {
+		return 0, 0, err
+	}
+	if collection == nil {
+		return 0, 0, fmt.Errorf("collection %s not found", tick)
+	}
+	count, failed := 0, 0
+	for offset := 0; ; {
+		tokens, err := h.TokenUc.ListTokens(ctx, &biz.TokenListOption{
+			P:      biz.ProtocolTypeBRC721,
+			Tick:   tick,
+			Order:  "token_id",
+			Offset: offset,
+		})
+		if err != nil {
+			return count, failed, err
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		for _, token := range tokens {
+			traits, err := h.resolveTraits(ctx, collection, token.TokenID)
+			if err != nil {
+				// one unavailable metadata doesn't leave the rest of the collection stale.
+				h.Logger.Warnf("failed to refresh traits of token %s %d: %v", tick, token.TokenID, err)
+				failed++
+				continue
+			}
+			if err := h.TraitUc.SetTokenTraits(ctx, token, traits); err != nil {
+				return count, failed, err
+			}
+			if len(traits) > 0 {
+				count++
+			}
+		}
+		h.Logger.Infof("refreshed traits of %d tokens of collection %s", offset+len(tokens), tick)
+		offset += len(tokens)
+	}
+	return count, failed, nil
+}
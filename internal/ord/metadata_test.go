@@ -0,0 +1,142 @@
+package ord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+func (s *brc721SigTestSuite) mockMetadata(responses map[string]string) func() {
+	fetch := fetchMetadata
+	fetchMetadata = func(ctx context.Context, uri string, trusted bool) ([]byte, error) {
+		body, ok := responses[uri]
+		if !ok {
+			return nil, errors.New("unexpected uri: " + uri)
+		}
+		return []byte(body), nil
+	}
+	return func() { fetchMetadata = fetch }
+}
+
+func (s *brc721SigTestSuite) tokenRarity(tick string, tokenID uint64) *biz.TokenRarity {
+	r := s.Require()
+	token, err := s.tokenUc.FindByTickTokenID(context.Background(), biz.ProtocolTypeBRC721, tick, tokenID)
+	r.NoError(err)
+	r.NotNil(token)
+	rarities, err := s.traitUc.GetTokensRarity(context.Background(), []*biz.Token{token})
+	r.NoError(err)
+	return rarities[token.ID]
+}
+
+func (s *brc721SigTestSuite) TestMintTraitsFromCollectionMeta() {
+	collection := s.newCollection()
+	collection.BaseURI = ""
+	collection.Attributes = []map[string]interface{}{
+		{"trait_type": "eyes", "value": "blue"},
+		{"trait_type": "level", "value": float64(5)},
+		{"value": "no trait type"},
+	}
+	_, err := s.collectionUc.CreateCollection(context.Background(), collection)
+	r := s.Require()
+	r.NoError(err)
+
+	r.NoError(s.brc721.processMint(context.Background(), s.mintInfo))
+	rarity := s.tokenRarity(collection.Tick, 1)
+	r.Len(rarity.Traits, 2)
+	r.Equal("eyes", rarity.Traits[0].TraitType)
+	r.Equal("level", rarity.Traits[1].TraitType)
+	r.Equal("5", rarity.Traits[1].Value)
+	r.Equal(2.0, rarity.RarityScore)
+}
+
+func (s *brc721SigTestSuite) TestMintTraitsFromBaseURI() {
+	collection := s.newCollection()
+	collection.BaseURI = "ipfs://bafy/"
+	_, err := s.collectionUc.CreateCollection(context.Background(), collection)
+	r := s.Require()
+	r.NoError(err)
+	defer s.mockMetadata(map[string]string{
+		"https://ipfs.io/ipfs/bafy/1": `{"name": "#1", "attributes": [{"trait_type": "hat", "value": "crown"}]}`,
+	})()
+
+	// the metadata of the base uri is never resolved on the mint.
+	s.c.Metadata = &conf.Ord_Metadata{Resolve: true, IpfsGateway: "https://ipfs.io/ipfs/"}
+	defer func() { s.c.Metadata = nil }()
+	r.NoError(s.brc721.processMint(context.Background(), s.mintInfo))
+	r.Empty(s.tokenRarity(collection.Tick, 1).Traits)
+
+	count, failed, err := s.syncer.RefreshTraits(context.Background(), collection.Tick)
+	r.NoError(err)
+	r.Equal(1, count)
+	r.Equal(0, failed)
+	rarity := s.tokenRarity(collection.Tick, 1)
+	r.Len(rarity.Traits, 1)
+	r.Equal(&biz.TraitStat{TraitType: "hat", Value: "crown", Count: 1, Frequency: 1, RarityScore: 1}, rarity.Traits[0])
+
+	// the mint does not wait for the metadata, which may be unavailable.
+	mintInfo := *s.mintInfo
+	mintInfo.ID++
+	mintInfo.UID = "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i1"
+	r.NoError(s.brc721.processMint(context.Background(), &mintInfo))
+	r.Empty(s.tokenRarity(collection.Tick, 2).Traits)
+
+	// the tokens after the one whose metadata is unavailable are still refreshed, and
+	// the traits of the failed one are kept.
+	defer s.mockMetadata(map[string]string{
+		"https://ipfs.io/ipfs/bafy/2": `{"name": "#2", "attributes": [{"trait_type": "hat", "value": "cap"}]}`,
+	})()
+	count, failed, err = s.syncer.RefreshTraits(context.Background(), collection.Tick)
+	r.NoError(err)
+	r.Equal(1, count)
+	r.Equal(1, failed)
+	r.Equal("crown", s.tokenRarity(collection.Tick, 1).Traits[0].Value)
+	r.Equal("cap", s.tokenRarity(collection.Tick, 2).Traits[0].Value)
+}
+
+func TestFetchMetadata(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/1":
+			fmt.Fprint(w, `{"attributes": []}`)
+		case "/large":
+			fmt.Fprint(w, strings.Repeat(" ", maxMetadataLength+1))
+		case "/redirect":
+			http.Redirect(w, req, "file:///etc/passwd", http.StatusFound)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+
+	body, err := fetchMetadata(ctx, srv.URL+"/1", true)
+	r.NoError(err)
+	r.Equal(`{"attributes": []}`, string(body))
+	_, err = fetchMetadata(ctx, srv.URL+"/large", true)
+	r.ErrorContains(err, "larger than")
+	_, err = fetchMetadata(ctx, srv.URL+"/missing", true)
+	r.ErrorContains(err, "status code 404")
+	_, err = fetchMetadata(ctx, srv.URL+"/redirect", true)
+	r.ErrorContains(err, "unsupported scheme")
+	_, err = fetchMetadata(ctx, "file:///etc/passwd", true)
+	r.ErrorContains(err, "unsupported scheme")
+
+	// the base uris of the inscribers can't reach the internal hosts.
+	_, err = fetchMetadata(ctx, srv.URL+"/1", false)
+	r.ErrorIs(err, errNonPublicAddress)
+	for _, ip := range []string{"127.0.0.1", "10.0.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "::1", "fd00::1", "0.0.0.0"} {
+		r.Error(dialPublicOnly("tcp", net.JoinHostPort(ip, "80"), nil), ip)
+	}
+	r.NoError(dialPublicOnly("tcp", "1.1.1.1:443", nil))
+}
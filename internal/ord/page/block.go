@@ -0,0 +1,87 @@
+package page
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type Block struct {
+	Height   uint64 `json:"height"`
+	Hash     string `json:"hash"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	// Timestamp is the time of the block, zero if ord doesn't show it.
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	// TxIDs are the transactions of the block in order.
+	TxIDs []string `json:"txids"`
+	// UIDs are the inscriptions featured on the block page, the block inscriptions
+	// pages list all of them.
+	UIDs []string `json:"uids"`
+}
+
+type BlockPage struct {
+	Height uint64
+}
+
+var (
+	_ Page = (*BlockPage)(nil)
+)
+
+func NewBlockPage(height uint64) *BlockPage {
+	return &BlockPage{
+		Height: height,
+	}
+}
+
+func (p *BlockPage) URL() string {
+	return fmt.Sprintf("/block/%d", p.Height)
+}
+
+func (p *BlockPage) Parse(r io.Reader) (interface{}, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	block := &Block{
+		TxIDs: make([]string, 0),
+		UIDs:  make([]string, 0),
+	}
+	heightText := strings.TrimPrefix(doc.Find("h1").First().Text(), "Block ")
+	height, err := strconv.ParseUint(heightText, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert height %s to uint64: %v", heightText, err)
+	}
+	block.Height = height
+
+	ddElements := doc.Find("dl dd")
+	doc.Find("dl dt").Each(func(i int, dt *goquery.Selection) {
+		value := strings.TrimSpace(ddElements.Eq(i).Text())
+		switch strings.ToLower(dt.Text()) {
+		case "hash":
+			block.Hash = value
+		case "previous blockhash":
+			block.PrevHash = value
+		case "timestamp":
+			// convert "2023-05-07 12:14:37 UTC" to time.Time
+			v, _ := time.Parse("2006-01-02 15:04:05 UTC", value)
+			block.Timestamp = v
+		}
+	})
+	doc.Find("div.thumbnails a[href^='/inscription/']").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		block.UIDs = append(block.UIDs, strings.TrimPrefix(href, "/inscription/"))
+	})
+	doc.Find("ul a[href^='/tx/']").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		block.TxIDs = append(block.TxIDs, strings.TrimPrefix(href, "/tx/"))
+	})
+	if block.Hash == "" {
+		return nil, fmt.Errorf("block %d has no hash", height)
+	}
+	return block, nil
+}
@@ -0,0 +1,112 @@
+package page
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockPage(t *testing.T) {
+	r := require.New(t)
+	body := `<!doctype html>
+<html lang=en>
+  <head>
+    <title>Block 788904</title>
+  </head>
+  <body>
+  <main>
+<h1>Block 788904</h1>
+<dl>
+  <dt>hash</dt><dd class=monospace>00000000000000000004a1c6ff1c9b5ef2c3b1d2e4ab9e2f1c0f5b5d8b7a2b1c</dd>
+  <dt>target</dt><dd class=monospace>0000000000000000000538ee0000000000000000000000000000000000000000</dd>
+  <dt>timestamp</dt><dd><time>2023-05-07 12:14:37 UTC</time></dd>
+  <dt>size</dt><dd>1604527</dd>
+  <dt>weight</dt><dd>3993286</dd>
+  <dt>previous blockhash</dt><dd><a href=/block/0000000000000000000311c3d8fde1c4b1d4c4c8d8f4a5f0d1e5a2b3c4d5e6f7 class=monospace>0000000000000000000311c3d8fde1c4b1d4c4c8d8f4a5f0d1e5a2b3c4d5e6f7</a></dd>
+</dl>
+<div class=center>
+<a class=prev href=/block/788903>prev</a>
+<a class=next href=/block/788905>next</a>
+</div>
+<h2>2 Inscriptions</h2>
+<div class=thumbnails>
+  <a href=/inscription/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0><iframe sandbox=allow-scripts scrolling=no loading=lazy src=/preview/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0></iframe></a>
+  <a href=/inscription/9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0><iframe sandbox=allow-scripts scrolling=no loading=lazy src=/preview/9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0></iframe></a>
+</div>
+<h2>3 Transactions</h2>
+<ul class=monospace>
+  <li><a href=/tx/e3678715396719368e039fa56a09aa77eb30a2ea525f5489779626e355a31b65>e3678715396719368e039fa56a09aa77eb30a2ea525f5489779626e355a31b65</a></li>
+  <li><a href=/tx/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564>347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564</a></li>
+  <li><a href=/tx/9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063>9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063</a></li>
+</ul>
+  </main>
+  </body>
+</html>`
+	p := NewBlockPage(788904)
+	r.Equal("/block/788904", p.URL())
+	data, err := p.Parse(strings.NewReader(body))
+	r.NoError(err)
+	block := data.(*Block)
+	r.Equal(uint64(788904), block.Height)
+	r.Equal("00000000000000000004a1c6ff1c9b5ef2c3b1d2e4ab9e2f1c0f5b5d8b7a2b1c", block.Hash)
+	r.Equal("0000000000000000000311c3d8fde1c4b1d4c4c8d8f4a5f0d1e5a2b3c4d5e6f7", block.PrevHash)
+	r.Equal(time.Date(2023, 5, 7, 12, 14, 37, 0, time.UTC), block.Timestamp)
+	r.Equal([]string{
+		"e3678715396719368e039fa56a09aa77eb30a2ea525f5489779626e355a31b65",
+		"347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564",
+		"9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063",
+	}, block.TxIDs)
+	r.Equal([]string{
+		"347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
+		"9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0",
+	}, block.UIDs)
+
+	// the pages without the block hash are invalid.
+	_, err = p.Parse(strings.NewReader(`<h1>Block 788904</h1>`))
+	r.Error(err)
+}
+
+func TestBlockInscriptionsPage(t *testing.T) {
+	r := require.New(t)
+	body := `<h1>Inscriptions in <a href=/block/788904>Block 788904</a></h1>
+<div class=thumbnails>
+  <a href=/inscription/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0><iframe sandbox=allow-scripts scrolling=no loading=lazy src=/preview/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0></iframe></a>
+  <a href=/inscription/9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0><iframe sandbox=allow-scripts scrolling=no loading=lazy src=/preview/9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0></iframe></a>
+</div>
+<div class=center>
+prev
+<a class=next href=/inscriptions/block/788904/1>next</a>
+</div>`
+	p := NewBlockInscriptionsPage(788904, 0)
+	r.Equal("/inscriptions/block/788904/0", p.URL())
+	data, err := p.Parse(strings.NewReader(body))
+	r.NoError(err)
+	inscriptions := data.(*BlockInscriptions)
+	r.Equal([]string{
+		"347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
+		"9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0",
+	}, inscriptions.UIDs)
+	r.NotNil(inscriptions.NextPage)
+	r.Equal(uint64(1), *inscriptions.NextPage)
+
+	// the last page has no next page.
+	data, err = NewBlockInscriptionsPage(788904, 1).Parse(strings.NewReader(`<h1>Inscriptions in <a href=/block/788904>Block 788904</a></h1><div class=thumbnails></div><div class=center><a class=prev href=/inscriptions/block/788904/0>prev</a> next</div>`))
+	r.NoError(err)
+	r.Empty(data.(*BlockInscriptions).UIDs)
+	r.Nil(data.(*BlockInscriptions).NextPage)
+
+	// the not found page is an error instead of an empty block.
+	_, err = NewBlockInscriptionsPage(900000, 0).Parse(strings.NewReader(`Not Found`))
+	r.Error(err)
+}
+
+func TestBlockHeightPage(t *testing.T) {
+	r := require.New(t)
+	data, err := NewBlockHeightPage().Parse(strings.NewReader("788905\n"))
+	r.NoError(err)
+	r.Equal(uint64(788905), data)
+	_, err = NewBlockHeightPage().Parse(strings.NewReader("Not Found"))
+	r.Error(err)
+}
@@ -0,0 +1,36 @@
+package page
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BlockHeightPage is the height of the latest block indexed by ord in plain text.
+type BlockHeightPage struct{}
+
+var (
+	_ Page = (*BlockHeightPage)(nil)
+)
+
+func NewBlockHeightPage() *BlockHeightPage {
+	return &BlockHeightPage{}
+}
+
+func (p *BlockHeightPage) URL() string {
+	return "/blockheight"
+}
+
+func (p *BlockHeightPage) Parse(r io.Reader) (interface{}, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(b))
+	height, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert block height %q to uint64: %v", text, err)
+	}
+	return height, nil
+}
@@ -0,0 +1,70 @@
+package page
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type BlockInscriptions struct {
+	Height uint64   `json:"height"`
+	UIDs   []string `json:"uids"`
+	// NextPage is the next page of the inscriptions of the block, nil on the last page.
+	NextPage *uint64 `json:"next_page,omitempty"`
+}
+
+// BlockInscriptionsPage is a page of all the inscriptions of a block, the block page
+// shows a few of them only.
+type BlockInscriptionsPage struct {
+	Height uint64
+	Page   uint64
+}
+
+var (
+	_ Page = (*BlockInscriptionsPage)(nil)
+)
+
+func NewBlockInscriptionsPage(height, page uint64) *BlockInscriptionsPage {
+	return &BlockInscriptionsPage{
+		Height: height,
+		Page:   page,
+	}
+}
+
+func (p *BlockInscriptionsPage) URL() string {
+	return fmt.Sprintf("/inscriptions/block/%d/%d", p.Height, p.Page)
+}
+
+func (p *BlockInscriptionsPage) Parse(r io.Reader) (interface{}, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(strings.TrimSpace(doc.Find("h1").First().Text()), "Inscriptions in Block") {
+		return nil, fmt.Errorf("invalid inscriptions page of block %d", p.Height)
+	}
+
+	inscriptions := &BlockInscriptions{
+		Height: p.Height,
+		UIDs:   make([]string, 0),
+	}
+	doc.Find("div.thumbnails a[href^='/inscription/']").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		inscriptions.UIDs = append(inscriptions.UIDs, strings.TrimPrefix(href, "/inscription/"))
+	})
+
+	nextLink := doc.Find("a.next")
+	if nextLink.Length() > 0 {
+		href, _ := nextLink.Attr("href")
+		nextPageText := strings.TrimPrefix(href, fmt.Sprintf("/inscriptions/block/%d/", p.Height))
+		nextPage, err := strconv.ParseUint(nextPageText, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert next page %s to uint64: %v", nextPageText, err)
+		}
+		inscriptions.NextPage = &nextPage
+	}
+	return inscriptions, nil
+}
@@ -1,12 +1,27 @@
 package page
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
 
 	"github.com/adshao/ordinals-indexer/internal/ord/parser"
 )
 
+const (
+	// ContentTypeRaw is the type of the content that no parser accepts after it is fetched.
+	ContentTypeRaw = "raw"
+	// ContentTypeSkipped is the type of the content that is not fetched or parsed,
+	// as no parser accepts its media type, or it is larger than the max length.
+	ContentTypeSkipped = "skipped"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
 type Content struct {
 	Data interface{} `json:"data"`
 	Type string      `json:"type"`
@@ -14,28 +29,69 @@ type Content struct {
 
 type ContentPage struct {
 	inscriptionUid string
+	mediaType      string
+	charset        string
+	maxLength      uint64
+	parsers        []parser.Parser
 }
 
 var (
 	_ Page = (*ContentPage)(nil)
 )
 
-func NewContentPage(inscriptionUid string) *ContentPage {
-	return &ContentPage{
+// NewContentPage parses the content by the first parser that accepts it. The parsers
+// are filtered by the media type of the content type, eg: text/plain;charset=utf-8,
+// all the parsers are tried if the content type is unknown. The content larger than
+// maxLength bytes is skipped, 0 means no limit.
+func NewContentPage(inscriptionUid, contentType string, maxLength uint64, parsers []parser.Parser) *ContentPage {
+	p := &ContentPage{
 		inscriptionUid: inscriptionUid,
+		maxLength:      maxLength,
+	}
+	p.mediaType, p.charset = parseContentType(contentType)
+	for _, ps := range parsers {
+		if filter, ok := ps.(parser.ContentFilter); ok && p.mediaType != "" && !filter.Accept(p.mediaType) {
+			continue
+		}
+		p.parsers = append(p.parsers, ps)
 	}
+	return p
 }
 
 func (p *ContentPage) URL() string {
 	return fmt.Sprintf("/content/%s", p.inscriptionUid)
 }
 
+// Accepted returns false if the content of the length is not worth fetching,
+// the length is unknown if 0.
+func (p *ContentPage) Accepted(length uint64) bool {
+	if len(p.parsers) == 0 {
+		return false
+	}
+	return p.maxLength == 0 || length <= p.maxLength
+}
+
+// Parse reads the content up to the max length only, the rest of the large
+// content is dropped with the response body.
 func (p *ContentPage) Parse(r io.Reader) (interface{}, error) {
+	if len(p.parsers) == 0 {
+		return &Content{Type: ContentTypeSkipped}, nil
+	}
+	if p.maxLength > 0 {
+		r = io.LimitReader(r, int64(p.maxLength)+1)
+	}
 	body, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range parser.ParserList() {
+	if p.maxLength > 0 && uint64(len(body)) > p.maxLength {
+		return &Content{Type: ContentTypeSkipped}, nil
+	}
+	body, err = p.decode(body)
+	if err != nil {
+		return &Content{Type: ContentTypeRaw}, nil
+	}
+	for _, p := range p.parsers {
 		data, valid, err := p.Parse(body)
 		if err != nil {
 			continue
@@ -48,8 +104,31 @@ func (p *ContentPage) Parse(r io.Reader) (interface{}, error) {
 			Type: p.Name(),
 		}, nil
 	}
-	return &Content{
-		Data: body,
-		Type: "raw",
-	}, nil
+	return &Content{Type: ContentTypeRaw}, nil
+}
+
+// decode decodes the text content of the charset to utf-8. The charset of
+// application/json is ignored, as JSON is always encoded in utf-8.
+func (p *ContentPage) decode(body []byte) ([]byte, error) {
+	if strings.HasPrefix(p.mediaType, "text/") && p.charset != "" && p.charset != "utf-8" && p.charset != "utf8" {
+		enc, err := htmlindex.Get(p.charset)
+		if err != nil {
+			return nil, err
+		}
+		body, err = enc.NewDecoder().Bytes(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bytes.TrimPrefix(body, utf8BOM), nil
+}
+
+// parseContentType returns the lower case media type and charset of the content type.
+func parseContentType(contentType string) (string, string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		return strings.ToLower(strings.TrimSpace(mediaType)), ""
+	}
+	return mediaType, strings.ToLower(params["charset"])
 }
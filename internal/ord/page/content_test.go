@@ -1,10 +1,14 @@
 package page
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/adshao/go-brc721/sig"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
 
 	"github.com/adshao/ordinals-indexer/internal/conf"
 	"github.com/adshao/ordinals-indexer/internal/ord/parser"
@@ -25,8 +29,8 @@ func TestContentPageBRC721Deploy(t *testing.T) {
 	brc721DeployContent := []byte(`{"p": "brc-721", "op": "deploy", "tick": "sato", "max": "10000", "meta": {"name": "Satoshi", "description": "The ChatGPT 09/May/2023 Financial institutions on the precipice as three banks collapse in 2023.", "image": "data:image/svg+xml;base64,PHN2ZyB4bWxuczpyZGY9Imh0dHA6Ly93d3cudzMub3JnLzE5OTkvMDIvMjItcmRmLXN5bnRheC1ucyMiIHhtbG5zPSJodHRwOi8vd3d3LnczLm9yZy8yMDAwL3N2ZyIgaGVpZ2h0PSI2NCIgd2lkdGg9IjY0IiB2ZXJzaW9uPSIxLjEiIHhtbG5zOmNjPSJodHRwOi8vY3JlYXRpdmVjb21tb25zLm9yZy9ucyMiIHhtbG5zOmRjPSJodHRwOi8vcHVybC5vcmcvZGMvZWxlbWVudHMvMS4xLyI+CjxnIHRyYW5zZm9ybT0idHJhbnNsYXRlKDAuMDA2MzA4NzYsLTAuMDAzMDE5ODQpIj4KPHBhdGggZmlsbD0iI2Y3OTMxYSIgZD0ibTYzLjAzMywzOS43NDRjLTQuMjc0LDE3LjE0My0yMS42MzcsMjcuNTc2LTM4Ljc4MiwyMy4zMDEtMTcuMTM4LTQuMjc0LTI3LjU3MS0yMS42MzgtMjMuMjk1LTM4Ljc4LDQuMjcyLTE3LjE0NSwyMS42MzUtMjcuNTc5LDM4Ljc3NS0yMy4zMDUsMTcuMTQ0LDQuMjc0LDI3LjU3NiwyMS42NCwyMy4zMDIsMzguNzg0eiIvPgo8cGF0aCBmaWxsPSIjRkZGIiBkPSJtNDYuMTAzLDI3LjQ0NGMwLjYzNy00LjI1OC0yLjYwNS02LjU0Ny03LjAzOC04LjA3NGwxLjQzOC01Ljc2OC0zLjUxMS0wLjg3NS0xLjQsNS42MTZjLTAuOTIzLTAuMjMtMS44NzEtMC40NDctMi44MTMtMC42NjJsMS40MS01LjY1My0zLjUwOS0wLjg3NS0xLjQzOSw1Ljc2NmMtMC43NjQtMC4xNzQtMS41MTQtMC4zNDYtMi4yNDItMC41MjdsMC4wMDQtMC4wMTgtNC44NDItMS4yMDktMC45MzQsMy43NXMyLjYwNSwwLjU5NywyLjU1LDAuNjM0YzEuNDIyLDAuMzU1LDEuNjc5LDEuMjk2LDEuNjM2LDIuMDQybC0xLjYzOCw2LjU3MWMwLjA5OCwwLjAyNSwwLjIyNSwwLjA2MSwwLjM2NSwwLjExNy0wLjExNy0wLjAyOS0wLjI0Mi0wLjA2MS0wLjM3MS0wLjA5MmwtMi4yOTYsOS4yMDVjLTAuMTc0LDAuNDMyLTAuNjE1LDEuMDgtMS42MDksMC44MzQsMC4wMzUsMC4wNTEtMi41NTItMC42MzctMi41NTItMC42MzdsLTEuNzQzLDQuMDE5LDQuNTY5LDEuMTM5YzAuODUsMC4yMTMsMS42ODMsMC40MzYsMi41MDMsMC42NDZsLTEuNDUzLDUuODM0LDMuNTA3LDAuODc1LDEuNDM5LTUuNzcyYzAuOTU4LDAuMjYsMS44ODgsMC41LDIuNzk4LDAuNzI2bC0xLjQzNCw1Ljc0NSwzLjUxMSwwLjg3NSwxLjQ1My01LjgyM2M1Ljk4NywxLjEzMywxMC40ODksMC42NzYsMTIuMzg0LTQuNzM5LDEuNTI3LTQuMzYtMC4wNzYtNi44NzUtMy4yMjYtOC41MTUsMi4yOTQtMC41MjksNC4wMjItMi4wMzgsNC40ODMtNS4xNTV6bS04LjAyMiwxMS4yNDljLTEuMDg1LDQuMzYtOC40MjYsMi4wMDMtMTAuODA2LDEuNDEybDEuOTI4LTcuNzI5YzIuMzgsMC41OTQsMTAuMDEyLDEuNzcsOC44NzgsNi4zMTd6bTEuMDg2LTExLjMxMmMtMC45OSwzLjk2Ni03LjEsMS45NTEtOS4wODIsMS40NTdsMS43NDgtNy4wMWMxLjk4MiwwLjQ5NCw4LjM2NSwxLjQxNiw3LjMzNCw1LjU1M3oiLz4KPC9nPgo8L3N2Zz4="}}`)
 	mockHTTPResult("http://localhost:8080/content/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", brc721DeployContent)
 
-	page := NewContentPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0")
-	data, err := pp.Parse(page)
+	page := NewContentPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", "", 0, parser.BRC721Parsers())
+	data, err := pp.Parse(context.Background(), page)
 
 	r := require.New(t)
 	r.Nil(err)
@@ -61,8 +65,8 @@ func TestContentPageBRC721DeployWithSig(t *testing.T) {
 	brc721DeployContent := []byte(`{"p": "brc-721", "op": "deploy", "tick": "sato", "max": "10000", "buri": "https://abc/", "sig": {"pk": "0379f79637ec1cc5375c4e269e9d70eda426b5ecba5d4088234a89e8943dc4aa9f", "fields": ["rec", "uid"]}}`)
 	mockHTTPResult("http://localhost:8080/content/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", brc721DeployContent)
 
-	page := NewContentPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0")
-	data, err := pp.Parse(page)
+	page := NewContentPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", "", 0, parser.BRC721Parsers())
+	data, err := pp.Parse(context.Background(), page)
 
 	r := require.New(t)
 	r.Nil(err)
@@ -99,8 +103,8 @@ func TestContentPageBRC721DeployWithSigInvalidFields(t *testing.T) {
 	brc721DeployContent := []byte(`{"p": "brc-721", "op": "deploy", "tick": "sato", "max": "10000", "buri": "https://abc/", "sig": {"pk": "0379f79637ec1cc5375c4e269e9d70eda426b5ecba5d4088234a89e8943dc4aa9f", "fields": ["rec123", "uid"]}}`)
 	mockHTTPResult("http://localhost:8080/content/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", brc721DeployContent)
 
-	page := NewContentPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0")
-	data, err := pp.Parse(page)
+	page := NewContentPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", "", 0, parser.BRC721Parsers())
+	data, err := pp.Parse(context.Background(), page)
 	r := require.New(t)
 	r.Nil(err)
 	content, ok := data.(*Content)
@@ -136,8 +140,8 @@ func TestContentPageBRC721Mint(t *testing.T) {
 	brc721DeployContent := []byte(`{"p": "brc-721", "op": "mint", "tick": "sato"}`)
 	mockHTTPResult("http://localhost:8080/content/8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0", brc721DeployContent)
 
-	page := NewContentPage("8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0")
-	data, err := pp.Parse(page)
+	page := NewContentPage("8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0", "", 0, parser.BRC721Parsers())
+	data, err := pp.Parse(context.Background(), page)
 
 	r := require.New(t)
 	r.Nil(err)
@@ -171,8 +175,8 @@ func TestContentPageBRC721Update(t *testing.T) {
 		"buri": "https://ipfs.io/abc/"}`)
 	mockHTTPResult("http://localhost:8080/content/8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0", brc721DeployContent)
 
-	page := NewContentPage("8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0")
-	data, err := pp.Parse(page)
+	page := NewContentPage("8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0", "", 0, parser.BRC721Parsers())
+	data, err := pp.Parse(context.Background(), page)
 
 	r := require.New(t)
 	r.Nil(err)
@@ -187,3 +191,51 @@ func TestContentPageBRC721Update(t *testing.T) {
 	r.Equal("https://ipfs.io/abc/", *o.BaseURI)
 	r.Nil(o.Meta)
 }
+
+func TestContentPageContentType(t *testing.T) {
+	mint := `{"p": "brc-721", "op": "mint", "tick": "café"}`
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().String(mint)
+	require.NoError(t, err)
+	latin1, err := charmap.ISO8859_1.NewEncoder().String(mint)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		maxLength   uint64
+		body        string
+		accepted    bool
+		typ         string
+	}{
+		{"utf-8", "text/plain;charset=utf-8", 0, mint, true, parser.NameBRC721Mint},
+		{"utf-8 bom", "text/plain;charset=utf-8", 0, "\xef\xbb\xbf" + mint, true, parser.NameBRC721Mint},
+		{"utf-16", "text/plain; charset=UTF-16LE", 0, utf16, true, parser.NameBRC721Mint},
+		{"latin1", "text/plain;charset=iso-8859-1", 0, latin1, true, parser.NameBRC721Mint},
+		{"json", "application/json", 0, mint, true, parser.NameBRC721Mint},
+		{"json ignores charset", "application/json;charset=iso-8859-1", 0, mint, true, parser.NameBRC721Mint},
+		{"unknown content type", "", 0, mint, true, parser.NameBRC721Mint},
+		{"malformed content type", "Text/Plain;;", 0, mint, true, parser.NameBRC721Mint},
+		{"unknown charset", "text/plain;charset=unknown", 0, mint, true, ContentTypeRaw},
+		{"text", "text/plain;charset=utf-8", 0, "hello", true, ContentTypeRaw},
+		{"image", "image/png", 0, mint, false, ContentTypeSkipped},
+		{"html", "text/html;charset=utf-8", 0, mint, false, ContentTypeSkipped},
+		{"too large", "text/plain;charset=utf-8", 16, mint, false, ContentTypeSkipped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			page := NewContentPage("uid", tt.contentType, tt.maxLength, parser.BRC721Parsers())
+			r.Equal(tt.accepted, page.Accepted(uint64(len(tt.body))))
+			// the length of the content is unknown until it is read.
+			data, err := page.Parse(strings.NewReader(tt.body))
+			r.NoError(err)
+			content := data.(*Content)
+			r.Equal(tt.typ, content.Type)
+			if tt.typ == parser.NameBRC721Mint {
+				r.Equal("café", content.Data.(*parser.BRC721Mint).Tick)
+			} else {
+				r.Nil(content.Data)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
 )
 
 type Inscription struct {
@@ -25,6 +27,15 @@ type Inscription struct {
 	Location      string    `json:"location,omitempty"`
 	Output        string    `json:"output,omitempty"`
 	Offset        uint64    `json:"offset,omitempty"`
+	// TxIndex is the index of the genesis tx in its block, it is resolved by the
+	// syncer from the block page.
+	TxIndex uint64 `json:"tx_index,omitempty"`
+	// Sat is the sat the inscription is on, nil if the inscription is unbound.
+	Sat       *uint64 `json:"sat,omitempty"`
+	SatRarity string  `json:"sat_rarity,omitempty"`
+	Cursed    bool    `json:"cursed,omitempty"`
+	// ParentUID is the uid of the parent inscription, empty if the inscription has no parent.
+	ParentUID string `json:"parent_uid,omitempty"`
 }
 
 type InscriptionPage struct {
@@ -52,12 +63,16 @@ func (p *InscriptionPage) Parse(r io.Reader) (interface{}, error) {
 	}
 
 	inscription := new(Inscription)
+	// the title is the number of the inscription, eg: "Inscription 0", "Inscription -5"
+	// or "Inscription 12 (unstable)" for the numbers that may change.
 	inscriptionIDText := doc.Find("h1").First().Text()
-	inscriptionIDText = strings.Replace(inscriptionIDText, "Inscription ", "", -1)
-	// convert inscriptionID string to int64
-	inscriptionID, err := strconv.ParseInt(inscriptionIDText, 10, 64)
+	fields := strings.Fields(inscriptionIDText)
+	if len(fields) < 2 || fields[0] != "Inscription" {
+		return nil, fmt.Errorf("invalid inscription title %q for uid %s", inscriptionIDText, p.UID)
+	}
+	inscriptionID, err := strconv.ParseInt(fields[1], 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert inscriptionID %s to int64 for uid %s: %v", inscriptionIDText, p.UID, err)
+		return nil, fmt.Errorf("failed to convert inscriptionID %s to int64 for uid %s: %v", fields[1], p.UID, err)
 	}
 	inscription.ID = inscriptionID
 
@@ -105,7 +120,35 @@ func (p *InscriptionPage) Parse(r io.Reader) (interface{}, error) {
 		case "offset":
 			v, _ := strconv.ParseUint(value, 10, 64)
 			inscription.Offset = v
+		case "sat":
+			if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+				inscription.Sat = &v
+				inscription.SatRarity = biz.SatRarity(v)
+			}
+		case "parent", "parents":
+			// the parents are the links to their inscription pages, only the first
+			// parent is kept.
+			if inscription.ParentUID == "" {
+				href, _ := dd.Find("a[href^='/inscription/']").First().Attr("href")
+				inscription.ParentUID = strings.TrimPrefix(href, "/inscription/")
+			}
+		case "charms":
+			// the charms are the emojis titled by their names, eg: <span title=cursed>👹</span>
+			dd.Find("span").Each(func(_ int, span *goquery.Selection) {
+				if title, _ := span.Attr("title"); title == "cursed" {
+					inscription.Cursed = true
+				}
+			})
 		}
 	})
+	// the cursed inscriptions are numbered negatively.
+	if inscription.ID < 0 {
+		inscription.Cursed = true
+	}
 	return inscription, nil
 }
+
+// Position returns the position of the inscription on the chain.
+func (i *Inscription) Position() (biz.InscriptionPosition, error) {
+	return biz.NewInscriptionPosition(i.GenesisHeight, i.TxIndex, i.UID)
+}
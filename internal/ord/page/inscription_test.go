@@ -1,10 +1,14 @@
 package page
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/conf"
 )
 
@@ -93,7 +97,7 @@ func TestInscriptionPage(t *testing.T) {
 	mockHTTPResult("http://localhost:8080/inscription/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", homePageBody)
 
 	inscriptionPage := NewInscriptionPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0")
-	data, err := parser.Parse(inscriptionPage)
+	data, err := parser.Parse(context.Background(), inscriptionPage)
 
 	r := require.New(t)
 	r.Nil(err)
@@ -113,3 +117,80 @@ func TestInscriptionPage(t *testing.T) {
 	r.Equal("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0", inscription.Output)
 	r.Equal(uint64(0), inscription.Offset)
 }
+
+func TestInscriptionPageSat(t *testing.T) {
+	r := require.New(t)
+	page := func(number, sat, charms string) string {
+		return `<h1>Inscription ` + number + `</h1>
+	<dl>
+	  <dt>id</dt>
+	  <dd class=monospace>347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0</dd>
+	  <dt>charms</dt>
+	  <dd>` + charms + `</dd>
+	  <dt>sat</dt>
+	  <dd><a href=/sat/` + sat + `>` + sat + `</a></dd>
+	</dl>`
+	}
+	for _, tc := range []struct {
+		number, sat, charms string
+		rarity              string
+		cursed              bool
+	}{
+		{"1", "0", "", biz.SatRarityMythic, false},
+		{"2", "1", "", biz.SatRarityCommon, false},
+		{"3", "5000000000", "", biz.SatRarityUncommon, false},
+		{"4", "10080000000000", "", biz.SatRarityRare, false},
+		{"5", "1050000000000000", "", biz.SatRarityEpic, false},
+		{"6", "2067187500000000", "", biz.SatRarityLegendary, false},
+		{"7", "2099999997690000", "", biz.SatRarityCommon, false},
+		{"8", "1", "<span title=cursed>👹</span><span title=reinscription>♻️</span>", biz.SatRarityCommon, true},
+		{"-9", "5000000001", "", biz.SatRarityCommon, true},
+	} {
+		data, err := NewInscriptionPage("uid").Parse(strings.NewReader(page(tc.number, tc.sat, tc.charms)))
+		r.NoError(err)
+		inscription := data.(*Inscription)
+		r.NotNil(inscription.Sat, tc.number)
+		r.Equal(tc.sat, strconv.FormatUint(*inscription.Sat, 10))
+		r.Equal(tc.rarity, inscription.SatRarity, tc.number)
+		r.Equal(tc.cursed, inscription.Cursed, tc.number)
+	}
+
+	// the unbound inscriptions are on no sat.
+	data, err := NewInscriptionPage("uid").Parse(strings.NewReader(`<h1>Inscription 10</h1><dl><dt>sat</dt><dd></dd></dl>`))
+	r.NoError(err)
+	r.Nil(data.(*Inscription).Sat)
+	r.Equal("", data.(*Inscription).SatRarity)
+}
+
+func TestInscriptionPageParent(t *testing.T) {
+	r := require.New(t)
+	for key, expected := range map[string]string{"parent": "e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0", "children": ""} {
+		data, err := NewInscriptionPage("uid").Parse(strings.NewReader(`<h1>Inscription 10</h1>
+	<dl>
+	  <dt>` + key + `</dt>
+	  <dd><div class=thumbnails><a href=/inscription/e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0><iframe sandbox=allow-scripts scrolling=no loading=lazy src=/preview/e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0></iframe></a></div></dd>
+	</dl>`))
+		r.NoError(err)
+		r.Equal(expected, data.(*Inscription).ParentUID, key)
+	}
+}
+
+func TestInscriptionPageNumber(t *testing.T) {
+	r := require.New(t)
+	for title, expected := range map[string]int64{
+		"Inscription 0":             0,
+		"Inscription 4984402":       4984402,
+		"Inscription -5":            -5,
+		"Inscription 12 (unstable)": 12,
+	} {
+		data, err := NewInscriptionPage("uid").Parse(strings.NewReader(`<h1>` + title + `</h1><dl></dl>`))
+		r.NoError(err, title)
+		inscription := data.(*Inscription)
+		r.Equal(expected, inscription.ID, title)
+		r.Equal(expected < 0, inscription.Cursed, title)
+	}
+	for _, title := range []string{"", "Inscription", "Block 0", "Inscription x"} {
+		_, err := NewInscriptionPage("uid").Parse(strings.NewReader(`<h1>` + title + `</h1>`))
+		r.Error(err, title)
+	}
+}
@@ -1,6 +1,7 @@
 package page
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -164,7 +165,7 @@ func TestInscriptionsHomePage(t *testing.T) {
 	mockHTTPResult("http://localhost:8080/inscriptions", homePageBody)
 
 	inscriptionsPage := NewInscriptionsPage()
-	data, err := parser.Parse(inscriptionsPage)
+	data, err := parser.Parse(context.Background(), inscriptionsPage)
 
 	r := require.New(t)
 	r.Nil(err)
@@ -334,7 +335,7 @@ func TestInscriptionsPage(t *testing.T) {
 	mockHTTPResult("http://localhost:8080/inscriptions/10400370", pageBody)
 
 	inscriptionsPage := NewInscriptionsPage(10400370)
-	data, err := parser.Parse(inscriptionsPage)
+	data, err := parser.Parse(context.Background(), inscriptionsPage)
 
 	r := require.New(t)
 	r.Nil(err)
@@ -1,6 +1,7 @@
 package page
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/url"
@@ -11,38 +12,49 @@ import (
 	"github.com/adshao/ordinals-indexer/internal/conf"
 )
 
-var ProviderSet = wire.NewSet(NewPageParser)
+var ProviderSet = wire.NewSet(NewPageParser, NewContentSource)
 
 func NewPageParser(c *conf.Ord) PageParser {
 	return &pageParser{
-		httpGet: http.Get,
+		httpGet: httpGet,
 		c:       c,
 	}
 }
 
+// PageParser fetches the pages from the ord server and parses them, the requests
+// are aborted once the context is done.
 type PageParser interface {
-	Parse(Page) (interface{}, error)
+	Parse(context.Context, Page) (interface{}, error)
 }
 
 type pageParser struct {
-	httpGet func(string) (*http.Response, error)
+	httpGet func(context.Context, string) (*http.Response, error)
 	c       *conf.Ord
 }
 
-func (parser *pageParser) parsePageRaw(p Page) (io.Reader, error) {
+// httpGet gets the url with the default client, the request is bound to the context.
+func httpGet(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func (parser *pageParser) parsePageRaw(ctx context.Context, p Page) (io.Reader, error) {
 	u := p.URL()
 	if !strings.HasPrefix(u, "http") {
 		u, _ = url.JoinPath(parser.c.Server.Addr, u)
 	}
-	resp, err := parser.httpGet(u)
+	resp, err := parser.httpGet(ctx, u)
 	if err != nil {
 		return nil, err
 	}
 	return resp.Body, nil
 }
 
-func (parser *pageParser) Parse(p Page) (interface{}, error) {
-	r, err := parser.parsePageRaw(p)
+func (parser *pageParser) Parse(ctx context.Context, p Page) (interface{}, error) {
+	r, err := parser.parsePageRaw(ctx, p)
 	if err != nil {
 		return nil, err
 	}
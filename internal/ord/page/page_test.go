@@ -2,6 +2,7 @@ package page
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -11,7 +12,7 @@ var (
 	mockResults = make(map[string][]byte)
 )
 
-func mockHTTPGet(url string) (*http.Response, error) {
+func mockHTTPGet(ctx context.Context, url string) (*http.Response, error) {
 	body, ok := mockResults[url]
 	if !ok {
 		return nil, fmt.Errorf("url %s not found", url)
@@ -0,0 +1,55 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+// contentFetchTimeout bounds the request of a content to the ord server, including
+// reading its body.
+const contentFetchTimeout = 30 * time.Second
+
+// contentSource fetches the raw content of the inscriptions from the ord server.
+type contentSource struct {
+	client *http.Client
+	c      *conf.Ord
+}
+
+func NewContentSource(c *conf.Ord) biz.ContentSource {
+	return &contentSource{
+		client: &http.Client{Timeout: contentFetchTimeout},
+		c:      c,
+	}
+}
+
+func (s *contentSource) FetchContent(ctx context.Context, uid string) (string, io.ReadCloser, error) {
+	u, err := url.JoinPath(s.c.Server.Addr, "content", url.PathEscape(uid))
+	if err != nil {
+		return "", nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get("Content-Type"), resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return "", nil, nil
+	default:
+		resp.Body.Close()
+		return "", nil, fmt.Errorf("failed to fetch content of %s: %s", uid, resp.Status)
+	}
+}
@@ -0,0 +1,46 @@
+package page
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+func TestContentSource(t *testing.T) {
+	r := require.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/content/found":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("png"))
+		case "/content/failed":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+	source := NewContentSource(&conf.Ord{Server: &conf.Ord_Server{Addr: srv.URL}})
+	ctx := context.Background()
+
+	contentType, body, err := source.FetchContent(ctx, "found")
+	r.NoError(err)
+	defer body.Close()
+	r.Equal("image/png", contentType)
+	b, err := io.ReadAll(body)
+	r.NoError(err)
+	r.Equal("png", string(b))
+
+	_, body, err = source.FetchContent(ctx, "missing")
+	r.NoError(err)
+	r.Nil(body)
+
+	_, _, err = source.FetchContent(ctx, "failed")
+	r.EqualError(err, "failed to fetch content of failed: 500 Internal Server Error")
+}
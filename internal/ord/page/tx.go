@@ -0,0 +1,137 @@
+package page
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type Tx struct {
+	TxID string `json:"txid"`
+	// Inputs are the outpoints spent by the transaction in order, eg: "<txid>:<vout>".
+	Inputs  []string    `json:"inputs"`
+	Outputs []*TxOutput `json:"outputs"`
+}
+
+type TxOutput struct {
+	Outpoint string `json:"outpoint"`
+	Value    uint64 `json:"value"`
+	// Address is empty if the script pubkey has no address, eg: OP_RETURN.
+	Address string `json:"address,omitempty"`
+}
+
+type TxPage struct {
+	TxID string
+}
+
+var (
+	_ Page = (*TxPage)(nil)
+)
+
+func NewTxPage(txid string) *TxPage {
+	return &TxPage{
+		TxID: txid,
+	}
+}
+
+func (p *TxPage) URL() string {
+	return fmt.Sprintf("/tx/%s", p.TxID)
+}
+
+func (p *TxPage) Parse(r io.Reader) (interface{}, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Tx{
+		TxID:    strings.TrimSpace(doc.Find("h1 span").First().Text()),
+		Inputs:  make([]string, 0),
+		Outputs: make([]*TxOutput, 0),
+	}
+	if tx.TxID == "" {
+		return nil, fmt.Errorf("tx %s has no txid", p.TxID)
+	}
+	var outputErr error
+	doc.Find("h2").Each(func(_ int, h2 *goquery.Selection) {
+		ul := h2.NextAllFiltered("ul").First()
+		switch title := strings.ToLower(h2.Text()); {
+		case strings.HasSuffix(title, "input"), strings.HasSuffix(title, "inputs"):
+			ul.Find("li a[href^='/output/']").Each(func(_ int, a *goquery.Selection) {
+				href, _ := a.Attr("href")
+				tx.Inputs = append(tx.Inputs, strings.TrimPrefix(href, "/output/"))
+			})
+		case strings.HasSuffix(title, "output"), strings.HasSuffix(title, "outputs"):
+			ul.ChildrenFiltered("li").Each(func(_ int, li *goquery.Selection) {
+				href, _ := li.Find("a[href^='/output/']").First().Attr("href")
+				output := &TxOutput{Outpoint: strings.TrimPrefix(href, "/output/")}
+				if err := parseOutput(li.Find("dl").First(), output); err != nil && outputErr == nil {
+					outputErr = err
+				}
+				tx.Outputs = append(tx.Outputs, output)
+			})
+		}
+	})
+	if outputErr != nil {
+		return nil, fmt.Errorf("tx %s: %v", tx.TxID, outputErr)
+	}
+	return tx, nil
+}
+
+// OutputPage is an output of a transaction, spent or not.
+type OutputPage struct {
+	Outpoint string
+}
+
+var (
+	_ Page = (*OutputPage)(nil)
+)
+
+func NewOutputPage(outpoint string) *OutputPage {
+	return &OutputPage{
+		Outpoint: outpoint,
+	}
+}
+
+func (p *OutputPage) URL() string {
+	return fmt.Sprintf("/output/%s", p.Outpoint)
+}
+
+func (p *OutputPage) Parse(r io.Reader) (interface{}, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &TxOutput{Outpoint: strings.TrimSpace(doc.Find("h1 span").First().Text())}
+	if output.Outpoint == "" {
+		return nil, fmt.Errorf("output %s has no outpoint", p.Outpoint)
+	}
+	if err := parseOutput(doc.Find("dl").First(), output); err != nil {
+		return nil, fmt.Errorf("output %s: %v", output.Outpoint, err)
+	}
+	return output, nil
+}
+
+// parseOutput parses the value and the address of the output from the dt and dd pairs of the dl.
+func parseOutput(dl *goquery.Selection, output *TxOutput) error {
+	var err error
+	dl.ChildrenFiltered("dt").Each(func(_ int, dt *goquery.Selection) {
+		value := strings.TrimSpace(dt.NextFiltered("dd").Text())
+		switch strings.ToLower(strings.TrimSpace(dt.Text())) {
+		case "value":
+			v, e := strconv.ParseUint(value, 10, 64)
+			if e != nil {
+				err = fmt.Errorf("failed to convert value %s to uint64: %v", value, e)
+				return
+			}
+			output.Value = v
+		case "address":
+			output.Address = value
+		}
+	})
+	return err
+}
@@ -0,0 +1,94 @@
+package page
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxPage(t *testing.T) {
+	r := require.New(t)
+	body := `<!doctype html>
+<html lang=en>
+  <body>
+  <main>
+<h1>Transaction <span class=monospace>b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1</span></h1>
+<h2>2 Inputs</h2>
+<ul>
+  <li><a class=monospace href=/output/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0>347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0</a></li>
+  <li><a class=monospace href=/output/9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063:1>9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063:1</a></li>
+</ul>
+<h2>2 Outputs</h2>
+<ul class=monospace>
+  <li>
+    <a href=/output/b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1:0 class=monospace>
+      b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1:0
+    </a>
+    <dl>
+      <dt>value</dt><dd>546</dd>
+      <dt>script pubkey</dt><dd class=monospace>OP_PUSHNUM_1 OP_PUSHBYTES_32 5a1b</dd>
+      <dt>address</dt><dd class=monospace>bc1p5d7rjq7g6rdk2yhzks9smlaqtedr4dekq08ge8ztwac72sfr9rusxg3297</dd>
+    </dl>
+  </li>
+  <li>
+    <a href=/output/b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1:1 class=monospace>
+      b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1:1
+    </a>
+    <dl>
+      <dt>value</dt><dd>0</dd>
+      <dt>script pubkey</dt><dd class=monospace>OP_RETURN</dd>
+    </dl>
+  </li>
+</ul>
+  </main>
+  </body>
+</html>`
+	p := NewTxPage("b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1")
+	r.Equal("/tx/b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1", p.URL())
+	data, err := p.Parse(strings.NewReader(body))
+	r.NoError(err)
+	tx := data.(*Tx)
+	r.Equal("b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1", tx.TxID)
+	r.Equal([]string{
+		"347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0",
+		"9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063:1",
+	}, tx.Inputs)
+	r.Equal([]*TxOutput{
+		{
+			Outpoint: "b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1:0",
+			Value:    546,
+			Address:  "bc1p5d7rjq7g6rdk2yhzks9smlaqtedr4dekq08ge8ztwac72sfr9rusxg3297",
+		},
+		{
+			Outpoint: "b0d4bcc2a7f1d1f2bdc4b6c1d6b9f0d2f6b0a1c4e8c7f3b2a9d5e6f7a8b9c0d1:1",
+		},
+	}, tx.Outputs)
+
+	// the pages without the txid are invalid.
+	_, err = p.Parse(strings.NewReader(`<h1>Transaction</h1>`))
+	r.Error(err)
+}
+
+func TestOutputPage(t *testing.T) {
+	r := require.New(t)
+	body := `<h1>Output <span class=monospace>347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0</span></h1>
+<dl>
+  <dt>value</dt><dd>10000</dd>
+  <dt>script pubkey</dt><dd class=monospace>OP_PUSHNUM_1 OP_PUSHBYTES_32 5a1b</dd>
+  <dt>address</dt><dd class=monospace>bc1pvvd9mvj6kx6ayd39xxc0l5qwyt2hl9mlmtt6mncz7sahxxhqnfmqql8zv6</dd>
+  <dt>transaction</dt><dd><a class=monospace href=/tx/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564>347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564</a></dd>
+</dl>`
+	p := NewOutputPage("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0")
+	r.Equal("/output/347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0", p.URL())
+	data, err := p.Parse(strings.NewReader(body))
+	r.NoError(err)
+	r.Equal(&TxOutput{
+		Outpoint: "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564:0",
+		Value:    10000,
+		Address:  "bc1pvvd9mvj6kx6ayd39xxc0l5qwyt2hl9mlmtt6mncz7sahxxhqnfmqql8zv6",
+	}, data)
+
+	_, err = p.Parse(strings.NewReader(`<h1>Output <span class=monospace>x:0</span></h1><dl><dt>value</dt><dd>x</dd></dl>`))
+	r.Error(err)
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"fmt"
+
 	"github.com/adshao/go-brc721/sig"
 	jsoniter "github.com/json-iterator/go"
 )
@@ -22,11 +24,51 @@ var (
 	_ Parser = (*BRC721MintParser)(nil)
 	_ Parser = (*BRC721UpdateParser)(nil)
 
+	_ ContentFilter = (*BRC721DeployParser)(nil)
+	_ ContentFilter = (*BRC721MintParser)(nil)
+	_ ContentFilter = (*BRC721UpdateParser)(nil)
+
 	_ Validator = (*BRC721Deploy)(nil)
 	_ Validator = (*BRC721Mint)(nil)
 	_ Validator = (*BRC721Update)(nil)
 )
 
+// brc721Ops are the ops of the BRC-721 protocol.
+var brc721Ops = map[string]bool{
+	"deploy": true,
+	"mint":   true,
+	"update": true,
+}
+
+// jsonContent accepts the JSON content inscribed as text or json.
+type jsonContent struct {
+	// strict rejects the content that breaks the JSON or protocol spec, which
+	// is accepted by the lenient decoder, eg: duplicate keys or trailing data.
+	strict bool
+}
+
+func (jsonContent) Accept(mediaType string) bool {
+	return mediaType == "text/plain" || mediaType == "application/json"
+}
+
+func (c jsonContent) unmarshal(data []byte, v interface{}) error {
+	if !c.strict {
+		return json.Unmarshal(data, v)
+	}
+	if err := checkStrictJSON(data); err != nil {
+		return err
+	}
+	return strictJSON.Unmarshal(data, v)
+}
+
+// checkOp checks the op of the BRC-721 content in strict mode.
+func (c jsonContent) checkOp(p, op string) error {
+	if c.strict && p == BRC721 && !brc721Ops[op] {
+		return fmt.Errorf("unknown op %q", op)
+	}
+	return nil
+}
+
 type BRC721Meta struct {
 	Name        string                   `json:"name"`
 	Description string                   `json:"description"`
@@ -42,6 +84,8 @@ type BRC721Deploy struct {
 	BaseURI *string        `json:"buri"`
 	Meta    *BRC721Meta    `json:"meta"`
 	Sig     *sig.DeploySig `json:"sig"`
+	// Child accepts only the mints inscribed as the children of the deploy inscription.
+	Child bool `json:"child"`
 }
 
 func (m BRC721Deploy) Validate() bool {
@@ -64,6 +108,7 @@ func (m BRC721Deploy) Validate() bool {
 }
 
 type BRC721DeployParser struct {
+	jsonContent
 }
 
 func (p *BRC721DeployParser) Name() string {
@@ -72,10 +117,16 @@ func (p *BRC721DeployParser) Name() string {
 
 func (p *BRC721DeployParser) Parse(data []byte) (interface{}, bool, error) {
 	var deploy BRC721Deploy
-	err := json.Unmarshal(data, &deploy)
+	err := p.unmarshal(data, &deploy)
 	if err != nil {
 		return nil, false, err
 	}
+	if err := p.checkOp(deploy.P, deploy.Op); err != nil {
+		return nil, false, err
+	}
+	if p.strict && !isCanonicalUint(deploy.Max) {
+		return &deploy, false, nil
+	}
 	return &deploy, deploy.Validate(), nil
 }
 
@@ -100,6 +151,7 @@ func (m BRC721Mint) Validate() bool {
 }
 
 type BRC721MintParser struct {
+	jsonContent
 }
 
 func (p *BRC721MintParser) Name() string {
@@ -108,9 +160,13 @@ func (p *BRC721MintParser) Name() string {
 
 func (p *BRC721MintParser) Parse(data []byte) (interface{}, bool, error) {
 	var mint BRC721Mint
-	err := json.Unmarshal(data, &mint)
+	// the numbers of the signed fields are decoded from JSON numbers only, the strings
+	// are rejected in both modes, see testdata/brc721_conformance.json.
+	err := p.unmarshal(data, &mint)
 	if err != nil {
-		// TODO: try protojson.Unmarshal, this will fix the issue of unmarshaling string to int64
+		return nil, false, err
+	}
+	if err := p.checkOp(mint.P, mint.Op); err != nil {
 		return nil, false, err
 	}
 	return &mint, mint.Validate(), nil
@@ -141,6 +197,7 @@ func (m BRC721Update) Validate() bool {
 }
 
 type BRC721UpdateParser struct {
+	jsonContent
 }
 
 func (p *BRC721UpdateParser) Name() string {
@@ -149,15 +206,33 @@ func (p *BRC721UpdateParser) Name() string {
 
 func (p *BRC721UpdateParser) Parse(data []byte) (interface{}, bool, error) {
 	var update BRC721Update
-	err := json.Unmarshal(data, &update)
+	err := p.unmarshal(data, &update)
 	if err != nil {
 		return nil, false, err
 	}
+	if err := p.checkOp(update.P, update.Op); err != nil {
+		return nil, false, err
+	}
 	return &update, update.Validate(), nil
 }
 
-func init() {
-	registerParser(&BRC721DeployParser{})
-	registerParser(&BRC721MintParser{})
-	registerParser(&BRC721UpdateParser{})
+// BRC721Parsers returns the parsers of the BRC-721 inscriptions.
+func BRC721Parsers() []Parser {
+	return []Parser{
+		&BRC721DeployParser{},
+		&BRC721MintParser{},
+		&BRC721UpdateParser{},
+	}
+}
+
+// BRC721StrictParsers returns the parsers of the BRC-721 inscriptions in strict mode,
+// which reject duplicate keys, mismatched case of keys, unknown ops, non-canonical
+// numbers in max and the data around the JSON object.
+func BRC721StrictParsers() []Parser {
+	c := jsonContent{strict: true}
+	return []Parser{
+		&BRC721DeployParser{jsonContent: c},
+		&BRC721MintParser{jsonContent: c},
+		&BRC721UpdateParser{jsonContent: c},
+	}
 }
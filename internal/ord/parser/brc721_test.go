@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// conformanceCase is the expected type of the content parsed in the lenient and
+// strict modes, the empty type means the content is rejected by all the parsers.
+type conformanceCase struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	Lenient string `json:"lenient"`
+	Strict  string `json:"strict"`
+}
+
+func parseType(parsers []Parser, content []byte) string {
+	for _, p := range parsers {
+		_, valid, err := p.Parse(content)
+		if err == nil && valid {
+			return p.Name()
+		}
+	}
+	return ""
+}
+
+func TestBRC721Conformance(t *testing.T) {
+	data, err := os.ReadFile("testdata/brc721_conformance.json")
+	require.NoError(t, err)
+	var cases []conformanceCase
+	require.NoError(t, json.Unmarshal(data, &cases))
+	require.NotEmpty(t, cases)
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(c.Lenient, parseType(BRC721Parsers(), []byte(c.Content)), "lenient")
+			r.Equal(c.Strict, parseType(BRC721StrictParsers(), []byte(c.Content)), "strict")
+		})
+	}
+}
+
+func TestIsCanonicalUint(t *testing.T) {
+	r := require.New(t)
+	r.True(isCanonicalUint("1"))
+	r.True(isCanonicalUint("18446744073709551615"))
+	r.False(isCanonicalUint(""))
+	r.False(isCanonicalUint("0"))
+	r.False(isCanonicalUint("01"))
+	r.False(isCanonicalUint("1 "))
+	r.False(isCanonicalUint("-1"))
+	r.False(isCanonicalUint("18446744073709551616"))
+}
@@ -1,14 +1,7 @@
 package parser
 
-import (
-	"sync"
-)
-
-var (
-	lock    sync.Mutex
-	parsers = make([]Parser, 0)
-)
-
+// Parser parses the inscription content, it returns false if the content
+// is parsed but invalid by the protocol rules.
 type Parser interface {
 	Parse(content []byte) (interface{}, bool, error)
 	Name() string
@@ -18,12 +11,9 @@ type Validator interface {
 	Validate() bool
 }
 
-func ParserList() []Parser {
-	return parsers
-}
-
-func registerParser(parser Parser) {
-	lock.Lock()
-	parsers = append(parsers, parser)
-	lock.Unlock()
+// ContentFilter is optionally implemented by the parsers to skip the content
+// they can not parse before it is fetched, the parsers without it accept any content.
+type ContentFilter interface {
+	// Accept returns true if the content of the media type, eg: application/json, can be parsed.
+	Accept(mediaType string) bool
 }
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// strictJSON decodes the field names case sensitively.
+var strictJSON = jsoniter.Config{
+	EscapeHTML:             true,
+	SortMapKeys:            true,
+	ValidateJsonRawMessage: true,
+	CaseSensitive:          true,
+}.Froze()
+
+var canonicalUint = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+// checkStrictJSON checks the content is a single JSON object without duplicate
+// keys, only the JSON whitespace is allowed around the object.
+func checkStrictJSON(data []byte) error {
+	dec := stdjson.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != stdjson.Delim('{') {
+		return errors.New("content is not a JSON object")
+	}
+	if err := checkStrictObject(dec); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("trailing data after the JSON object")
+	}
+	return nil
+}
+
+func checkStrictObject(dec *stdjson.Decoder) error {
+	keys := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		if keys[key] {
+			return fmt.Errorf("duplicate key %q", key)
+		}
+		keys[key] = true
+		if err := checkStrictValue(dec); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func checkStrictValue(dec *stdjson.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case stdjson.Delim('{'):
+		return checkStrictObject(dec)
+	case stdjson.Delim('['):
+		for dec.More() {
+			if err := checkStrictValue(dec); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token()
+		return err
+	}
+	return nil
+}
+
+// isCanonicalUint returns true if s is a positive decimal integer of uint64
+// without sign, leading zeros or spaces.
+func isCanonicalUint(s string) bool {
+	if !canonicalUint.MatchString(s) {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,102 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+// maxCachedBlocks is the max number of the blocks to cache the tx indexes of.
+const maxCachedBlocks = 16
+
+// sortResults resolves the tx indexes of the inscriptions, and sorts the results in
+// the order of their positions on the chain. The inscriptions are at their genesis
+// locations in the blocks mode.
+func (s *Syncer) sortResults(ctx context.Context, results []*result) error {
+	for _, result := range results {
+		if err := s.resolveTxIndex(ctx, result.info); err != nil {
+			return err
+		}
+		if s.syncMode != SyncModeBlocks {
+			continue
+		}
+		if err := s.resolveGenesisLocation(ctx, result.info); err != nil {
+			return err
+		}
+	}
+	var err error
+	sort.SliceStable(results, func(i, j int) bool {
+		pi, e := results[i].info.Position()
+		if e != nil {
+			err = e
+			return false
+		}
+		pj, e := results[j].info.Position()
+		if e != nil {
+			err = e
+			return false
+		}
+		return pi.Less(pj)
+	})
+	return err
+}
+
+// resolveTxIndex sets the index of the genesis tx of the inscription in its block.
+func (s *Syncer) resolveTxIndex(ctx context.Context, info *page.Inscription) error {
+	txIndexes, err := s.blockTxIndexes(ctx, info.GenesisHeight)
+	if err != nil {
+		return err
+	}
+	txIndex, ok := txIndexes[info.GenesisTx]
+	if !ok {
+		return fmt.Errorf("genesis tx %s of inscription %d is not in block %d", info.GenesisTx, info.ID, info.GenesisHeight)
+	}
+	info.TxIndex = txIndex
+	return nil
+}
+
+// blockTxIndexes returns the indexes of the txs of the block by txid, the blocks
+// are fetched from ord once and cached.
+func (s *Syncer) blockTxIndexes(ctx context.Context, height uint64) (map[string]uint64, error) {
+	s.blocksLock.Lock()
+	txIndexes, ok := s.blocks[height]
+	s.blocksLock.Unlock()
+	if ok {
+		return txIndexes, nil
+	}
+	blockPage := page.NewBlockPage(height)
+	s.logger.Debugf("fetching %s...", blockPage.URL())
+	data, err := s.pageParser.Parse(ctx, blockPage)
+	if err != nil {
+		return nil, err
+	}
+	block, ok := data.(*page.Block)
+	if !ok {
+		return nil, fmt.Errorf("invalid block page: %T", data)
+	}
+	return s.cacheBlock(block), nil
+}
+
+// cacheBlock caches the tx indexes of the block, and returns them.
+func (s *Syncer) cacheBlock(block *page.Block) map[string]uint64 {
+	txIndexes := make(map[string]uint64, len(block.TxIDs))
+	for i, txid := range block.TxIDs {
+		txIndexes[txid] = uint64(i)
+	}
+	s.blocksLock.Lock()
+	defer s.blocksLock.Unlock()
+	if len(s.blocks) >= maxCachedBlocks {
+		s.blocks = make(map[uint64]map[string]uint64)
+	}
+	s.blocks[block.Height] = txIndexes
+	return txIndexes
+}
+
+// resetBlocks drops the cached blocks, eg: after a reorg.
+func (s *Syncer) resetBlocks() {
+	s.blocksLock.Lock()
+	defer s.blocksLock.Unlock()
+	s.blocks = make(map[uint64]map[string]uint64)
+}
@@ -0,0 +1,163 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+	"github.com/adshao/ordinals-indexer/internal/ord/parser"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ProtocolHandler indexes the inscriptions of a protocol.
+type ProtocolHandler interface {
+	// Name is the name of the protocol, eg: brc-721.
+	Name() string
+	// Parsers parses and validates the inscription content of the protocol.
+	// The content is dispatched back to the handler with the parser name as its type.
+	Parsers() []parser.Parser
+	// Process applies the state transition of the inscription to the repos.
+	// The inscriptions breaking the protocol rules are ignored without error.
+	Process(ctx context.Context, info *page.Inscription) error
+	// Rollback reverts the state of the inscriptions from inscriptionID on.
+	Rollback(ctx context.Context, inscriptionID int64) error
+	// RollbackBlocks reverts the state of the inscriptions from the block height on,
+	// the cursed ones included, eg: after a reorg.
+	RollbackBlocks(ctx context.Context, height uint64) error
+}
+
+// ReprocessChecker is implemented by the protocol handlers to tell if an inscription
+// can be processed again without breaking the order of the indexed inscriptions.
+type ReprocessChecker interface {
+	// CheckReprocess returns a non-empty reason to skip re-processing the inscription.
+	CheckReprocess(ctx context.Context, info *page.Inscription) (string, error)
+}
+
+// HandlerContext holds the dependencies of the protocol handlers.
+type HandlerContext struct {
+	Conf          *conf.Ord
+	CollectionUc  *biz.CollectionUsecase
+	InscriptionUc *biz.InscriptionUsecase
+	TokenUc       *biz.TokenUsecase
+	TraitUc       *biz.TraitUsecase
+	Logger        *log.Helper
+}
+
+// HandlerFactory creates a protocol handler for the syncer.
+type HandlerFactory func(*HandlerContext) ProtocolHandler
+
+var (
+	protocolsLock sync.Mutex
+	protocols     = make(map[string]HandlerFactory)
+)
+
+// RegisterProtocol registers the protocol handler by name, the in-house protocols
+// register their handlers in the init function of their packages.
+func RegisterProtocol(name string, factory HandlerFactory) {
+	protocolsLock.Lock()
+	defer protocolsLock.Unlock()
+	if _, ok := protocols[name]; ok {
+		panic(fmt.Sprintf("protocol %s is already registered", name))
+	}
+	protocols[name] = factory
+}
+
+// Protocols returns the names of the registered protocols in order.
+func Protocols() []string {
+	protocolsLock.Lock()
+	defer protocolsLock.Unlock()
+	names := make([]string, 0, len(protocols))
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newHandlers creates the handlers of the registered protocols.
+func newHandlers(hc *HandlerContext) ([]ProtocolHandler, error) {
+	handlers := make([]ProtocolHandler, 0)
+	contentTypes := make(map[string]string)
+	for _, name := range Protocols() {
+		protocolsLock.Lock()
+		factory := protocols[name]
+		protocolsLock.Unlock()
+		handler := factory(hc)
+		for _, p := range handler.Parsers() {
+			if other, ok := contentTypes[p.Name()]; ok {
+				return nil, fmt.Errorf("content type %s is handled by both protocols %s and %s", p.Name(), other, name)
+			}
+			contentTypes[p.Name()] = name
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+// parsers returns the content parsers of all the handlers.
+func (s *Syncer) parsers() []parser.Parser {
+	parsers := make([]parser.Parser, 0)
+	for _, handler := range s.handlers {
+		parsers = append(parsers, handler.Parsers()...)
+	}
+	return parsers
+}
+
+// handler returns the handler of the content type, or nil if no protocol handles it.
+func (s *Syncer) handler(contentType string) ProtocolHandler {
+	for _, handler := range s.handlers {
+		for _, p := range handler.Parsers() {
+			if p.Name() == contentType {
+				return handler
+			}
+		}
+	}
+	return nil
+}
+
+// protocol returns the handler of the protocol by name.
+func (s *Syncer) protocol(name string) ProtocolHandler {
+	for _, handler := range s.handlers {
+		if handler.Name() == name {
+			return handler
+		}
+	}
+	return nil
+}
+
+// Rollback reverts the state of the inscriptions from inscriptionID on for all
+// the protocols, deletes the indexed inscriptions, and rewinds the sync checkpoint
+// to inscriptionID, and the block checkpoint to the block before the inscription.
+func (s *Syncer) Rollback(ctx context.Context, inscriptionID int64) error {
+	// the state of the inscriptions is rolled back together in a transaction.
+	return s.data.InTx(biz.NewNoCacheContext(ctx), func(ctx context.Context) error {
+		ins, err := s.inscriptionUc.FindByInscriptionID(ctx, inscriptionID)
+		if err != nil {
+			return err
+		}
+		for i := len(s.handlers) - 1; i >= 0; i-- {
+			handler := s.handlers[i]
+			if err := handler.Rollback(ctx, inscriptionID); err != nil {
+				return fmt.Errorf("failed to rollback protocol %s: %w", handler.Name(), err)
+			}
+			s.logger.Infof("rolled back protocol %s from inscription %d", handler.Name(), inscriptionID)
+		}
+		count, err := s.inscriptionUc.DeleteInscriptionsFrom(ctx, inscriptionID)
+		if err != nil {
+			return fmt.Errorf("failed to delete inscriptions: %w", err)
+		}
+		s.logger.Infof("deleted %d inscriptions from inscription %d", count, inscriptionID)
+		if err := s.rewindLastInscriptionId(ctx, inscriptionID); err != nil {
+			return err
+		}
+		if ins == nil {
+			return nil
+		}
+		return s.rewindBlockCheckpoints(ctx, ins.GenesisHeight)
+	})
+}
@@ -0,0 +1,195 @@
+package ord
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/data"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+	"github.com/adshao/ordinals-indexer/internal/ord/parser"
+)
+
+type testParser struct {
+	name string
+}
+
+func (p *testParser) Name() string {
+	return p.name
+}
+
+func (p *testParser) Parse(data []byte) (interface{}, bool, error) {
+	s := string(data)
+	return s, strings.HasPrefix(s, p.name), nil
+}
+
+type testHandler struct {
+	name      string
+	parsers   []parser.Parser
+	processed []int64
+}
+
+func (h *testHandler) Name() string {
+	return h.name
+}
+
+func (h *testHandler) Parsers() []parser.Parser {
+	return h.parsers
+}
+
+func (h *testHandler) Process(ctx context.Context, info *page.Inscription) error {
+	h.processed = append(h.processed, info.ID)
+	return nil
+}
+
+func (h *testHandler) Rollback(ctx context.Context, inscriptionID int64) error {
+	return nil
+}
+
+func (h *testHandler) RollbackBlocks(ctx context.Context, height uint64) error {
+	return nil
+}
+
+// withProtocols registers the protocols for the test only.
+func withProtocols(t *testing.T, factories map[string]HandlerFactory) {
+	protocolsLock.Lock()
+	registered := protocols
+	protocols = make(map[string]HandlerFactory)
+	for name, factory := range registered {
+		protocols[name] = factory
+	}
+	protocolsLock.Unlock()
+	t.Cleanup(func() {
+		protocolsLock.Lock()
+		protocols = registered
+		protocolsLock.Unlock()
+	})
+	for name, factory := range factories {
+		RegisterProtocol(name, factory)
+	}
+}
+
+func TestProtocolHandlers(t *testing.T) {
+	r := require.New(t)
+	handler := &testHandler{name: "test", parsers: []parser.Parser{&testParser{name: "test-op"}}}
+	withProtocols(t, map[string]HandlerFactory{
+		"test": func(*HandlerContext) ProtocolHandler { return handler },
+	})
+	r.Equal([]string{parser.BRC721, "test"}, Protocols())
+	r.Panics(func() { RegisterProtocol("test", nil) })
+
+	c := &conf.Ord{Worker: &conf.Ord_Worker{Concurrency: 1}, Server: &conf.Ord_Server{}}
+	d, cleanup := data.NewTData(t)
+	defer cleanup()
+	inscriptionUc := biz.NewInscriptionUsecase(data.NewInscriptionRepo(d, log.GetLogger()), log.GetLogger())
+	syncer, _, err := NewSyncer(c, d, nil, inscriptionUc, nil, nil, nil, nil, nil, log.GetLogger())
+	r.NoError(err)
+	r.Len(syncer.parsers(), 4)
+
+	// the content is parsed by the parsers of all the protocols.
+	content, err := page.NewContentPage("uid", "text/plain;charset=utf-8", 0, syncer.parsers()).Parse(strings.NewReader("test-op 1"))
+	r.NoError(err)
+	r.Equal("test-op", content.(*page.Content).Type)
+
+	r.NoError(syncer.processResult(context.Background(), &result{info: &page.Inscription{ID: 1, UID: "uid1", Content: content.(*page.Content)}}))
+	r.NoError(syncer.processResult(context.Background(), &result{info: &page.Inscription{ID: 2, UID: "uid2", Content: &page.Content{Type: "raw"}}}))
+	r.Equal([]int64{1}, handler.processed)
+	// all the inscriptions are indexed, handled or not.
+	count, err := inscriptionUc.CountInscriptions(context.Background(), &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(2, count)
+}
+
+func TestProtocolHandlersConflict(t *testing.T) {
+	withProtocols(t, map[string]HandlerFactory{
+		"test": func(*HandlerContext) ProtocolHandler {
+			return &testHandler{name: "test", parsers: []parser.Parser{&testParser{name: parser.NameBRC721Mint}}}
+		},
+	})
+	c := &conf.Ord{Worker: &conf.Ord_Worker{Concurrency: 1}, Server: &conf.Ord_Server{}}
+	_, _, err := NewSyncer(c, nil, nil, nil, nil, nil, nil, nil, nil, log.GetLogger())
+	require.ErrorContains(t, err, "content type brc-721-mint is handled by both protocols")
+}
+
+func (s *brc721SigTestSuite) TestRollback() {
+	r := s.Require()
+
+	ctx := context.Background()
+	r.NoError(s.brc721.processDeploy(ctx, s.deployInfo))
+	r.NoError(s.brc721.processMint(ctx, s.mintInfo))
+	mintInfo := *s.mintInfo
+	mintInfo.ID += 10
+	mintInfo.UID = "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i1"
+	r.NoError(s.brc721.processMint(ctx, &mintInfo))
+	collection, err := s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(2), collection.Supply)
+
+	for _, info := range []*page.Inscription{s.mintInfo, &mintInfo} {
+		_, err = s.inscriptionUc.SaveInscription(ctx, &biz.Inscription{InscriptionID: info.ID, UID: info.UID, Address: info.Address})
+		r.NoError(err)
+	}
+	r.NoError(s.syncer.Rollback(ctx, mintInfo.ID))
+	collection, err = s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(1), collection.Supply)
+	inscriptions, err := s.inscriptionUc.ListInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Len(inscriptions, 1)
+	r.Equal(s.mintInfo.ID, inscriptions[0].InscriptionID)
+	tokens, err := s.tokenUc.ListTokens(ctx, &biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Len(tokens, 1)
+	r.Equal(s.mintInfo.ID, tokens[0].InscriptionID)
+	lastInscriptionId, err := s.syncer.getLastInscriptionId(ctx)
+	r.NoError(err)
+	r.Equal(mintInfo.ID, lastInscriptionId)
+
+	// the same mint is indexed again after the rollback.
+	r.NoError(s.brc721.processMint(ctx, &mintInfo))
+	token, err := s.tokenUc.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 2)
+	r.NoError(err)
+	r.Equal(mintInfo.ID, token.InscriptionID)
+
+	r.NoError(s.syncer.Rollback(ctx, s.deployInfo.ID))
+	collection, err = s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Nil(collection)
+	count, err := s.tokenUc.CountTokens(ctx, &biz.TokenListOption{})
+	r.NoError(err)
+	r.Equal(0, count)
+}
+
+func TestBRC721StrictParsers(t *testing.T) {
+	r := require.New(t)
+	content := `{"p": "brc-721", "op": "mint", "tick": "ordinals", "tick": "bitcoin"}`
+	for strict, typ := range map[bool]string{false: parser.NameBRC721Mint, true: page.ContentTypeRaw} {
+		h := newBRC721Handler(&HandlerContext{Conf: &conf.Ord{Parser: &conf.Ord_Parser{Strict: strict}}})
+		data, err := page.NewContentPage("uid", "text/plain;charset=utf-8", 0, h.Parsers()).Parse(strings.NewReader(content))
+		r.NoError(err)
+		r.Equal(typ, data.(*page.Content).Type)
+	}
+}
+
+func TestSyncerParseMint(t *testing.T) {
+	r := require.New(t)
+	content := []byte(`{"p": "brc-721", "op": "mint", "tick": "ordinals", "tick": "bitcoin"}`)
+	for _, strict := range []bool{false, true} {
+		syncer, _, err := NewSyncer(&conf.Ord{Parser: &conf.Ord_Parser{Strict: strict}}, nil, nil, nil, nil, nil, nil, nil, nil, log.GetLogger())
+		r.NoError(err)
+		mint := syncer.ParseMint(content)
+		if strict {
+			r.Nil(mint)
+			continue
+		}
+		r.Equal("bitcoin", mint.Tick)
+	}
+	syncer, _, err := NewSyncer(&conf.Ord{}, nil, nil, nil, nil, nil, nil, nil, nil, log.GetLogger())
+	r.NoError(err)
+	r.Nil(syncer.ParseMint([]byte(`{"p": "brc-721", "op": "deploy", "tick": "ordinals", "max": "10"}`)))
+}
@@ -0,0 +1,121 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+// ReprocessResult reports what changed after re-processing a single inscription.
+type ReprocessResult struct {
+	UID           string          `json:"uid"`
+	InscriptionID int64           `json:"inscription_id"`
+	Parser        string          `json:"parser,omitempty"`
+	Changed       bool            `json:"changed"`
+	SkipReason    string          `json:"skip_reason,omitempty"`
+	Collection    *biz.Collection `json:"collection,omitempty"`
+	Token         *biz.Token      `json:"token,omitempty"`
+}
+
+// Reprocess fetches a single inscription from ord and processes it again.
+// It is idempotent: inscriptions that are already indexed are not processed twice,
+// and inscriptions that would break the first-is-first ordering are skipped.
+// With the leader election enabled, it is only run by the leader, and its commits
+// are fenced like the ones of the sync, otherwise they hold the lock of the sync.
+func (s *Syncer) Reprocess(ctx context.Context, uid string) (*ReprocessResult, error) {
+	ctx, err := s.leaderContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	worker := s.newWorker(0)
+	result := worker.processInscription(ctx, uid)
+	if result.err != nil {
+		return nil, result.err
+	}
+	info := result.info
+	if err := s.resolveTxIndex(ctx, info); err != nil {
+		return nil, err
+	}
+	ret := &ReprocessResult{
+		UID:           info.UID,
+		InscriptionID: info.ID,
+	}
+	if info.Content != nil {
+		ret.Parser = info.Content.Type
+	}
+
+	// the inscription is checked and processed in the transaction holding the fence or
+	// the lock of the sync, so the sync can't index it, or the next token, meanwhile.
+	err = s.data.InTx(ctx, func(ctx context.Context) error {
+		collections, err := s.collectionUc.GetCollectionByInscriptionID(ctx, info.ID)
+		if err != nil {
+			return err
+		}
+		tokens, err := s.tokenUc.FindByInscriptionID(ctx, info.ID)
+		if err != nil {
+			return err
+		}
+		if len(collections) > 0 || len(tokens) > 0 {
+			ret.SkipReason = fmt.Sprintf("inscription %d is already indexed", info.ID)
+			return nil
+		}
+		ret.SkipReason, err = s.checkReprocessOrder(biz.NewNoCacheContext(ctx), info)
+		if err != nil || ret.SkipReason != "" {
+			return err
+		}
+		if err := s.fence(ctx); err != nil {
+			return err
+		}
+		return s.processResult(ctx, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ret.SkipReason != "" {
+		s.logger.Warnf("skip re-processing inscription %d: %s", info.ID, ret.SkipReason)
+		return ret, nil
+	}
+
+	collections, err := s.collectionUc.GetCollectionByInscriptionID(ctx, info.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(collections) > 0 {
+		ret.Collection = collections[0]
+	}
+	tokens, err := s.tokenUc.FindByInscriptionID(ctx, info.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > 0 {
+		ret.Token = tokens[0]
+	}
+	ret.Changed = ret.Collection != nil || ret.Token != nil
+	if !ret.Changed {
+		ret.SkipReason = "inscription was rejected by the protocol rules"
+	}
+	s.logger.Infof("re-processed inscription %d, changed: %t", info.ID, ret.Changed)
+	return ret, nil
+}
+
+// checkReprocessOrder returns a non-empty reason if processing the inscription now
+// would index it out of order.
+func (s *Syncer) checkReprocessOrder(ctx context.Context, info *page.Inscription) (string, error) {
+	lastInscriptionId, err := s.getLastInscriptionId(ctx)
+	if err != nil {
+		return "", err
+	}
+	if info.ID > lastInscriptionId {
+		return fmt.Sprintf("inscription %d is ahead of the sync checkpoint %d", info.ID, lastInscriptionId), nil
+	}
+	if info.Content == nil {
+		return "", nil
+	}
+	checker, ok := s.handler(info.Content.Type).(ReprocessChecker)
+	if !ok {
+		return "", nil
+	}
+	return checker.CheckReprocess(ctx, info)
+}
@@ -0,0 +1,171 @@
+package ord
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+func (s *brc721SigTestSuite) mockReprocess(info *page.Inscription) {
+	inscription := *info
+	inscription.Content = nil
+	mockPageParser := &MockPageParser{}
+	mockPageParser.On("Parse", mock.MatchedBy(func(p *page.BlockPage) bool { return true })).Return(&page.Block{
+		Height: info.GenesisHeight,
+		TxIDs:  []string{s.deployInfo.GenesisTx, info.GenesisTx},
+	}, nil)
+	mockPageParser.On("Parse", mock.MatchedBy(func(p *page.InscriptionPage) bool { return true })).Once().Return(&inscription, nil)
+	mockPageParser.On("Parse", mock.Anything).Return(info.Content, nil)
+	s.syncer.pageParser = mockPageParser
+}
+
+func (s *brc721SigTestSuite) TestReprocessMint() {
+	collection := s.initCollection()
+	s.c.Server.InscriptionIdStart = s.mintInfo.ID
+	defer func() { s.c.Server.InscriptionIdStart = 0 }()
+
+	s.mockReprocess(s.mintInfo)
+	res, err := s.syncer.Reprocess(context.Background(), s.mintInfo.UID)
+	r := s.Require()
+	r.NoError(err)
+	r.True(res.Changed)
+	r.Equal("", res.SkipReason)
+	r.Equal(s.mintInfo.ID, res.InscriptionID)
+	r.NotNil(res.Token)
+	r.Equal(uint64(1), res.Token.TokenID)
+	r.Nil(res.Collection)
+
+	// re-processing again is a no-op
+	s.mockReprocess(s.mintInfo)
+	res, err = s.syncer.Reprocess(context.Background(), s.mintInfo.UID)
+	r.NoError(err)
+	r.False(res.Changed)
+	r.NotEqual("", res.SkipReason)
+
+	collection, err = s.collectionUc.GetCollectionByTick(context.Background(), collection.P, collection.Tick)
+	r.NoError(err)
+	r.Equal(uint64(1), collection.Supply)
+}
+
+func (s *brc721SigTestSuite) TestReprocessMintOutOfOrder() {
+	collection := s.initCollection()
+	s.c.Server.InscriptionIdStart = s.mintInfo.ID + 10
+	defer func() { s.c.Server.InscriptionIdStart = 0 }()
+
+	laterMint := *s.mintInfo
+	laterMint.ID = s.mintInfo.ID + 10
+	laterMint.UID = "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i2"
+	err := s.brc721.processMint(context.Background(), &laterMint)
+	r := s.Require()
+	r.NoError(err)
+
+	s.mockReprocess(s.mintInfo)
+	res, err := s.syncer.Reprocess(context.Background(), s.mintInfo.UID)
+	r.NoError(err)
+	r.False(res.Changed)
+	r.Contains(res.SkipReason, "later inscription")
+
+	tokens, err := s.tokenUc.ListTokens(context.Background(), &biz.TokenListOption{
+		Tick: collection.Tick,
+		P:    collection.P,
+	})
+	r.NoError(err)
+	r.Len(tokens, 1)
+	r.Equal(laterMint.ID, tokens[0].InscriptionID)
+}
+
+func (s *brc721SigTestSuite) TestReprocessAheadOfCheckpoint() {
+	s.initCollection()
+	s.mockReprocess(s.mintInfo)
+	res, err := s.syncer.Reprocess(context.Background(), s.mintInfo.UID)
+	r := s.Require()
+	r.NoError(err)
+	r.False(res.Changed)
+	r.Contains(res.SkipReason, "checkpoint")
+}
+
+func (s *brc721SigTestSuite) TestReprocessLeader() {
+	s.initCollection()
+	s.c.Server.InscriptionIdStart = s.mintInfo.ID
+	s.c.Leader = &conf.Ord_Leader{Enabled: true}
+	defer func() {
+		s.c.Server.InscriptionIdStart = 0
+		s.c.Leader = nil
+	}()
+	r := s.Require()
+	ctx := context.Background()
+
+	// the standby doesn't re-process the inscriptions.
+	_, err := s.syncer.Reprocess(ctx, s.mintInfo.UID)
+	r.ErrorIs(err, ErrNotLeader)
+
+	// the stale leader can't commit after the lease is taken over.
+	repo := &fakeLeaseRepo{}
+	leadership, err := biz.NewLeaderUsecase(repo, s.logger).Campaign(ctx, "sync:mainnet", time.Minute)
+	r.NoError(err)
+	defer leadership.Resign()
+	s.syncer.setLeadership(leadership)
+	repo.takeOver("standby")
+	s.mockReprocess(s.mintInfo)
+	_, err = s.syncer.Reprocess(ctx, s.mintInfo.UID)
+	r.ErrorIs(err, biz.ErrLeaseLost)
+	count, err := s.tokenUc.CountTokens(ctx, &biz.TokenListOption{})
+	r.NoError(err)
+	r.Equal(0, count)
+
+	// the new leader re-processes the inscription.
+	repo.takeOver("")
+	leadership, err = biz.NewLeaderUsecase(repo, s.logger).Campaign(ctx, "sync:mainnet", time.Minute)
+	r.NoError(err)
+	defer leadership.Resign()
+	s.syncer.setLeadership(leadership)
+	res, err := s.syncer.Reprocess(ctx, s.mintInfo.UID)
+	r.NoError(err)
+	r.True(res.Changed)
+}
+
+func (s *brc721SigTestSuite) TestReprocessMintAfterCursed() {
+	r := s.Require()
+	ctx := context.Background()
+	s.c.Brc721 = &conf.Ord_Brc721{Cursed: true}
+	defer func() { s.c.Brc721 = nil }()
+	r.NoError(s.brc721.processDeploy(ctx, s.deployInfo))
+
+	// the blessed mint of a later block is indexed before the cursed mint of an
+	// earlier block, which gets the greater token id.
+	blessed := *s.mintInfo
+	blessed.ID = s.mintInfo.ID + 10
+	blessed.UID = "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i1"
+	blessed.GenesisHeight = s.mintInfo.GenesisHeight + 2
+	r.NoError(s.brc721.processMint(ctx, &blessed))
+	cursed := *s.mintInfo
+	cursed.ID = -5
+	cursed.UID = "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i2"
+	cursed.Cursed = true
+	cursed.GenesisHeight = s.mintInfo.GenesisHeight + 1
+	r.NoError(s.brc721.processMint(ctx, &cursed))
+	token, err := s.tokenUc.FindByTickTokenID(ctx, biz.ProtocolTypeBRC721, "ordinals", 2)
+	r.NoError(err)
+	r.Equal(cursed.ID, token.InscriptionID)
+
+	// the mint between them is behind the blessed mint, whatever the token ids.
+	between := *s.mintInfo
+	between.ID = s.mintInfo.ID + 5
+	between.UID = "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72565i3"
+	between.GenesisHeight = cursed.GenesisHeight
+	reason, err := s.brc721.CheckReprocess(ctx, &between)
+	r.NoError(err)
+	r.Contains(reason, "token 1 of collection ordinals was minted by later inscription")
+
+	// the mint after both of them is not.
+	after := between
+	after.GenesisHeight = blessed.GenesisHeight + 1
+	reason, err = s.brc721.CheckReprocess(ctx, &after)
+	r.NoError(err)
+	r.Equal("", reason)
+}
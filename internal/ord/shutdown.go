@@ -0,0 +1,83 @@
+package ord
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// defaultShutdownTimeout is how long the batch in flight is drained on shutdown by default.
+const defaultShutdownTimeout = 30 * time.Second
+
+// ErrShutdownTimeout is the error of the work aborted after the shutdown timeout.
+var ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
+
+// shutdownContext is done the shutdown timeout after its parent is done, so that
+// the work in flight has a chance to finish on shutdown. It keeps the values of its
+// parent. Its Err is context.DeadlineExceeded after the timeout as for any context,
+// the timeout is reported by timedOut.
+type shutdownContext struct {
+	context.Context
+	done chan struct{}
+	lock sync.Mutex
+	err  error
+}
+
+// withShutdownTimeout returns a copy of the parent that is done the timeout after
+// the parent is done, or when the cancel function is called.
+func withShutdownTimeout(parent context.Context, timeout time.Duration) (*shutdownContext, context.CancelFunc) {
+	ctx := &shutdownContext{Context: parent, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-parent.Done():
+		case <-ctx.done:
+			return
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			ctx.cancel(context.DeadlineExceeded)
+		case <-ctx.done:
+		}
+	}()
+	return ctx, func() { ctx.cancel(context.Canceled) }
+}
+
+func (c *shutdownContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c *shutdownContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *shutdownContext) Err() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err
+}
+
+// timedOut reports whether the context is done by the shutdown timeout.
+func (c *shutdownContext) timedOut() bool {
+	return errors.Is(c.Err(), context.DeadlineExceeded)
+}
+
+func (c *shutdownContext) cancel(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
+
+// shutdownTimeout is how long the batch in flight is drained on shutdown.
+func (s *Syncer) shutdownTimeout() time.Duration {
+	if s.c.Worker == nil || s.c.Worker.ShutdownTimeout == nil {
+		return defaultShutdownTimeout
+	}
+	return s.c.Worker.ShutdownTimeout.AsDuration()
+}
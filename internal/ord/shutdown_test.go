@@ -0,0 +1,133 @@
+package ord
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+// blockingPageParser blocks the inscription pages until they are released or the
+// context is done.
+type blockingPageParser struct {
+	*fakePageParser
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingPageParser) Parse(ctx context.Context, pg page.Page) (interface{}, error) {
+	if strings.HasPrefix(pg.URL(), "/inscription/") {
+		select {
+		case p.started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return p.fakePageParser.Parse(ctx, pg)
+}
+
+func TestWithShutdownTimeout(t *testing.T) {
+	r := require.New(t)
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := withShutdownTimeout(parent, 10*time.Millisecond)
+	defer cancel()
+	cancelParent()
+	// the context is still running after the parent is done.
+	r.NoError(ctx.Err())
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		r.Fail("the context is not done after the shutdown timeout")
+	}
+	// the error of the context is one of the context package, the timeout is reported apart.
+	r.Equal(context.DeadlineExceeded, ctx.Err())
+	r.True(ctx.timedOut())
+
+	ctx, cancel = withShutdownTimeout(context.Background(), time.Hour)
+	cancel()
+	<-ctx.Done()
+	r.Equal(context.Canceled, ctx.Err())
+	r.False(ctx.timedOut())
+}
+
+// runShutdown runs the syncer from the inscription #0 with the deploy and mint
+// inscriptions in flight, and stops it once they are being fetched.
+func (s *brc721SigTestSuite) runShutdown(timeout time.Duration, release bool) error {
+	r := s.Require()
+	s.c.Worker.ShutdownTimeout = durationpb.New(timeout)
+	s.T().Cleanup(func() { s.c.Worker.ShutdownTimeout = nil })
+
+	deployInfo, mintInfo := *s.deployInfo, *s.mintInfo
+	deployInfo.Content, mintInfo.Content = nil, nil
+	nextID := s.mintInfo.ID + 1
+	parser := &blockingPageParser{
+		fakePageParser: &fakePageParser{pages: map[string]interface{}{
+			"/inscriptions/0":                &page.Inscriptions{UIDs: []string{s.deployInfo.UID, s.mintInfo.UID}, NextID: &nextID},
+			"/block/788904":                  &page.Block{Height: 788904, Hash: "hash788904", TxIDs: []string{s.deployInfo.GenesisTx, s.mintInfo.GenesisTx}},
+			"/inscription/" + deployInfo.UID: &deployInfo,
+			"/inscription/" + mintInfo.UID:   &mintInfo,
+			"/content/" + deployInfo.UID:     s.deployInfo.Content,
+			"/content/" + mintInfo.UID:       s.mintInfo.Content,
+		}},
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	s.syncer.pageParser = parser
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() { errC <- s.syncer.Run(ctx) }()
+	<-parser.started
+	cancel()
+	if release {
+		close(parser.release)
+	}
+	var err error
+	select {
+	case err = <-errC:
+	case <-time.After(5 * time.Second):
+		r.Fail("the syncer is not stopped")
+	}
+	// the next page is not started after the shutdown.
+	r.NotContains(parser.parsedURLs(), "/inscriptions/4984404")
+	return err
+}
+
+func (s *brc721SigTestSuite) TestShutdownDrainsBatch() {
+	r := s.Require()
+	r.NoError(s.runShutdown(time.Minute, true))
+
+	// the batch in flight is processed, and the checkpoint is moved.
+	ctx := context.Background()
+	collection, err := s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.NotNil(collection)
+	r.Equal(uint64(1), collection.Supply)
+	lastInscriptionId, err := s.syncer.getLastInscriptionId(ctx)
+	r.NoError(err)
+	r.Equal(int64(4984403), lastInscriptionId)
+}
+
+func (s *brc721SigTestSuite) TestShutdownAbortsBatch() {
+	r := s.Require()
+	r.ErrorIs(s.runShutdown(10*time.Millisecond, false), ErrShutdownTimeout)
+
+	// the batch in flight is aborted without any change.
+	ctx := context.Background()
+	count, err := s.inscriptionUc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(0, count)
+	lastInscriptionId, err := s.syncer.getLastInscriptionId(ctx)
+	r.NoError(err)
+	r.Zero(lastInscriptionId)
+}
@@ -0,0 +1,33 @@
+package ord
+
+import (
+	"context"
+	"io"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+// ExportSnapshot writes the snapshot of the state at the height, 0 for the latest
+// state, with the sync checkpoint to continue syncing from.
+func (s *Syncer) ExportSnapshot(ctx context.Context, w io.Writer, height uint64) (*biz.SnapshotTrailer, error) {
+	checkpoint, err := s.getLastInscriptionId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.snapshotUc.ExportSnapshot(biz.NewNetworkContext(ctx, s.network.Name), w, height, checkpoint)
+}
+
+// ImportSnapshot restores the snapshot into the empty database, and moves the
+// sync checkpoint to the checkpoint of the snapshot.
+func (s *Syncer) ImportSnapshot(ctx context.Context, r io.Reader) (*biz.SnapshotHeader, error) {
+	header, err := s.snapshotUc.ImportSnapshot(biz.NewNetworkContext(ctx, s.network.Name), r)
+	if err != nil {
+		return nil, err
+	}
+	if header.Checkpoint > 0 {
+		if err := s.rewindLastInscriptionId(ctx, header.Checkpoint); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
@@ -0,0 +1,42 @@
+package ord
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/data"
+)
+
+func (s *brc721SigTestSuite) TestSnapshot() {
+	r := s.Require()
+
+	ctx := context.Background()
+	r.NoError(s.brc721.processDeploy(ctx, s.deployInfo))
+	r.NoError(s.brc721.processMint(ctx, s.mintInfo))
+	r.NoError(s.syncer.rewindLastInscriptionId(ctx, s.mintInfo.ID+100))
+	var buf bytes.Buffer
+	trailer, err := s.syncer.ExportSnapshot(ctx, &buf, 0)
+	r.NoError(err)
+	r.Equal(1, trailer.Collections)
+	r.Equal(1, trailer.Tokens)
+
+	// restore into a new database and sync checkpoint.
+	d, cleanup := data.NewTData(s.T())
+	s.cleanup()
+	*s.d = *d
+	s.cleanup = cleanup
+	header, err := s.syncer.ImportSnapshot(ctx, &buf)
+	r.NoError(err)
+	r.Equal(s.mintInfo.ID+100, header.Checkpoint)
+	lastInscriptionId, err := s.syncer.getLastInscriptionId(ctx)
+	r.NoError(err)
+	r.Equal(s.mintInfo.ID+100, lastInscriptionId)
+	collection, err := s.collectionUc.GetCollectionByTick(ctx, biz.ProtocolTypeBRC721, "ordinals")
+	r.NoError(err)
+	r.Equal(uint64(1), collection.Supply)
+	tokens, err := s.tokenUc.ListTokens(ctx, &biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Len(tokens, 1)
+	r.Equal(s.mintInfo.UID, tokens[0].InscriptionUID)
+}
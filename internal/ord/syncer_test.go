@@ -3,7 +3,9 @@ package ord
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,7 +27,7 @@ type MockPageParser struct {
 	mock.Mock
 }
 
-func (m *MockPageParser) Parse(p page.Page) (interface{}, error) {
+func (m *MockPageParser) Parse(ctx context.Context, p page.Page) (interface{}, error) {
 	args := m.Called(p)
 	return args.Get(0), args.Error(1)
 }
@@ -50,102 +52,77 @@ func TestSyncerParseInscriptions(t *testing.T) {
 	)
 	c := &conf.Ord{
 		Worker: &conf.Ord_Worker{
-			Concurrency: 10,
+			Concurrency: 2,
 		},
 		Server: &conf.Ord_Server{
 			Addr: "http://localhost:8080",
 		},
 	}
-	syncer, _, _ := NewSyncer(c, nil, nil, nil, logger)
-	concurrency := 2
-	syncer.inscriptionUidChan = make(chan string, concurrency)
-	syncer.resultChan = make(chan *result, concurrency)
-	syncer.processChan = make(chan uids)
-	syncer.processFinishedChan = make(chan error)
-
-	mockPageParser := &MockPageParser{}
+	syncer, _, _ := NewSyncer(c, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	firstUIDs := []string{
+		"347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
+		"9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0",
+		"e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0",
+		"e616c29b8bd9c6da52866882a04213278854e86e39c7704032ab2b9cdabdc860i0",
+		"c06b2dcb0b92b9fa9b509c4128745dc28a4ee8917e8d8df00ed5b063dc28c55ei0",
+		"6d487246d3f279e053ebf7b92bf1ed949ee63935a6b8160a05d6cae7af4b625ei0",
+	}
+	secondUIDs := []string{
+		"09af268da3a45bb20f49296904f73ec70e1ead6676ba65c97036dd118d7fdcf0i0",
+		"a6bf3307d613fe515b28333aa54a0e844bf28e5f6beeddd1dc0d026b276094f0i0",
+		"2199307ba2e25cff294d4da4d1c06727d0e0bca05c48b2497b66f271df57f3efi0",
+		"a88bdeee9dc55ef00d599e96176a59a416b939890956601087150022ae7bb0efi0",
+		"6279bba4ee7fe35f20d6e2a3df989d0528aeb31a774f76d6d0001556272a66eei0",
+		"959e6e747a6597d811b5910837cd6e3ac8bc58709ec80460c05be6c7600df9ebi0",
+		"f5d970d0a009bb140db9437cbff5157d11c95bba49e2287c123d53bbee21ecebi0",
+	}
 	nextID := int64(4984502)
-	mockPageParser.On("Parse", mock.Anything).Once().Return(&page.Inscriptions{
-		UIDs: []string{
-			"347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
-			"9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0",
-			"e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0",
-			"e616c29b8bd9c6da52866882a04213278854e86e39c7704032ab2b9cdabdc860i0",
-			"c06b2dcb0b92b9fa9b509c4128745dc28a4ee8917e8d8df00ed5b063dc28c55ei0",
-			"6d487246d3f279e053ebf7b92bf1ed949ee63935a6b8160a05d6cae7af4b625ei0",
-		},
-		NextID: &nextID,
-	}, nil)
-
-	mockPageParser.On("Parse", mock.Anything).Return(&page.Inscriptions{
-		UIDs: []string{
-			"09af268da3a45bb20f49296904f73ec70e1ead6676ba65c97036dd118d7fdcf0i0",
-			"a6bf3307d613fe515b28333aa54a0e844bf28e5f6beeddd1dc0d026b276094f0i0",
-			"2199307ba2e25cff294d4da4d1c06727d0e0bca05c48b2497b66f271df57f3efi0",
-			"a88bdeee9dc55ef00d599e96176a59a416b939890956601087150022ae7bb0efi0",
-			"6279bba4ee7fe35f20d6e2a3df989d0528aeb31a774f76d6d0001556272a66eei0",
-			"959e6e747a6597d811b5910837cd6e3ac8bc58709ec80460c05be6c7600df9ebi0",
-			"f5d970d0a009bb140db9437cbff5157d11c95bba49e2287c123d53bbee21ecebi0",
-		},
-		NextID: nil,
-	}, nil)
-	syncer.pageParser = mockPageParser
-
-	go func(syncer *Syncer) {
-		i := 0
-		uids := make([]string, 0)
-		uidNum := 0
-		for {
-			select {
-			case <-syncer.stopC:
-				t.Logf("stopping")
-				return
-			case uids = <-syncer.processChan:
-				t.Logf("processing %d uids, i=%d", len(uids), i)
-			case <-syncer.inscriptionUidChan:
-				uidNum++
-			default:
-				if len(uids) == uidNum && uidNum != 0 {
-					if i == 0 {
-						r := require.New(t)
-						r.Equal(6, len(uids))
-						r.Equal("347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0", uids[0])
-						r.Equal("9bb83fa001542416bdf1eaeed41699f619110e9b68fb25b5cd2628dfb328c063i0", uids[1])
-						r.Equal("6d487246d3f279e053ebf7b92bf1ed949ee63935a6b8160a05d6cae7af4b625ei0", uids[5])
-					}
-					if i == 1 {
-						r := require.New(t)
-						r.Equal(7, len(uids))
-						r.Equal("09af268da3a45bb20f49296904f73ec70e1ead6676ba65c97036dd118d7fdcf0i0", uids[0])
-						r.Equal("a6bf3307d613fe515b28333aa54a0e844bf28e5f6beeddd1dc0d026b276094f0i0", uids[1])
-						r.Equal("f5d970d0a009bb140db9437cbff5157d11c95bba49e2287c123d53bbee21ecebi0", uids[6])
-					}
-					i++
-					uidNum = 0
-					syncer.processFinishedChan <- nil
-				}
-				time.Sleep(100 * time.Millisecond)
-			}
-		}
-	}(syncer)
+	parser := &fakePageParser{pages: map[string]interface{}{
+		"/inscriptions/4984402": &page.Inscriptions{UIDs: firstUIDs, NextID: &nextID},
+		"/inscriptions/4984502": &page.Inscriptions{UIDs: secondUIDs},
+	}}
+	// the inscriptions before the checkpoint are fetched but not processed.
+	for _, uid := range append(append([]string{}, firstUIDs...), secondUIDs...) {
+		parser.set("/inscription/"+uid, &page.Inscription{ID: 1, UID: uid, ContentType: "image/png"})
+	}
+	syncer.pageParser = parser
 
-	err := syncer.parseInscriptions(4984402)
 	r := require.New(t)
-	r.NoError(err)
-	close(syncer.stopC)
+	r.NoError(syncer.parseInscriptions(context.Background(), 4984402))
+	parsed := parser.parsedURLs()
+	r.Equal("/inscriptions/4984402", parsed[0])
+	// the second page is parsed after all the inscriptions of the first page.
+	r.Equal("/inscriptions/4984502", parsed[len(firstUIDs)+1])
+	r.ElementsMatch(firstUIDs, inscriptionUIDs(parsed[1:len(firstUIDs)+1]))
+	r.ElementsMatch(secondUIDs, inscriptionUIDs(parsed[len(firstUIDs)+2:]))
+}
+
+// inscriptionUIDs returns the uids of the inscription page URLs.
+func inscriptionUIDs(urls []string) []string {
+	uids := make([]string, 0, len(urls))
+	for _, url := range urls {
+		uids = append(uids, strings.TrimPrefix(url, "/inscription/"))
+	}
+	return uids
 }
 
 type brc721SigTestSuite struct {
 	suite.Suite
-	c            *conf.Ord
-	collectionUc *biz.CollectionUsecase
-	tokenUc      *biz.TokenUsecase
-	d            *data.Data
-	cleanup      func()
-	syncer       *Syncer
-	logger       log.Logger
-	deployInfo   *page.Inscription
-	mintInfo     *page.Inscription
+	c             *conf.Ord
+	collectionUc  *biz.CollectionUsecase
+	tokenUc       *biz.TokenUsecase
+	traitUc       *biz.TraitUsecase
+	inscriptionUc *biz.InscriptionUsecase
+	snapshotUc    *biz.SnapshotUsecase
+	checkpointUc  *biz.CheckpointUsecase
+	d             *data.Data
+	cleanup       func()
+	syncer        *Syncer
+	brc721        *brc721Handler
+	logger        log.Logger
+	deployInfo    *page.Inscription
+	mintInfo      *page.Inscription
 }
 
 func TestBRC721Suite(t *testing.T) {
@@ -168,13 +145,18 @@ func (s *brc721SigTestSuite) SetupSuite() {
 	tokenRepo := data.NewTokenRepo(s.d, logger)
 	s.collectionUc = biz.NewCollectionUsecase(collectionRepo, logger)
 	s.tokenUc = biz.NewTokenUsecase(tokenRepo, logger)
+	s.traitUc = biz.NewTraitUsecase(data.NewTraitRepo(s.d, logger), tokenRepo, logger)
+	s.snapshotUc = biz.NewSnapshotUsecase(data.NewSnapshotRepo(s.d, logger), logger)
+	s.checkpointUc = biz.NewCheckpointUsecase(data.NewCheckpointRepo(s.d, logger), logger)
+	s.inscriptionUc = biz.NewInscriptionUsecase(data.NewInscriptionRepo(s.d, logger), logger)
 }
 
 func (s *brc721SigTestSuite) SetupTest() {
 	d, cleanup := data.NewTData(s.T())
 	s.cleanup = cleanup
 	*s.d = *d
-	s.syncer, _, _ = NewSyncer(s.c, s.d, s.collectionUc, s.tokenUc, s.logger)
+	s.syncer, _, _ = NewSyncer(s.c, s.d, s.collectionUc, s.inscriptionUc, s.tokenUc, s.traitUc, s.snapshotUc, s.checkpointUc, nil, s.logger)
+	s.brc721 = s.syncer.protocol(parser.BRC721).(*brc721Handler)
 	deployInfo := &page.Inscription{
 		ID:            4984402,
 		UID:           "347052bed5c7929f5e5186188ee3abd571f9c1f619d6ac6238b96437b7b72564i0",
@@ -236,7 +218,7 @@ func (s *brc721SigTestSuite) TearDownTest() {
 }
 
 func (s *brc721SigTestSuite) TestNewDeploy() {
-	err := s.syncer.processBRC721Deploy(s.deployInfo)
+	err := s.brc721.processDeploy(context.Background(), s.deployInfo)
 	r := s.Require()
 	r.NoError(err)
 	collections, err := s.collectionUc.GetCollectionByInscriptionID(context.Background(), 4984402)
@@ -292,7 +274,7 @@ func (s *brc721SigTestSuite) TestDeployWithExistentTick() {
 	r.NoError(err)
 	r.NotNil(newCollection)
 	r.True(newCollection.ID > 0)
-	err = s.syncer.processBRC721Deploy(s.deployInfo)
+	err = s.brc721.processDeploy(context.Background(), s.deployInfo)
 	r.NoError(err)
 	collections, err := s.collectionUc.ListCollections(context.Background(), &biz.CollectionListOption{})
 	r.NoError(err)
@@ -310,7 +292,7 @@ func (s *brc721SigTestSuite) TestDeployWithSig() {
 		Fields: []sig.SigField{sig.SigFieldReceiver, sig.SigFieldUid},
 	}
 	s.deployInfo.Content.Data.(*parser.BRC721Deploy).Sig = deploySig
-	err = s.syncer.processBRC721Deploy(s.deployInfo)
+	err = s.brc721.processDeploy(context.Background(), s.deployInfo)
 	r.NoError(err)
 	collections, err := s.collectionUc.GetCollectionByInscriptionID(context.Background(), 4984402)
 	r.NoError(err)
@@ -346,7 +328,7 @@ func (s *brc721SigTestSuite) TestDeployWithInvalidSigPubKey() {
 		Fields: []sig.SigField{sig.SigFieldReceiver, sig.SigFieldUid},
 	}
 	s.deployInfo.Content.Data.(*parser.BRC721Deploy).Sig = deploySig
-	err := s.syncer.processBRC721Deploy(s.deployInfo)
+	err := s.brc721.processDeploy(context.Background(), s.deployInfo)
 	r.NoError(err)
 	collections, err := s.collectionUc.GetCollectionByInscriptionID(context.Background(), 4984402)
 	r.NoError(err)
@@ -356,7 +338,7 @@ func (s *brc721SigTestSuite) TestDeployWithInvalidSigPubKey() {
 func (s *brc721SigTestSuite) TestDeployWithInvalidMax() {
 	r := s.Require()
 	s.deployInfo.Content.Data.(*parser.BRC721Deploy).Max = "InvalidMax"
-	err := s.syncer.processBRC721Deploy(s.deployInfo)
+	err := s.brc721.processDeploy(context.Background(), s.deployInfo)
 	r.NoError(err)
 	collections, err := s.collectionUc.GetCollectionByInscriptionID(context.Background(), 4984402)
 	r.NoError(err)
@@ -373,7 +355,7 @@ func (s *brc721SigTestSuite) TestDeployWithSigDuplicateFields() {
 		Fields: []sig.SigField{sig.SigFieldReceiver, sig.SigFieldReceiver},
 	}
 	s.deployInfo.Content.Data.(*parser.BRC721Deploy).Sig = deploySig
-	err = s.syncer.processBRC721Deploy(s.deployInfo)
+	err = s.brc721.processDeploy(context.Background(), s.deployInfo)
 	r.NoError(err)
 	collections, err := s.collectionUc.GetCollectionByInscriptionID(context.Background(), 4984402)
 	r.NoError(err)
@@ -390,7 +372,7 @@ func (s *brc721SigTestSuite) TestDeployWithSigInvalidFields() {
 		Fields: []sig.SigField{sig.SigFieldReceiver, sig.SigField("invalid_field")},
 	}
 	s.deployInfo.Content.Data.(*parser.BRC721Deploy).Sig = deploySig
-	err = s.syncer.processBRC721Deploy(s.deployInfo)
+	err = s.brc721.processDeploy(context.Background(), s.deployInfo)
 	r.NoError(err)
 	collections, err := s.collectionUc.GetCollectionByInscriptionID(context.Background(), 4984402)
 	r.NoError(err)
@@ -409,7 +391,7 @@ func (s *brc721SigTestSuite) initCollection() *biz.Collection {
 
 func (s *brc721SigTestSuite) TestNewMint() {
 	collection := s.initCollection()
-	err := s.syncer.processBRC721Mint(s.mintInfo)
+	err := s.brc721.processMint(context.Background(), s.mintInfo)
 	r := s.Require()
 	r.NoError(err)
 	tokens, err := s.tokenUc.ListTokens(context.Background(), &biz.TokenListOption{
@@ -438,7 +420,7 @@ func (s *brc721SigTestSuite) TestNewMint() {
 }
 
 func (s *brc721SigTestSuite) TestMintWithNonExistentTick() {
-	err := s.syncer.processBRC721Mint(s.mintInfo)
+	err := s.brc721.processMint(context.Background(), s.mintInfo)
 	r := s.Require()
 	r.NoError(err)
 	tokens, err := s.tokenUc.ListTokens(context.Background(), &biz.TokenListOption{})
@@ -449,7 +431,7 @@ func (s *brc721SigTestSuite) TestMintWithNonExistentTick() {
 func (s *brc721SigTestSuite) TestMintWithPreviousToken() {
 	collection := s.initCollection()
 	s.mintInfo.ID = collection.InscriptionID - 1
-	err := s.syncer.processBRC721Mint(s.mintInfo)
+	err := s.brc721.processMint(context.Background(), s.mintInfo)
 	r := s.Require()
 	r.NoError(err)
 	tokens, err := s.tokenUc.ListTokens(context.Background(), &biz.TokenListOption{
@@ -466,7 +448,7 @@ func (s *brc721SigTestSuite) TestMintWithMaxSupply() {
 	_, err := s.collectionUc.UpdateCollection(context.Background(), collection)
 	r := s.Require()
 	r.NoError(err)
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err := s.tokenUc.ListTokens(context.Background(), &biz.TokenListOption{
 		Tick: collection.Tick,
@@ -476,12 +458,77 @@ func (s *brc721SigTestSuite) TestMintWithMaxSupply() {
 	r.Len(tokens, 0)
 }
 
+func (s *brc721SigTestSuite) TestMintWithChildMints() {
+	r := s.Require()
+	ctx := context.Background()
+	deploy := *s.deployInfo.Content.Data.(*parser.BRC721Deploy)
+	deploy.Child = true
+	deployInfo := *s.deployInfo
+	deployInfo.Content = &page.Content{Data: &deploy, Type: parser.NameBRC721Deploy}
+	r.NoError(s.brc721.processDeploy(ctx, &deployInfo))
+	collection, err := s.collectionUc.GetCollectionByTick(ctx, "brc-721", "ordinals")
+	r.NoError(err)
+	r.True(collection.ChildMints)
+
+	// the mint is not a child of the deploy inscription.
+	r.NoError(s.brc721.processMint(ctx, s.mintInfo))
+	mintInfo := *s.mintInfo
+	mintInfo.ParentUID = "e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0"
+	r.NoError(s.brc721.processMint(ctx, &mintInfo))
+	count, err := s.tokenUc.CountTokens(ctx, &biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Equal(0, count)
+
+	mintInfo.ParentUID = s.deployInfo.UID
+	r.NoError(s.brc721.processMint(ctx, &mintInfo))
+	count, err = s.tokenUc.CountTokens(ctx, &biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Equal(1, count)
+}
+
+func (s *brc721SigTestSuite) TestVerifyMint() {
+	r := s.Require()
+	ctx := context.Background()
+	deploy := *s.deployInfo.Content.Data.(*parser.BRC721Deploy)
+	deploy.Child = true
+	deployInfo := *s.deployInfo
+	deployInfo.Content = &page.Content{Data: &deploy, Type: parser.NameBRC721Deploy}
+	r.NoError(s.brc721.processDeploy(ctx, &deployInfo))
+	mint := s.mintInfo.Content.Data.(*parser.BRC721Mint)
+
+	report, err := s.syncer.VerifyMint(ctx, &MintCandidate{Mint: mint, Receiver: s.mintInfo.Address, BlockHeight: 788905, Cursed: true})
+	r.NoError(err)
+	r.False(report.Eligible)
+	r.True(report.Sig.Valid)
+	r.Equal([]*biz.MintSigVerdict{
+		{Field: biz.MintFieldDeploy, Valid: true},
+		{Field: biz.MintFieldCursed, Reason: "cursed inscriptions do not count for brc-721"},
+		{Field: biz.MintFieldParent, Reason: "mint is not a child of collection ordinals"},
+		{Field: biz.MintFieldSupply, Valid: true},
+	}, report.Eligibility)
+
+	// the mint is eligible once the syncer would index it.
+	report, err = s.syncer.VerifyMint(ctx, &MintCandidate{Mint: mint, Receiver: s.mintInfo.Address, BlockHeight: 788905, ParentUID: s.deployInfo.UID})
+	r.NoError(err)
+	r.True(report.Eligible)
+	report, err = s.syncer.VerifyMint(ctx, &MintCandidate{Mint: mint, Receiver: s.mintInfo.Address, BlockHeight: 788903, ParentUID: s.deployInfo.UID})
+	r.NoError(err)
+	r.False(report.Eligible)
+	r.Equal("collection ordinals is deployed at block 788904 after the mint", report.Eligibility[0].Reason)
+
+	other := *mint
+	other.Tick = "unknown"
+	report, err = s.syncer.VerifyMint(ctx, &MintCandidate{Mint: &other, BlockHeight: 788905})
+	r.NoError(err)
+	r.Nil(report)
+}
+
 func (s *brc721SigTestSuite) TestMintWithExistentInscription() {
 	collection := s.initCollection()
 	_, err := s.collectionUc.UpdateCollection(context.Background(), collection)
 	r := s.Require()
 	r.NoError(err)
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err := s.tokenUc.ListTokens(context.Background(), &biz.TokenListOption{
 		Tick: collection.Tick,
@@ -490,7 +537,7 @@ func (s *brc721SigTestSuite) TestMintWithExistentInscription() {
 	r.NoError(err)
 	r.Len(tokens, 1)
 
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = s.tokenUc.ListTokens(context.Background(), &biz.TokenListOption{
 		Tick: collection.Tick,
@@ -523,7 +570,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 	}
 
 	// missing sig
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err := listTokens()
 	r.NoError(err)
@@ -533,7 +580,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 	s.mintInfo.Content.Data.(*parser.BRC721Mint).Sig = &sig.MintSig{
 		Signature: "",
 	}
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -545,7 +592,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 		Uid:         "uid",
 		ExpiredTime: 0,
 	}
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -558,7 +605,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 		ExpiredTime:   100,
 		ExpiredHeight: 788905,
 	}
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -571,7 +618,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 		ExpiredTime:   1624296001,
 		ExpiredHeight: 0,
 	}
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -584,7 +631,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 		ExpiredTime:   1624296001,
 		ExpiredHeight: 100,
 	}
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -601,7 +648,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 	r.NoError(err)
 	mintSig.Signature = string(sigBytes)
 	s.mintInfo.Content.Data.(*parser.BRC721Mint).Sig = mintSig
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -618,7 +665,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 	r.NoError(err)
 	mintSig.Signature = string(sigBytes)
 	s.mintInfo.Content.Data.(*parser.BRC721Mint).Sig = mintSig
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -631,7 +678,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 	// uid already exists
 	s.mintInfo.UID = "uid"
 	s.mintInfo.ID += 1
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -641,7 +688,7 @@ func (s *brc721SigTestSuite) TestMintWithSig() {
 	sigBytes, err = mintSig.Sign(privateKey) // sig bytes are already in DER encoded format
 	r.NoError(err)
 	mintSig.Signature = string(sigBytes)
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err = listTokens()
 	r.NoError(err)
@@ -683,7 +730,7 @@ func (s *brc721SigTestSuite) TestMintWithSigSomeFields() {
 	r.NoError(err)
 	mintSig.Signature = string(sigBytes)
 	s.mintInfo.Content.Data.(*parser.BRC721Mint).Sig = mintSig
-	err = s.syncer.processBRC721Mint(s.mintInfo)
+	err = s.brc721.processMint(context.Background(), s.mintInfo)
 	r.NoError(err)
 	tokens, err := listTokens()
 	r.NoError(err)
@@ -692,3 +739,201 @@ func (s *brc721SigTestSuite) TestMintWithSigSomeFields() {
 	r.NoError(err)
 	r.Equal(uint64(1), collection.Supply)
 }
+
+func (s *brc721SigTestSuite) TestVerifyMintSig() {
+	privateKey, err := btcec.NewPrivateKey()
+	r := s.Require()
+	r.NoError(err)
+	collection := s.initCollection()
+	ctx := context.Background()
+	verdicts := func(report *biz.MintSigReport) map[string]string {
+		m := make(map[string]string)
+		for _, v := range report.Verdicts {
+			r.Equal(v.Reason == "", v.Valid, v.Field)
+			m[v.Field] = v.Reason
+		}
+		return m
+	}
+
+	// the collection does not require signed mints.
+	report, err := s.tokenUc.VerifyMintSig(ctx, collection, &biz.MintSigCandidate{Receiver: s.mintInfo.Address})
+	r.NoError(err)
+	r.False(report.Required)
+	r.True(report.Valid)
+
+	collection.Sig = sig.DeploySig{
+		PubKey: hex.EncodeToString(privateKey.PubKey().SerializeCompressed()),
+		Fields: []sig.SigField{sig.SigFieldReceiver, sig.SigFieldUid, sig.SigFieldExpiredTime, sig.SigFieldExpiredHeight},
+	}
+	collection, err = s.collectionUc.UpdateCollection(ctx, collection)
+	r.NoError(err)
+	candidate := &biz.MintSigCandidate{
+		Receiver:    s.mintInfo.Address,
+		BlockHeight: s.mintInfo.GenesisHeight,
+		BlockTime:   s.mintInfo.Timestamp,
+	}
+	report, err = s.tokenUc.VerifyMintSig(ctx, collection, candidate)
+	r.NoError(err)
+	r.True(report.Required)
+	r.False(report.Valid)
+	r.Equal(map[string]string{biz.MintSigFieldSig: "missing mint sig"}, verdicts(report))
+
+	// every field gets a verdict.
+	candidate.Sig = &sig.MintSig{Signature: "signature", ExpiredTime: 100, ExpiredHeight: 788905}
+	report, err = s.tokenUc.VerifyMintSig(ctx, collection, candidate)
+	r.NoError(err)
+	r.False(report.Valid)
+	r.Equal(map[string]string{
+		"rec":  "",
+		"uid":  "missing sig.uid",
+		"expt": "sig.expt 100 is expired at block time 1624296000",
+		"exph": "",
+		"s":    "failed to verify sig.s: malformed signature: no header magic",
+	}, verdicts(report))
+
+	// the signature is verified against the receiver of the mint.
+	mintSig := &sig.MintSig{Receiver: "bc1pother", Uid: "uid", ExpiredTime: 1624296001, ExpiredHeight: 788905}
+	sigBytes, err := mintSig.Sign(privateKey)
+	r.NoError(err)
+	mintSig.Signature = string(sigBytes)
+	candidate.Sig = mintSig
+	report, err = s.tokenUc.VerifyMintSig(ctx, collection, candidate)
+	r.NoError(err)
+	r.False(report.Valid)
+	r.Equal("invalid sig.s, the signed sig.rec bc1pother is not the receiver "+s.mintInfo.Address, verdicts(report)["s"])
+
+	candidate.Receiver = "bc1pother"
+	report, err = s.tokenUc.VerifyMintSig(ctx, collection, candidate)
+	r.NoError(err)
+	r.True(report.Valid)
+	r.Equal(mintSig, report.Sig)
+	r.Len(report.Verdicts, 5)
+}
+
+// positionResults returns the results of a cursed mint between the deploy and a blessed
+// mint in the block, they are listed in the descending order of the numbers.
+func (s *brc721SigTestSuite) positionResults() []*result {
+	cursedMint := *s.mintInfo
+	cursedMint.ID = -5
+	cursedMint.UID = "e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562i0"
+	cursedMint.GenesisTx = "e9948dd04f3b63810e52c77f431daf6179cb06219724493cf8e5349b6c3cb562"
+	cursedMint.Cursed = true
+	mockPageParser := &MockPageParser{}
+	mockPageParser.On("Parse", mock.MatchedBy(func(p *page.BlockPage) bool { return p.Height == 788904 })).Once().Return(&page.Block{
+		Height: 788904,
+		TxIDs: []string{
+			"e3678715396719368e039fa56a09aa77eb30a2ea525f5489779626e355a31b65",
+			s.deployInfo.GenesisTx,
+			cursedMint.GenesisTx,
+			s.mintInfo.GenesisTx,
+		},
+	}, nil)
+	s.syncer.pageParser = mockPageParser
+	return []*result{{info: s.mintInfo}, {info: s.deployInfo}, {info: &cursedMint}}
+}
+
+func (s *brc721SigTestSuite) TestProcessResultsInPositionOrder() {
+	r := s.Require()
+	s.c.Brc721 = &conf.Ord_Brc721{Cursed: true}
+	defer func() { s.c.Brc721 = nil }()
+	s.syncer, _, _ = NewSyncer(s.c, s.d, s.collectionUc, s.inscriptionUc, s.tokenUc, s.traitUc, s.snapshotUc, s.checkpointUc, nil, s.logger)
+
+	ctx := context.Background()
+	count, err := s.syncer.processResults(context.Background(), s.positionResults(), s.deployInfo.ID)
+	r.NoError(err)
+	r.Equal(3, count)
+	tokens, err := s.tokenUc.ListTokens(ctx, &biz.TokenListOption{Tick: "ordinals", Order: "token_id"})
+	r.NoError(err)
+	r.Len(tokens, 2)
+	r.Equal(int64(-5), tokens[0].InscriptionID)
+	r.Equal(s.mintInfo.ID, tokens[1].InscriptionID)
+	ins, err := s.inscriptionUc.FindByInscriptionID(ctx, -5)
+	r.NoError(err)
+	r.True(ins.Cursed)
+	r.Equal(uint64(2), ins.TxIndex)
+	// the checkpoint is the last blessed inscription.
+	lastInscriptionId, err := s.syncer.getLastInscriptionId(ctx)
+	r.NoError(err)
+	r.Equal(s.mintInfo.ID, lastInscriptionId)
+
+	// the cursed mint is processed again in the next batch, but its token is not minted twice.
+	count, err = s.syncer.processResults(context.Background(), s.positionResults(), lastInscriptionId)
+	r.NoError(err)
+	r.Equal(2, count)
+	count, err = s.tokenUc.CountTokens(ctx, &biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Equal(2, count)
+}
+
+func (s *brc721SigTestSuite) TestProcessResultsIgnoreCursed() {
+	r := s.Require()
+
+	ctx := context.Background()
+	count, err := s.syncer.processResults(context.Background(), s.positionResults(), 0)
+	r.NoError(err)
+	r.Equal(3, count)
+	tokens, err := s.tokenUc.ListTokens(ctx, &biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Len(tokens, 1)
+	r.Equal(s.mintInfo.ID, tokens[0].InscriptionID)
+	r.Equal(uint64(1), tokens[0].TokenID)
+	// the cursed inscriptions are indexed whether or not they count for brc-721.
+	ins, err := s.inscriptionUc.FindByInscriptionID(ctx, -5)
+	r.NoError(err)
+	r.NotNil(ins)
+}
+
+func (s *brc721SigTestSuite) TestProcessResultsFailedBehindCheckpoint() {
+	r := s.Require()
+	ctx := context.Background()
+	r.NoError(s.syncer.rewindLastInscriptionId(ctx, s.deployInfo.ID))
+
+	// the failed fetch is not taken for an inscription behind the checkpoint.
+	deployInfo := *s.deployInfo
+	failed := &result{info: &page.Inscription{UID: s.mintInfo.UID}, err: errors.New("ord is unavailable")}
+	count, err := s.syncer.processResults(ctx, []*result{{info: &deployInfo}, failed}, s.deployInfo.ID)
+	r.ErrorContains(err, "ord is unavailable")
+	r.Equal(0, count)
+	lastInscriptionId, err := s.syncer.getLastInscriptionId(ctx)
+	r.NoError(err)
+	r.Equal(s.deployInfo.ID, lastInscriptionId)
+	count, err = s.inscriptionUc.CountInscriptions(ctx, &biz.InscriptionListOption{})
+	r.NoError(err)
+	r.Equal(0, count)
+}
+
+func (s *brc721SigTestSuite) TestMintBeforeDeployInBlock() {
+	r := s.Require()
+	ctx := context.Background()
+	s.deployInfo.TxIndex = 2
+	s.mintInfo.TxIndex = 1
+	_, err := s.inscriptionUc.SaveInscription(ctx, &biz.Inscription{
+		InscriptionID: s.deployInfo.ID,
+		UID:           s.deployInfo.UID,
+		GenesisHeight: s.deployInfo.GenesisHeight,
+		TxIndex:       s.deployInfo.TxIndex,
+	})
+	r.NoError(err)
+	r.NoError(s.brc721.processDeploy(ctx, s.deployInfo))
+	// the mint is numbered after the deploy, but it is before the deploy in the block.
+	r.NoError(s.brc721.processMint(ctx, s.mintInfo))
+	count, err := s.tokenUc.CountTokens(ctx, &biz.TokenListOption{Tick: "ordinals"})
+	r.NoError(err)
+	r.Equal(0, count)
+}
+
+func (s *brc721SigTestSuite) TestWorkerUndecodableAddress() {
+	r := s.Require()
+	mintInfo := *s.mintInfo
+	mintInfo.Content = nil
+	// the witness version 2 is not decoded yet.
+	mintInfo.Address = "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs"
+	s.syncer.pageParser = &fakePageParser{pages: map[string]interface{}{
+		"/inscription/" + mintInfo.UID: &mintInfo,
+		"/content/" + mintInfo.UID:     s.mintInfo.Content,
+	}}
+	result := s.syncer.newWorker(0).processInscription(context.Background(), mintInfo.UID)
+	r.NoError(result.err)
+	r.Equal(mintInfo.ID, result.info.ID)
+	r.Equal("", result.info.Address)
+}
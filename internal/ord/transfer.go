@@ -0,0 +1,373 @@
+package ord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord/page"
+)
+
+// maxSpentOutputs is the max number of the spent outputs looked up by one query.
+const maxSpentOutputs = 1000
+
+// resolveGenesisLocation moves the inscription back to its genesis satpoint in the blocks
+// mode, as ord shows its latest location and the moves after the genesis are ingested
+// block by block. The location shown by ord is the genesis satpoint if it is still in the
+// genesis tx, otherwise the first inscription of the tx is placed on the first sat of its
+// inputs by ord. The indexed inscriptions keep the locations tracked by the syncer, eg:
+// when they are re-processed.
+func (s *Syncer) resolveGenesisLocation(ctx context.Context, info *page.Inscription) error {
+	existing, err := s.inscriptionUc.FindByUID(biz.NewNoCacheContext(ctx), info.UID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		info.Address = existing.Address
+		info.Location = existing.Location
+		info.Output = existing.Output
+		info.Offset = existing.Offset
+		info.OutputValue = existing.OutputValue
+		return nil
+	}
+	if strings.HasPrefix(info.Output, info.GenesisTx+":") {
+		return nil
+	}
+	// the satpoints of the other inscriptions of the tx depend on the envelopes, which
+	// ord doesn't show, they are followed from the locations shown by ord.
+	if info.UID != info.GenesisTx+"i0" {
+		s.logger.Warnf("inscription %d is moved out of its genesis tx %s, it is followed from %s", info.ID, info.GenesisTx, info.Location)
+		return nil
+	}
+	tx, err := s.fetchTx(ctx, info.GenesisTx)
+	if err != nil {
+		return err
+	}
+	output, offset := locateSat(tx, 0)
+	if output == nil {
+		return fmt.Errorf("genesis tx %s of inscription %d has no output", info.GenesisTx, info.ID)
+	}
+	info.Address = output.Address
+	info.Location = fmt.Sprintf("%s:%d", output.Outpoint, offset)
+	info.Output = output.Outpoint
+	info.Offset = offset
+	info.OutputValue = output.Value
+	return nil
+}
+
+// locateSat returns the output of the tx holding the sat at the offset of the inputs, and
+// the offset of the sat in it. The sats of the inputs are assigned to the outputs first
+// in first out, the output is nil if the sat is spent as the fee.
+func locateSat(tx *page.Tx, offset uint64) (*page.TxOutput, uint64) {
+	for _, output := range tx.Outputs {
+		if offset < output.Value {
+			return output, offset
+		}
+		offset -= output.Value
+	}
+	return nil, 0
+}
+
+// processTransfers moves the token inscriptions spent by the txs of the block in order,
+// and records the transfers of their tokens. The locations of the other inscriptions
+// are the ones shown by ord when they are indexed. The inputs of the txs are shown by
+// ord's tx pages only, the outputs they spend are looked up in the database at once, and
+// only the txs spending the token inscriptions are processed.
+func (s *Syncer) processTransfers(ctx context.Context, block *page.Block) error {
+	ctx = biz.NewNoCacheContext(ctx)
+	count, err := s.tokenUc.CountTokens(ctx, &biz.TokenListOption{})
+	if err != nil {
+		return err
+	}
+	// the txs are not fetched before the first token is minted, and the coinbase tx
+	// spends no inscription.
+	if count == 0 || len(block.TxIDs) < 2 {
+		return nil
+	}
+	txs, err := s.fetchTxs(ctx, block.TxIDs[1:])
+	if err != nil {
+		return err
+	}
+	inputs := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		inputs = append(inputs, tx.Inputs...)
+	}
+	spent, err := s.spentInscriptions(ctx, inputs)
+	if err != nil {
+		return err
+	}
+	// the token inscriptions by their outputs, and the values of the outputs known
+	// without fetching them from ord.
+	tracked := make(map[string][]*biz.Inscription)
+	values := make(map[string]uint64)
+	for _, ins := range spent {
+		values[ins.Output] = ins.OutputValue
+		tokens, err := s.tokenUc.FindByInscriptionID(ctx, ins.InscriptionID)
+		if err != nil {
+			return err
+		}
+		if len(tokens) > 0 {
+			tracked[ins.Output] = append(tracked[ins.Output], ins)
+		}
+	}
+	for _, tx := range txs {
+		for _, output := range tx.Outputs {
+			values[output.Outpoint] = output.Value
+		}
+	}
+	for _, tx := range txs {
+		if err := s.processTx(ctx, block, tx, tracked, values); err != nil {
+			return fmt.Errorf("failed to process tx %s: %w", tx.TxID, err)
+		}
+	}
+	return nil
+}
+
+// processTx moves the token inscriptions spent by the tx, the inscriptions spent as the
+// fee are moved to no address and no longer tracked. The moved inscriptions are tracked
+// by their new outputs, which may be spent by the txs after it in the block.
+func (s *Syncer) processTx(ctx context.Context, block *page.Block, tx *page.Tx, tracked map[string][]*biz.Inscription, values map[string]uint64) error {
+	last := -1
+	for i, input := range tx.Inputs {
+		if len(tracked[input]) > 0 {
+			last = i
+		}
+	}
+	// the offset of the first sat of the input in the tx.
+	var offset uint64
+	for i := 0; i <= last; i++ {
+		input := tx.Inputs[i]
+		inscriptions := tracked[input]
+		delete(tracked, input)
+		for _, ins := range inscriptions {
+			moved, err := s.moveInscription(ctx, block, tx, ins, offset+ins.Offset)
+			if err != nil {
+				return err
+			}
+			if moved.Output != "" {
+				tracked[moved.Output] = append(tracked[moved.Output], moved)
+			}
+		}
+		if i == last {
+			break
+		}
+		value, err := s.outputValue(ctx, input, values)
+		if err != nil {
+			return err
+		}
+		offset += value
+	}
+	return nil
+}
+
+// moveInscription moves the token inscription to the sat at the offset of the inputs of
+// the tx, and records the transfers of its tokens. It returns the inscription moved.
+func (s *Syncer) moveInscription(ctx context.Context, block *page.Block, tx *page.Tx, ins *biz.Inscription, offset uint64) (*biz.Inscription, error) {
+	tokens, err := s.tokenUc.FindByInscriptionID(ctx, ins.InscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	moved := *ins
+	moved.Address, moved.Location, moved.Output, moved.Offset, moved.OutputValue = "", "", "", 0, 0
+	if output, offset := locateSat(tx, offset); output != nil {
+		moved.Address = output.Address
+		moved.Location = fmt.Sprintf("%s:%d", output.Outpoint, offset)
+		moved.Output = output.Outpoint
+		moved.Offset = offset
+		moved.OutputValue = output.Value
+	}
+	if err := s.fence(ctx); err != nil {
+		return nil, err
+	}
+	// the transfers and the move of the inscription are committed together.
+	err = s.data.InTx(ctx, func(ctx context.Context) error {
+		return s.commitMove(ctx, block, tx, ins, &moved, tokens)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Infof("moved inscription %d from %s to %s by tx %s", ins.InscriptionID, ins.Location, moved.Location, tx.TxID)
+	return &moved, nil
+}
+
+// commitMove records the transfers of the tokens of the inscription moved by the tx,
+// and moves the inscription.
+func (s *Syncer) commitMove(ctx context.Context, block *page.Block, tx *page.Tx, ins, moved *biz.Inscription, tokens []*biz.Token) error {
+	for _, token := range tokens {
+		_, err := s.tokenUc.TransferToken(ctx, &biz.Transfer{
+			P:               token.P,
+			Tick:            token.Tick,
+			TokenID:         token.TokenID,
+			From:            ins.Address,
+			To:              moved.Address,
+			TxHash:          tx.TxID,
+			BlockHeight:     block.Height,
+			BlockTime:       block.Timestamp,
+			FromLocation:    ins.Location,
+			ToLocation:      moved.Location,
+			FromOutputValue: ins.OutputValue,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to transfer token %s #%d: %w", token.Tick, token.TokenID, err)
+		}
+	}
+	_, err := s.inscriptionUc.UpdateInscription(ctx, moved)
+	return err
+}
+
+// rollbackTransfers deletes the transfers from the height on, and moves their token
+// inscriptions back to the locations before the height.
+func (s *Syncer) rollbackTransfers(ctx context.Context, height uint64) error {
+	ctx = biz.NewNoCacheContext(ctx)
+	transfers, err := s.tokenUc.DeleteTransfersFrom(ctx, height)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfers: %w", err)
+	}
+	// the transfers are from the latest one, the earliest transfer of an inscription
+	// is moved from its location before the height.
+	moved := make(map[string]bool)
+	for i := len(transfers) - 1; i >= 0; i-- {
+		t := transfers[i]
+		if moved[t.InscriptionUID] {
+			continue
+		}
+		moved[t.InscriptionUID] = true
+		ins, err := s.inscriptionUc.FindByUID(ctx, t.InscriptionUID)
+		if err != nil {
+			return err
+		}
+		if ins == nil {
+			continue
+		}
+		ins.Address = t.From
+		ins.Location = t.FromLocation
+		ins.Output, ins.Offset = splitLocation(t.FromLocation)
+		ins.OutputValue = t.FromOutputValue
+		if _, err := s.inscriptionUc.UpdateInscription(ctx, ins); err != nil {
+			return err
+		}
+	}
+	s.logger.Infof("rolled back %d transfers from block %d", len(transfers), height)
+	return nil
+}
+
+// splitLocation splits the satpoint into the output and the offset, eg: "<txid>:<vout>:<offset>".
+func splitLocation(location string) (string, uint64) {
+	i := strings.LastIndex(location, ":")
+	if i < 0 {
+		return "", 0
+	}
+	offset, _ := strconv.ParseUint(location[i+1:], 10, 64)
+	return location[:i], offset
+}
+
+// spentInscriptions lists the indexed inscriptions on the outputs.
+func (s *Syncer) spentInscriptions(ctx context.Context, outputs []string) ([]*biz.Inscription, error) {
+	ret := make([]*biz.Inscription, 0)
+	for len(outputs) > 0 {
+		n := len(outputs)
+		if n > maxSpentOutputs {
+			n = maxSpentOutputs
+		}
+		opt := &biz.InscriptionListOption{Outputs: outputs[:n]}
+		outputs = outputs[n:]
+		for {
+			inscriptions, next, err := s.inscriptionUc.ListInscriptionsPage(ctx, opt)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, inscriptions...)
+			if next == nil {
+				break
+			}
+			opt.Cursor = next
+		}
+	}
+	return ret, nil
+}
+
+// outputValue returns the value of the output, the outputs not known by the block or
+// the database are fetched from ord.
+func (s *Syncer) outputValue(ctx context.Context, outpoint string, values map[string]uint64) (uint64, error) {
+	if value, ok := values[outpoint]; ok {
+		return value, nil
+	}
+	data, err := s.pageParser.Parse(ctx, page.NewOutputPage(outpoint))
+	if err != nil {
+		return 0, err
+	}
+	output, ok := data.(*page.TxOutput)
+	if !ok {
+		return 0, fmt.Errorf("invalid output page: %T", data)
+	}
+	values[outpoint] = output.Value
+	return output.Value, nil
+}
+
+// fetchTx fetches the tx from ord.
+func (s *Syncer) fetchTx(ctx context.Context, txid string) (*page.Tx, error) {
+	txPage := page.NewTxPage(txid)
+	s.logger.Debugf("fetching %s...", txPage.URL())
+	data, err := s.pageParser.Parse(ctx, txPage)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := data.(*page.Tx)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type: %T for URL %s", data, txPage.URL())
+	}
+	return tx, nil
+}
+
+// fetchTxs fetches the txs with the concurrency of the workers, the txs are in the
+// order of the txids.
+func (s *Syncer) fetchTxs(ctx context.Context, txids []string) ([]*page.Tx, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	indexes := make(chan int, len(txids))
+	for i := range txids {
+		indexes <- i
+	}
+	close(indexes)
+	concurrency := int(s.c.Worker.Concurrency)
+	if concurrency <= 0 || concurrency > len(txids) {
+		concurrency = len(txids)
+	}
+	txs := make([]*page.Tx, len(txids))
+	var (
+		errOnce  sync.Once
+		fetchErr error
+	)
+	wg := &sync.WaitGroup{}
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ctx.Err() != nil {
+					return
+				}
+				tx, err := s.fetchTx(ctx, txids[i])
+				if err != nil {
+					errOnce.Do(func() {
+						fetchErr = err
+						cancel()
+					})
+					return
+				}
+				txs[i] = tx
+			}
+		}()
+	}
+	wg.Wait()
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
@@ -0,0 +1,393 @@
+package ord
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	collectionv1 "github.com/adshao/ordinals-indexer/api/collection/v1"
+	tokenv1 "github.com/adshao/ordinals-indexer/api/token/v1"
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+const verifyPageSize = 100
+
+// The fields of the verify diffs.
+const (
+	VerifyFieldCollection     = "collection"
+	VerifyFieldMax            = "max"
+	VerifyFieldSupply         = "supply"
+	VerifyFieldInscriptionUID = "inscription_uid"
+	VerifyFieldToken          = "token"
+	VerifyFieldTokenID        = "token_id"
+	VerifyFieldOwner          = "owner"
+)
+
+// Reference is the BRC-721 state of a reference indexer to verify against.
+type Reference interface {
+	// Collections returns a page of the collections ordered by inscription id,
+	// all the collections if tick is empty.
+	Collections(ctx context.Context, tick string, offset, limit int) ([]*biz.Collection, error)
+	// Tokens returns a page of the tokens of the collection ordered by token id.
+	Tokens(ctx context.Context, tick string, offset, limit int) ([]*biz.Token, error)
+}
+
+// VerifyDiff is a mismatch between our state and the reference, the empty
+// side of a collection or token diff is missing.
+type VerifyDiff struct {
+	Tick  string `json:"tick"`
+	Field string `json:"field"`
+	// InscriptionUID is the mint inscription of the token diffs.
+	InscriptionUID string `json:"inscription_uid,omitempty"`
+	Ours           string `json:"ours"`
+	Theirs         string `json:"theirs"`
+}
+
+// VerifyReport is the result of the verification.
+type VerifyReport struct {
+	Collections int           `json:"collections"`
+	Tokens      int           `json:"tokens"`
+	Diffs       []*VerifyDiff `json:"diffs"`
+}
+
+// WriteDiff writes the diffs grouped by collection, ours are prefixed by "-"
+// and the reference by "+".
+func (r *VerifyReport) WriteDiff(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	tick := ""
+	for i, d := range r.Diffs {
+		if i == 0 || d.Tick != tick {
+			tick = d.Tick
+			fmt.Fprintf(bw, "@@ collection %s @@\n", tick)
+		}
+		subject := d.Field
+		if d.InscriptionUID != "" && d.Field != VerifyFieldToken {
+			subject = fmt.Sprintf("token %s %s", d.InscriptionUID, d.Field)
+		}
+		missing := d.Field == VerifyFieldCollection || d.Field == VerifyFieldToken
+		if d.Ours != "" || !missing {
+			fmt.Fprintf(bw, "- %s %s\n", subject, d.Ours)
+		}
+		if d.Theirs != "" || !missing {
+			fmt.Fprintf(bw, "+ %s %s\n", subject, d.Theirs)
+		}
+	}
+	fmt.Fprintf(bw, "verified %d collection(s) and %d token(s), found %d mismatch(es)\n", r.Collections, r.Tokens, len(r.Diffs))
+	return bw.Flush()
+}
+
+// Verify compares the BRC-721 collections and tokens with the reference indexer,
+// the tokens are matched by their mint inscriptions.
+func (s *Syncer) Verify(ctx context.Context, ref Reference, tick string) (*VerifyReport, error) {
+	ours, err := s.verifyCollections(ctx, tick)
+	if err != nil {
+		return nil, err
+	}
+	theirs := make(map[string]*biz.Collection)
+	if err := pageThrough(func(offset int) (int, error) {
+		collections, err := ref.Collections(ctx, tick, offset, verifyPageSize)
+		for _, collection := range collections {
+			theirs[collection.Tick] = collection
+		}
+		return len(collections), err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list reference collections: %w", err)
+	}
+
+	report := &VerifyReport{}
+	for _, tick := range unionKeys(ours, theirs) {
+		our, their := ours[tick], theirs[tick]
+		if our == nil || their == nil {
+			d := &VerifyDiff{Tick: tick, Field: VerifyFieldCollection}
+			if our != nil {
+				d.Ours = our.InscriptionUID
+			} else {
+				d.Theirs = their.InscriptionUID
+			}
+			report.Diffs = append(report.Diffs, d)
+			continue
+		}
+		report.Collections++
+		report.Diffs = appendDiff(report.Diffs, &VerifyDiff{Tick: tick, Field: VerifyFieldInscriptionUID, Ours: our.InscriptionUID, Theirs: their.InscriptionUID})
+		report.Diffs = appendDiff(report.Diffs, &VerifyDiff{Tick: tick, Field: VerifyFieldMax, Ours: strconv.FormatUint(our.Max, 10), Theirs: strconv.FormatUint(their.Max, 10)})
+		report.Diffs = appendDiff(report.Diffs, &VerifyDiff{Tick: tick, Field: VerifyFieldSupply, Ours: strconv.FormatUint(our.Supply, 10), Theirs: strconv.FormatUint(their.Supply, 10)})
+		diffs, count, err := s.verifyTokens(ctx, ref, tick)
+		if err != nil {
+			return nil, err
+		}
+		report.Tokens += count
+		report.Diffs = append(report.Diffs, diffs...)
+	}
+	return report, nil
+}
+
+func (s *Syncer) verifyCollections(ctx context.Context, tick string) (map[string]*biz.Collection, error) {
+	ours := make(map[string]*biz.Collection)
+	err := pageThrough(func(offset int) (int, error) {
+		collections, err := s.collectionUc.ListCollections(ctx, &biz.CollectionListOption{
+			P:      biz.ProtocolTypeBRC721,
+			Tick:   tick,
+			Order:  "inscription_id",
+			Offset: offset,
+			Limit:  verifyPageSize,
+		})
+		for _, collection := range collections {
+			ours[collection.Tick] = collection
+		}
+		return len(collections), err
+	})
+	return ours, err
+}
+
+// verifyTokens compares the tokens of the collection, and returns the diffs and
+// the number of the tokens in both.
+func (s *Syncer) verifyTokens(ctx context.Context, ref Reference, tick string) ([]*VerifyDiff, int, error) {
+	ours := make(map[string]*biz.Token)
+	if err := pageThrough(func(offset int) (int, error) {
+		tokens, err := s.tokenUc.ListTokens(ctx, &biz.TokenListOption{
+			P:      biz.ProtocolTypeBRC721,
+			Tick:   tick,
+			Order:  "token_id",
+			Offset: offset,
+			Limit:  verifyPageSize,
+		})
+		for _, token := range tokens {
+			ours[token.InscriptionUID] = token
+		}
+		return len(tokens), err
+	}); err != nil {
+		return nil, 0, err
+	}
+	theirs := make(map[string]*biz.Token)
+	if err := pageThrough(func(offset int) (int, error) {
+		tokens, err := ref.Tokens(ctx, tick, offset, verifyPageSize)
+		for _, token := range tokens {
+			theirs[token.InscriptionUID] = token
+		}
+		return len(tokens), err
+	}); err != nil {
+		return nil, 0, fmt.Errorf("failed to list reference tokens of %s: %w", tick, err)
+	}
+
+	diffs := make([]*VerifyDiff, 0)
+	count := 0
+	uids := unionKeys(ours, theirs)
+	// report the tokens in the mint order.
+	sort.SliceStable(uids, func(i, j int) bool {
+		return tokenOrder(ours, theirs, uids[i]) < tokenOrder(ours, theirs, uids[j])
+	})
+	for _, uid := range uids {
+		our, their := ours[uid], theirs[uid]
+		if our == nil || their == nil {
+			d := &VerifyDiff{Tick: tick, Field: VerifyFieldToken, InscriptionUID: uid}
+			if our != nil {
+				d.Ours = fmt.Sprintf("#%d %s", our.TokenID, uid)
+			} else {
+				d.Theirs = fmt.Sprintf("#%d %s", their.TokenID, uid)
+			}
+			diffs = append(diffs, d)
+			continue
+		}
+		count++
+		diffs = appendDiff(diffs, &VerifyDiff{Tick: tick, Field: VerifyFieldTokenID, InscriptionUID: uid, Ours: strconv.FormatUint(our.TokenID, 10), Theirs: strconv.FormatUint(their.TokenID, 10)})
+		diffs = appendDiff(diffs, &VerifyDiff{Tick: tick, Field: VerifyFieldOwner, InscriptionUID: uid, Ours: our.Address, Theirs: their.Address})
+	}
+	return diffs, count, nil
+}
+
+func tokenOrder(ours, theirs map[string]*biz.Token, uid string) uint64 {
+	if token, ok := ours[uid]; ok {
+		return token.TokenID
+	}
+	return theirs[uid].TokenID
+}
+
+func appendDiff(diffs []*VerifyDiff, d *VerifyDiff) []*VerifyDiff {
+	if d.Ours == d.Theirs {
+		return diffs
+	}
+	return append(diffs, d)
+}
+
+// pageThrough calls list with the offsets until a page is not full.
+func pageThrough(list func(offset int) (int, error)) error {
+	for offset := 0; ; {
+		n, err := list(offset)
+		if err != nil {
+			return err
+		}
+		if n < verifyPageSize {
+			return nil
+		}
+		offset += n
+	}
+}
+
+func unionKeys[T any](a, b map[string]T) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// apiReference is the HTTP API of a reference ordinals-indexer.
+type apiReference struct {
+	baseURL string
+}
+
+// NewAPIReference returns the reference of the indexer serving the API at baseURL, eg: http://127.0.0.1:8000
+func NewAPIReference(baseURL string) Reference {
+	return &apiReference{baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (r *apiReference) Collections(ctx context.Context, tick string, offset, limit int) ([]*biz.Collection, error) {
+	var reply collectionv1.ListCollectionReply
+	if err := r.get(ctx, "/v1/collections", tick, "inscription_id", offset, limit, &reply); err != nil {
+		return nil, err
+	}
+	collections := make([]*biz.Collection, 0, len(reply.Data))
+	for _, m := range reply.Data {
+		collections = append(collections, &biz.Collection{
+			P:              m.P,
+			Tick:           m.Tick,
+			Max:            m.Max,
+			Supply:         m.Supply,
+			InscriptionID:  m.InscriptionId,
+			InscriptionUID: m.InscriptionUid,
+		})
+	}
+	return collections, nil
+}
+
+func (r *apiReference) Tokens(ctx context.Context, tick string, offset, limit int) ([]*biz.Token, error) {
+	var reply tokenv1.ListTokenReply
+	if err := r.get(ctx, "/v1/tokens", tick, "token_id", offset, limit, &reply); err != nil {
+		return nil, err
+	}
+	tokens := make([]*biz.Token, 0, len(reply.Data))
+	for _, m := range reply.Data {
+		tokens = append(tokens, &biz.Token{
+			P:              m.P,
+			Tick:           m.Tick,
+			TokenID:        m.TokenId,
+			Address:        m.Address,
+			InscriptionID:  m.InscriptionId,
+			InscriptionUID: m.InscriptionUid,
+		})
+	}
+	return tokens, nil
+}
+
+func (r *apiReference) get(ctx context.Context, path, tick, order string, offset, limit int, reply proto.Message) error {
+	query := url.Values{}
+	query.Set("p", biz.ProtocolTypeBRC721)
+	if tick != "" {
+		query.Set("tick", tick)
+	}
+	query.Set("order_by", order)
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	u := r.baseURL + path + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get %s: status code %d: %s", u, resp.StatusCode, body)
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, reply)
+}
+
+// snapshotReference is the state of the exported snapshot, the snapshot is
+// loaded into memory.
+type snapshotReference struct {
+	collections []*biz.Collection
+	tokens      map[string][]*biz.Token
+}
+
+// NewSnapshotReference returns the reference of the BRC-721 state in the exported snapshot.
+func NewSnapshotReference(r io.Reader) (Reference, error) {
+	sr, err := biz.NewSnapshotReader(r)
+	if err != nil {
+		return nil, err
+	}
+	ref := &snapshotReference{tokens: make(map[string][]*biz.Token)}
+	for {
+		record, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c := record.Collection; c != nil && c.P == biz.ProtocolTypeBRC721 {
+			ref.collections = append(ref.collections, c)
+		}
+		if t := record.Token; t != nil && t.P == biz.ProtocolTypeBRC721 {
+			ref.tokens[t.Tick] = append(ref.tokens[t.Tick], t)
+		}
+	}
+	sort.SliceStable(ref.collections, func(i, j int) bool {
+		return ref.collections[i].InscriptionID < ref.collections[j].InscriptionID
+	})
+	for _, tokens := range ref.tokens {
+		sort.SliceStable(tokens, func(i, j int) bool {
+			return tokens[i].TokenID < tokens[j].TokenID
+		})
+	}
+	return ref, nil
+}
+
+func (r *snapshotReference) Collections(ctx context.Context, tick string, offset, limit int) ([]*biz.Collection, error) {
+	collections := r.collections
+	if tick != "" {
+		collections = make([]*biz.Collection, 0, 1)
+		for _, collection := range r.collections {
+			if collection.Tick == tick {
+				collections = append(collections, collection)
+			}
+		}
+	}
+	return paginate(collections, offset, limit), nil
+}
+
+func (r *snapshotReference) Tokens(ctx context.Context, tick string, offset, limit int) ([]*biz.Token, error) {
+	return paginate(r.tokens[tick], offset, limit), nil
+}
+
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
@@ -0,0 +1,178 @@
+package ord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	collectionv1 "github.com/adshao/ordinals-indexer/api/collection/v1"
+	tokenv1 "github.com/adshao/ordinals-indexer/api/token/v1"
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
+
+func verifyUID(i int) string {
+	return fmt.Sprintf("%064di0", i)
+}
+
+// newReferenceServer serves the collections and tokens like the API of the reference indexer.
+func newReferenceServer(collections []*collectionv1.CollectionMessage, tokens []*tokenv1.TokenMessage) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		var reply proto.Message
+		switch req.URL.Path {
+		case "/v1/collections":
+			data := make([]*collectionv1.CollectionMessage, 0)
+			for _, c := range collections {
+				if q.Get("tick") == "" || c.Tick == q.Get("tick") {
+					data = append(data, c)
+				}
+			}
+			reply = &collectionv1.ListCollectionReply{Data: paginate(data, offset, limit)}
+		case "/v1/tokens":
+			data := make([]*tokenv1.TokenMessage, 0)
+			for _, t := range tokens {
+				if t.Tick == q.Get("tick") {
+					data = append(data, t)
+				}
+			}
+			reply = &tokenv1.ListTokenReply{Data: paginate(data, offset, limit)}
+		default:
+			http.NotFound(w, req)
+			return
+		}
+		body, _ := protojson.Marshal(reply)
+		w.Write(body)
+	}))
+}
+
+func (s *brc721SigTestSuite) createVerifyState() {
+	r := s.Require()
+	ctx := context.Background()
+	for i, tick := range []string{"ordinals", "ours"} {
+		collection, err := s.collectionUc.CreateCollection(ctx, &biz.Collection{
+			P:              biz.ProtocolTypeBRC721,
+			Tick:           tick,
+			Max:            1000,
+			Supply:         3,
+			InscriptionID:  int64(i + 1),
+			InscriptionUID: verifyUID(i + 1),
+		})
+		r.NoError(err)
+		if tick != "ordinals" {
+			continue
+		}
+		for j := 1; j <= 3; j++ {
+			_, err := s.tokenUc.CreateToken(ctx, &biz.Token{
+				P:              biz.ProtocolTypeBRC721,
+				Tick:           tick,
+				TokenID:        uint64(j),
+				Address:        "bc1palice",
+				InscriptionID:  int64(10 + j),
+				InscriptionUID: verifyUID(10 + j),
+				CollectionID:   collection.ID,
+			})
+			r.NoError(err)
+		}
+	}
+}
+
+func (s *brc721SigTestSuite) TestVerifyAPIReference() {
+	s.createVerifyState()
+	server := newReferenceServer([]*collectionv1.CollectionMessage{
+		{P: "brc-721", Tick: "ordinals", Max: 1000, Supply: 4, InscriptionId: 1, InscriptionUid: verifyUID(1)},
+		{P: "brc-721", Tick: "theirs", Max: 10, InscriptionId: 3, InscriptionUid: verifyUID(3)},
+	}, []*tokenv1.TokenMessage{
+		{P: "brc-721", Tick: "ordinals", TokenId: 1, Address: "bc1palice", InscriptionId: 11, InscriptionUid: verifyUID(11)},
+		{P: "brc-721", Tick: "ordinals", TokenId: 2, Address: "bc1pbob", InscriptionId: 12, InscriptionUid: verifyUID(12)},
+		{P: "brc-721", Tick: "ordinals", TokenId: 3, Address: "bc1palice", InscriptionId: 14, InscriptionUid: verifyUID(14)},
+		{P: "brc-721", Tick: "ordinals", TokenId: 4, Address: "bc1palice", InscriptionId: 13, InscriptionUid: verifyUID(13)},
+	})
+	defer server.Close()
+
+	r := s.Require()
+	report, err := s.syncer.Verify(context.Background(), NewAPIReference(server.URL), "")
+	r.NoError(err)
+	r.Equal(1, report.Collections)
+	r.Equal(3, report.Tokens)
+	r.Equal([]*VerifyDiff{
+		{Tick: "ordinals", Field: VerifyFieldSupply, Ours: "3", Theirs: "4"},
+		{Tick: "ordinals", Field: VerifyFieldOwner, InscriptionUID: verifyUID(12), Ours: "bc1palice", Theirs: "bc1pbob"},
+		{Tick: "ordinals", Field: VerifyFieldTokenID, InscriptionUID: verifyUID(13), Ours: "3", Theirs: "4"},
+		{Tick: "ordinals", Field: VerifyFieldToken, InscriptionUID: verifyUID(14), Theirs: "#3 " + verifyUID(14)},
+		{Tick: "ours", Field: VerifyFieldCollection, Ours: verifyUID(2)},
+		{Tick: "theirs", Field: VerifyFieldCollection, Theirs: verifyUID(3)},
+	}, report.Diffs)
+
+	var buf bytes.Buffer
+	r.NoError(report.WriteDiff(&buf))
+	r.Equal(strings.Join([]string{
+		"@@ collection ordinals @@",
+		"- supply 3",
+		"+ supply 4",
+		"- token " + verifyUID(12) + " owner bc1palice",
+		"+ token " + verifyUID(12) + " owner bc1pbob",
+		"- token " + verifyUID(13) + " token_id 3",
+		"+ token " + verifyUID(13) + " token_id 4",
+		"+ token #3 " + verifyUID(14),
+		"@@ collection ours @@",
+		"- collection " + verifyUID(2),
+		"@@ collection theirs @@",
+		"+ collection " + verifyUID(3),
+		"verified 1 collection(s) and 3 token(s), found 6 mismatch(es)",
+		"",
+	}, "\n"), buf.String())
+
+	// verify a single collection.
+	report, err = s.syncer.Verify(context.Background(), NewAPIReference(server.URL), "ours")
+	r.NoError(err)
+	r.Len(report.Diffs, 1)
+
+	_, err = s.syncer.Verify(context.Background(), NewAPIReference(server.URL+"/404"), "")
+	r.ErrorContains(err, "status code 404")
+}
+
+func (s *brc721SigTestSuite) TestVerifySnapshotReference() {
+	s.createVerifyState()
+	var buf bytes.Buffer
+	w, err := biz.NewSnapshotWriter(&buf, &biz.SnapshotHeader{Version: biz.SnapshotVersion})
+	r := s.Require()
+	r.NoError(err)
+	for i, tick := range []string{"ours", "ordinals"} {
+		r.NoError(w.Write(&biz.SnapshotRecord{Collection: &biz.Collection{
+			P: biz.ProtocolTypeBRC721, Tick: tick, Max: 1000, Supply: 3, InscriptionID: int64(2 - i), InscriptionUID: verifyUID(2 - i),
+		}}))
+	}
+	// more tokens than a page.
+	for i := verifyPageSize + 1; i >= 1; i-- {
+		address := "bc1palice"
+		if i > 3 {
+			address = "bc1pbob"
+		}
+		r.NoError(w.Write(&biz.SnapshotRecord{Token: &biz.Token{
+			P: biz.ProtocolTypeBRC721, Tick: "ordinals", TokenID: uint64(i), Address: address, InscriptionID: int64(10 + i), InscriptionUID: verifyUID(10 + i),
+		}}))
+	}
+	_, err = w.Close()
+	r.NoError(err)
+	ref, err := NewSnapshotReference(&buf)
+	r.NoError(err)
+
+	report, err := s.syncer.Verify(context.Background(), ref, "")
+	r.NoError(err)
+	r.Equal(2, report.Collections)
+	r.Equal(3, report.Tokens)
+	r.Len(report.Diffs, verifyPageSize-2)
+	r.Equal(&VerifyDiff{Tick: "ordinals", Field: VerifyFieldToken, InscriptionUID: verifyUID(14), Theirs: "#4 " + verifyUID(14)}, report.Diffs[0])
+
+	_, err = NewSnapshotReference(strings.NewReader("{}"))
+	r.ErrorContains(err, "invalid snapshot")
+}
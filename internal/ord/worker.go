@@ -1,66 +1,70 @@
 package ord
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 
+	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/data"
 	"github.com/adshao/ordinals-indexer/internal/ord/page"
+	"github.com/adshao/ordinals-indexer/internal/ord/parser"
 
 	"github.com/go-kratos/kratos/v2/log"
 )
 
-var (
-	httpGet = http.Get
-)
-
 type Worker struct {
 	wid        int
 	baseURL    string
 	pageParser page.PageParser
-	data       *data.Data
-	uidChan    chan string
-	resultChan chan (*result)
-	stopC      chan struct{}
-	logger     *log.Helper
+	parsers    []parser.Parser
+	// maxContentLength skips the content larger than it in bytes.
+	maxContentLength uint64
+	network          *biz.Network
+	data             *data.Data
+	uidChan          chan string
+	resultChan       chan (*result)
+	logger           *log.Helper
 }
 
-func (w *Worker) Start() {
+// Start processes the inscriptions of uidChan until it is closed or the context is
+// done, the results are sent to resultChan.
+func (w *Worker) Start(ctx context.Context) {
 	for {
 		select {
-		case uid := <-w.uidChan:
+		case uid, ok := <-w.uidChan:
+			if !ok {
+				return
+			}
 			w.logger.Debugf("[worker %d]: processing inscription %s", w.wid, uid)
-			w.resultChan <- w.processInscription(uid)
-		case <-w.stopC:
+			w.resultChan <- w.processInscription(ctx, uid)
+		case <-ctx.Done():
 			w.logger.Infof("[worker %d]: stopping", w.wid)
 			return
 		}
 	}
 }
 
-func (w *Worker) processInscription(uid string) *result {
-	info, err := w.parseInscriptionInfo(uid)
+func (w *Worker) processInscription(ctx context.Context, uid string) *result {
+	info, err := w.parseInscriptionInfo(ctx, uid)
 	if info == nil {
 		info = &page.Inscription{}
 	}
 	if info.UID == "" {
 		info.UID = uid
 	}
-	// FIXME: inscription_id is not always available
-	if info.ID == 0 {
-		if err == nil {
-			err = fmt.Errorf("failed to get inscription_id")
-		}
+	// the inscription #0 and the cursed inscriptions of negative numbers are valid,
+	// only the failure to parse the page is an error.
+	if err != nil {
 		return &result{info: info, err: err}
 	}
 	w.logger.Debugf("[worker %d] parsed inscription %d", w.wid, info.ID)
-	return &result{info: info, err: err}
+	return &result{info: info}
 }
 
-func (w *Worker) parseInscriptionInfo(uid string) (*page.Inscription, error) {
+func (w *Worker) parseInscriptionInfo(ctx context.Context, uid string) (*page.Inscription, error) {
 	inscriptionPage := page.NewInscriptionPage(uid)
 	w.logger.Debugf("[worker %d] fetching %s...", w.wid, inscriptionPage.URL())
-	data, err := w.pageParser.Parse(inscriptionPage)
+	data, err := w.pageParser.Parse(ctx, inscriptionPage)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +72,16 @@ func (w *Worker) parseInscriptionInfo(uid string) (*page.Inscription, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid inscription page: %T", data)
 	}
-	content, err := w.parseContent(uid)
+	// the inscriptions on the unspendable outputs have no address, the addresses that
+	// can't be decoded, eg: of a new witness version, are dropped the same way instead
+	// of failing the batch.
+	if inscription.Address != "" {
+		if err := w.network.ValidateAddress(inscription.Address); err != nil {
+			w.logger.Warnf("[worker %d] inscription %s is indexed without its address: %v", w.wid, uid, err)
+			inscription.Address = ""
+		}
+	}
+	content, err := w.parseContent(ctx, uid, inscription)
 	if err != nil {
 		return nil, err
 	}
@@ -76,10 +89,16 @@ func (w *Worker) parseInscriptionInfo(uid string) (*page.Inscription, error) {
 	return inscription, nil
 }
 
-func (w *Worker) parseContent(uid string) (*page.Content, error) {
-	contentPage := page.NewContentPage(uid)
+// parseContent fetches and parses the content of the inscription, the content
+// is skipped without fetching if no parser accepts its type or length.
+func (w *Worker) parseContent(ctx context.Context, uid string, inscription *page.Inscription) (*page.Content, error) {
+	contentPage := page.NewContentPage(uid, inscription.ContentType, w.maxContentLength, w.parsers)
+	if !contentPage.Accepted(inscription.ContentLength) {
+		w.logger.Debugf("[worker %d] skipped content of %s: %s %d bytes", w.wid, uid, inscription.ContentType, inscription.ContentLength)
+		return &page.Content{Type: page.ContentTypeSkipped}, nil
+	}
 	w.logger.Debugf("[worker %d] fetching %s...", w.wid, contentPage.URL())
-	data, err := w.pageParser.Parse(contentPage)
+	data, err := w.pageParser.Parse(ctx, contentPage)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package ord
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
@@ -9,23 +10,25 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/ord/page"
 	"github.com/adshao/ordinals-indexer/internal/ord/parser"
 )
 
+var mainnet, _ = biz.ParseNetwork(biz.NetworkMainnet)
+
 func TestWorkerParseBRC721DeployInscription(t *testing.T) {
 	logger := log.With(log.NewStdLogger(os.Stdout),
 		"caller", log.DefaultCaller,
 	)
 
 	worker := &Worker{
-		wid:        1,
-		baseURL:    "http://localhost:8080",
-		data:       nil,
-		uidChan:    make(chan string, 10),
-		resultChan: make(chan *result, 10),
-		stopC:      make(chan struct{}),
-		logger:     log.NewHelper(logger),
+		wid:     1,
+		baseURL: "http://localhost:8080",
+		parsers: parser.BRC721Parsers(),
+		network: mainnet,
+		data:    nil,
+		logger:  log.NewHelper(logger),
 	}
 
 	mockPageParser := &MockPageParser{}
@@ -37,7 +40,7 @@ func TestWorkerParseBRC721DeployInscription(t *testing.T) {
 		Address:       "bc1putjs4fvkp3uaq6nhph7h2e7pmpwduq6zrxkt5kyyxe4rn47yrwzqup8lfu",
 		OutputValue:   10000,
 		ContentLength: 3440,
-		ContentType:   "image/webp",
+		ContentType:   "text/plain;charset=utf-8",
 		Timestamp:     time.Date(2023, 5, 28, 3, 28, 17, 0, time.UTC),
 		GenesisHeight: 791720,
 		GenesisFee:    21000,
@@ -62,7 +65,7 @@ func TestWorkerParseBRC721DeployInscription(t *testing.T) {
 		Type: parser.NameBRC721Deploy,
 	}, nil)
 
-	info, err := worker.parseInscriptionInfo("3501f4fa1f754e5e7c58a153efbcec92a93b2ff9721a215bec6cdb9dd48d96abi0")
+	info, err := worker.parseInscriptionInfo(context.Background(), "3501f4fa1f754e5e7c58a153efbcec92a93b2ff9721a215bec6cdb9dd48d96abi0")
 	r := require.New(t)
 	r.NoError(err)
 	r.Equal(int64(9553787), info.ID, "inscription_id")
@@ -89,6 +92,20 @@ func TestWorkerParseBRC721DeployInscription(t *testing.T) {
 	r.Equal("The ChatGPT 09/May/2023 Financial institutions on the precipice as three banks collapse in 2023.", o.Meta.Description, "content.Meta.Description")
 	r.Equal("data:image/svg+xml;base64,PHN2ZyB4bWxuczpyZGY9Imh0dHA6Ly93d3cudzMub3JnLzE5OTkvMDIvMjItcmRmLXN5bnRheC1ucyMiIHhtbG5zPSJodHRwOi8vd3d3LnczLm9yZy8yMDAwL3N2ZyIgaGVpZ2h0PSI2NCIgd2lkdGg9IjY0IiB2ZXJzaW9uPSIxLjEiIHhtbG5zOmNjPSJodHRwOi8vY3JlYXRpdmVjb21tb25zLm9yZy9ucyMiIHhtbG5zOmRjPSJodHRwOi8vcHVybC5vcmcvZGMvZWxlbWVudHMvMS4xLyI+CjxnIHRyYW5zZm9ybT0idHJhbnNsYXRlKDAuMDA2MzA4NzYsLTAuMDAzMDE5ODQpIj4KPHBhdGggZmlsbD0iI2Y3OTMxYSIgZD0ibTYzLjAzMywzOS43NDRjLTQuMjc0LDE3LjE0My0yMS42MzcsMjcuNTc2LTM4Ljc4MiwyMy4zMDEtMTcuMTM4LTQuMjc0LTI3LjU3MS0yMS42MzgtMjMuMjk1LTM4Ljc4LDQuMjcyLTE3LjE0NSwyMS42MzUtMjcuNTc5LDM4Ljc3NS0yMy4zMDUsMTcuMTQ0LDQuMjc0LDI3LjU3NiwyMS42NCwyMy4zMDIsMzguNzg0eiIvPgo8cGF0aCBmaWxsPSIjRkZGIiBkPSJtNDYuMTAzLDI3LjQ0NGMwLjYzNy00LjI1OC0yLjYwNS02LjU0Ny03LjAzOC04LjA3NGwxLjQzOC01Ljc2OC0zLjUxMS0wLjg3NS0xLjQsNS42MTZjLTAuOTIzLTAuMjMtMS44NzEtMC40NDctMi44MTMtMC42NjJsMS40MS01LjY1My0zLjUwOS0wLjg3NS0xLjQzOSw1Ljc2NmMtMC43NjQtMC4xNzQtMS41MTQtMC4zNDYtMi4yNDItMC41MjdsMC4wMDQtMC4wMTgtNC44NDItMS4yMDktMC45MzQsMy43NXMyLjYwNSwwLjU5NywyLjU1LDAuNjM0YzEuNDIyLDAuMzU1LDEuNjc5LDEuMjk2LDEuNjM2LDIuMDQybC0xLjYzOCw2LjU3MWMwLjA5OCwwLjAyNSwwLjIyNSwwLjA2MSwwLjM2NSwwLjExNy0wLjExNy0wLjAyOS0wLjI0Mi0wLjA2MS0wLjM3MS0wLjA5MmwtMi4yOTYsOS4yMDVjLTAuMTc0LDAuNDMyLTAuNjE1LDEuMDgtMS42MDksMC44MzQsMC4wMzUsMC4wNTEtMi41NTItMC42MzctMi41NTItMC42MzdsLTEuNzQzLDQuMDE5LDQuNTY5LDEuMTM5YzAuODUsMC4yMTMsMS42ODMsMC40MzYsMi41MDMsMC42NDZsLTEuNDUzLDUuODM0LDMuNTA3LDAuODc1LDEuNDM5LTUuNzcyYzAuOTU4LDAuMjYsMS44ODgsMC41LDIuNzk4LDAuNzI2bC0xLjQzNCw1Ljc0NSwzLjUxMSwwLjg3NSwxLjQ1My01LjgyM2M1Ljk4NywxLjEzMywxMC40ODksMC42NzYsMTIuMzg0LTQuNzM5LDEuNTI3LTQuMzYtMC4wNzYtNi44NzUtMy4yMjYtOC41MTUsMi4yOTQtMC41MjksNC4wMjItMi4wMzgsNC40ODMtNS4xNTV6bS04LjAyMiwxMS4yNDljLTEuMDg1LDQuMzYtOC40MjYsMi4wMDMtMTAuODA2LDEuNDEybDEuOTI4LTcuNzI5YzIuMzgsMC41OTQsMTAuMDEyLDEuNzcsOC44NzgsNi4zMTd6bTEuMDg2LTExLjMxMmMtMC45OSwzLjk2Ni03LjEsMS45NTEtOS4wODIsMS40NTdsMS43NDgtNy4wMWMxLjk4MiwwLjQ5NCw4LjM2NSwxLjQxNiw3LjMzNCw1LjU1M3oiLz4KPC9nPgo8L3N2Zz4=", o.Meta.Image, "content.Meta.Image")
 	r.Nil(o.BaseURI, "content.BaseURI")
+
+	// the address not of the network of the worker is dropped.
+	worker.network, _ = biz.ParseNetwork(biz.NetworkTestnet)
+	mockPageParser = &MockPageParser{}
+	worker.pageParser = mockPageParser
+	mockPageParser.On("Parse", mock.Anything).Once().Return(&page.Inscription{
+		ID:      9553787,
+		UID:     "3501f4fa1f754e5e7c58a153efbcec92a93b2ff9721a215bec6cdb9dd48d96abi0",
+		Address: "bc1putjs4fvkp3uaq6nhph7h2e7pmpwduq6zrxkt5kyyxe4rn47yrwzqup8lfu",
+	}, nil)
+	mockPageParser.On("Parse", mock.Anything).Return(&page.Content{}, nil)
+	info, err = worker.parseInscriptionInfo(context.Background(), "3501f4fa1f754e5e7c58a153efbcec92a93b2ff9721a215bec6cdb9dd48d96abi0")
+	r.NoError(err)
+	r.Equal("", info.Address, "address")
 }
 
 func TestWorkerParseBRC721MintInscription(t *testing.T) {
@@ -97,13 +114,12 @@ func TestWorkerParseBRC721MintInscription(t *testing.T) {
 	)
 
 	worker := &Worker{
-		wid:        1,
-		baseURL:    "http://localhost:8080",
-		data:       nil,
-		uidChan:    make(chan string, 10),
-		resultChan: make(chan *result, 10),
-		stopC:      make(chan struct{}),
-		logger:     log.NewHelper(logger),
+		wid:     1,
+		baseURL: "http://localhost:8080",
+		parsers: parser.BRC721Parsers(),
+		network: mainnet,
+		data:    nil,
+		logger:  log.NewHelper(logger),
 	}
 
 	mockPageParser := &MockPageParser{}
@@ -133,7 +149,7 @@ func TestWorkerParseBRC721MintInscription(t *testing.T) {
 		Type: parser.NameBRC721Mint,
 	}, nil)
 
-	info, err := worker.parseInscriptionInfo("8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0")
+	info, err := worker.parseInscriptionInfo(context.Background(), "8417b71ef08dd2c824b8c5712f558228fcb67032a6589185d12b67768c319564i0")
 	r := require.New(t)
 	r.NoError(err)
 	r.Equal(int64(4986756), info.ID, "inscription_id")
@@ -163,13 +179,12 @@ func TestWorkerParseBRC721DeployWithMeta(t *testing.T) {
 	)
 
 	worker := &Worker{
-		wid:        1,
-		baseURL:    "http://localhost:8080",
-		data:       nil,
-		uidChan:    make(chan string, 10),
-		resultChan: make(chan *result, 10),
-		stopC:      make(chan struct{}),
-		logger:     log.NewHelper(logger),
+		wid:     1,
+		baseURL: "http://localhost:8080",
+		parsers: parser.BRC721Parsers(),
+		network: mainnet,
+		data:    nil,
+		logger:  log.NewHelper(logger),
 	}
 
 	mockPageParser := &MockPageParser{}
@@ -204,7 +219,7 @@ func TestWorkerParseBRC721DeployWithMeta(t *testing.T) {
 		Type: parser.NameBRC721Deploy,
 	}, nil)
 
-	info, err := worker.parseInscriptionInfo("423992a9468b935e2e04234c0c5232f3d8f7acd0e1d867e9797cee3941bdc702i0")
+	info, err := worker.parseInscriptionInfo(context.Background(), "423992a9468b935e2e04234c0c5232f3d8f7acd0e1d867e9797cee3941bdc702i0")
 	r := require.New(t)
 	r.NoError(err)
 	r.Equal(int64(9553787), info.ID, "inscription_id")
@@ -231,3 +246,56 @@ func TestWorkerParseBRC721DeployWithMeta(t *testing.T) {
 	r.Equal("Taking the birth date of Bitcoin as the Genesis Yuan, brc721 will open a new round of blockchain legends.", o.Meta.Description, "content.Meta.Description")
 	r.Nil(o.BaseURI, "content.BaseURI")
 }
+
+func TestWorkerSkipContent(t *testing.T) {
+	logger := log.With(log.NewStdLogger(os.Stdout),
+		"caller", log.DefaultCaller,
+	)
+
+	worker := &Worker{
+		wid:              1,
+		baseURL:          "http://localhost:8080",
+		parsers:          parser.BRC721Parsers(),
+		maxContentLength: 1024,
+		logger:           log.NewHelper(logger),
+	}
+
+	r := require.New(t)
+	for _, inscription := range []*page.Inscription{
+		{ID: 9553787, ContentLength: 3440, ContentType: "image/webp"},
+		{ID: 9553788, ContentLength: 3440, ContentType: "text/plain;charset=utf-8"},
+	} {
+		mockPageParser := &MockPageParser{}
+		worker.pageParser = mockPageParser
+		mockPageParser.On("Parse", mock.Anything).Once().Return(inscription, nil)
+
+		// the content is not fetched.
+		info, err := worker.parseInscriptionInfo(context.Background(), "3501f4fa1f754e5e7c58a153efbcec92a93b2ff9721a215bec6cdb9dd48d96abi0")
+		r.NoError(err)
+		r.Equal(page.ContentTypeSkipped, info.Content.Type, inscription.ContentType)
+		mockPageParser.AssertExpectations(t)
+	}
+}
+
+func TestWorkerInscriptionNumbers(t *testing.T) {
+	worker := &Worker{
+		wid:              1,
+		baseURL:          "http://localhost:8080",
+		parsers:          parser.BRC721Parsers(),
+		maxContentLength: 1024,
+		network:          mainnet,
+		logger:           log.NewHelper(log.GetLogger()),
+	}
+
+	r := require.New(t)
+	// the inscription #0 and the cursed inscriptions are not failures.
+	for _, id := range []int64{0, -5} {
+		mockPageParser := &MockPageParser{}
+		worker.pageParser = mockPageParser
+		mockPageParser.On("Parse", mock.Anything).Once().Return(&page.Inscription{ID: id, Cursed: id < 0, ContentType: "image/png"}, nil)
+		res := worker.processInscription(context.Background(), "6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i0")
+		r.NoError(res.err)
+		r.Equal(id, res.info.ID)
+		r.Equal("6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i0", res.info.UID)
+	}
+}
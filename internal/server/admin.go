@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+
+	adminv1 "github.com/adshao/ordinals-indexer/api/admin/v1"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+	adminOperation      = "/api.admin.v1.Admin/"
+)
+
+// adminAuth authenticates the requests of the admin API by the bearer token of the
+// admin config, the other APIs are public. The token is required once the admin API
+// is enabled.
+func adminAuth(c *conf.Server) (middleware.Middleware, error) {
+	token := c.GetAdmin().GetToken()
+	if c.GetAdmin().GetEnabled() && token == "" {
+		return nil, errors.New("server.admin.enabled requires server.admin.token")
+	}
+	auth := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || token == "" || !validBearer(tr.RequestHeader().Get(authorizationHeader), token) {
+				return nil, adminv1.ErrorUnauthorized("invalid admin token")
+			}
+			return handler(ctx, req)
+		}
+	}
+	return selector.Server(auth).Prefix(adminOperation).Build(), nil
+}
+
+func validBearer(header, token string) bool {
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return false
+	}
+	given := strings.TrimPrefix(header, bearerPrefix)
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
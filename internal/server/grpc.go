@@ -1,9 +1,11 @@
 package server
 
 import (
+	adminv1 "github.com/adshao/ordinals-indexer/api/admin/v1"
 	collectionv1 "github.com/adshao/ordinals-indexer/api/collection/v1"
 	inscriptionv1 "github.com/adshao/ordinals-indexer/api/inscription/v1"
 	tokenv1 "github.com/adshao/ordinals-indexer/api/token/v1"
+	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/conf"
 	"github.com/adshao/ordinals-indexer/internal/service"
 
@@ -13,10 +15,20 @@ import (
 )
 
 // NewGRPCServer new a gRPC server.
-func NewGRPCServer(c *conf.Server, collection *service.CollectionService, token *service.TokenService, inscription *service.InscriptionService, logger log.Logger) *grpc.Server {
+func NewGRPCServer(c *conf.Server, collection *service.CollectionService, token *service.TokenService, inscription *service.InscriptionService, admin *service.AdminService, network *biz.Network, logger log.Logger) (*grpc.Server, error) {
+	selector, err := networkSelector(c, network)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := adminAuth(c)
+	if err != nil {
+		return nil, err
+	}
 	var opts = []grpc.ServerOption{
 		grpc.Middleware(
 			recovery.Recovery(),
+			selector,
+			auth,
 		),
 	}
 	if c.Grpc.Network != "" {
@@ -32,5 +44,8 @@ func NewGRPCServer(c *conf.Server, collection *service.CollectionService, token
 	tokenv1.RegisterTokenServer(srv, token)
 	collectionv1.RegisterCollectionServer(srv, collection)
 	inscriptionv1.RegisterInscriptionServer(srv, inscription)
-	return srv
+	if c.Admin != nil && c.Admin.Enabled {
+		adminv1.RegisterAdminServer(srv, admin)
+	}
+	return srv, nil
 }
@@ -1,9 +1,11 @@
 package server
 
 import (
+	adminv1 "github.com/adshao/ordinals-indexer/api/admin/v1"
 	collectionv1 "github.com/adshao/ordinals-indexer/api/collection/v1"
 	inscriptionv1 "github.com/adshao/ordinals-indexer/api/inscription/v1"
 	tokenv1 "github.com/adshao/ordinals-indexer/api/token/v1"
+	"github.com/adshao/ordinals-indexer/internal/biz"
 	"github.com/adshao/ordinals-indexer/internal/conf"
 	"github.com/adshao/ordinals-indexer/internal/service"
 
@@ -15,14 +17,24 @@ import (
 )
 
 // NewHTTPServer new an HTTP server.
-func NewHTTPServer(c *conf.Server, collection *service.CollectionService, token *service.TokenService, inscription *service.InscriptionService, logger log.Logger) *http.Server {
+func NewHTTPServer(c *conf.Server, collection *service.CollectionService, token *service.TokenService, inscription *service.InscriptionService, admin *service.AdminService, network *biz.Network, logger log.Logger) (*http.Server, error) {
 	json.MarshalOptions = protojson.MarshalOptions{
 		EmitUnpopulated: true,
 		UseProtoNames:   true,
 	}
+	selector, err := networkSelector(c, network)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := adminAuth(c)
+	if err != nil {
+		return nil, err
+	}
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
+			selector,
+			auth,
 		),
 	}
 	if c.Http.Network != "" {
@@ -38,5 +50,9 @@ func NewHTTPServer(c *conf.Server, collection *service.CollectionService, token
 	collectionv1.RegisterCollectionHTTPServer(srv, collection)
 	tokenv1.RegisterTokenHTTPServer(srv, token)
 	inscriptionv1.RegisterInscriptionHTTPServer(srv, inscription)
-	return srv
+	srv.Route("/").GET("/v1/inscriptions/{uid}/content", inscription.GetInscriptionContent)
+	if c.Admin != nil && c.Admin.Enabled {
+		adminv1.RegisterAdminHTTPServer(srv, admin)
+	}
+	return srv, nil
 }
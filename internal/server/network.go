@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/conf"
+)
+
+const (
+	networkHeader = "X-Network"
+	networkQuery  = "network"
+)
+
+// networkSelector selects the network of the request by the X-Network header or
+// the network query, the ord network by default. The networks not served are rejected.
+func networkSelector(c *conf.Server, network *biz.Network) (middleware.Middleware, error) {
+	served := map[string]bool{network.Name: true}
+	for _, name := range c.GetNetworks() {
+		n, err := biz.ParseNetwork(name)
+		if err != nil {
+			return nil, err
+		}
+		served[n.Name] = true
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			name := network.Name
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if v := tr.RequestHeader().Get(networkHeader); v != "" {
+					name = v
+				}
+				if ht, ok := tr.(http.Transporter); ok {
+					if v := ht.Request().URL.Query().Get(networkQuery); v != "" {
+						name = v
+					}
+				}
+			}
+			name = strings.ToLower(name)
+			if !served[name] {
+				return nil, errors.BadRequest("NETWORK_NOT_SERVED", fmt.Sprintf("network %s is not served", name))
+			}
+			return handler(biz.NewNetworkContext(ctx, name), req)
+		}
+	}, nil
+}
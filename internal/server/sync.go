@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/adshao/ordinals-indexer/internal/conf"
+	"github.com/adshao/ordinals-indexer/internal/ord"
+)
+
+// SyncServer runs the syncer in the server process as a transport server, it
+// shares the data and the usecases with the APIs. It is a no-op unless enabled.
+type SyncServer struct {
+	enabled bool
+	syncer  *ord.Syncer
+	log     *log.Helper
+
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSyncServer new a sync server. It requires the leader election, as every replica
+// of the server would sync the same database otherwise.
+func NewSyncServer(c *conf.Server, o *conf.Ord, syncer *ord.Syncer, logger log.Logger) (*SyncServer, error) {
+	enabled := c.GetSync().GetEnabled()
+	if enabled && !o.GetLeader().GetEnabled() {
+		return nil, errors.New("server.sync.enabled requires ord.leader.enabled")
+	}
+	return &SyncServer{
+		enabled: enabled,
+		syncer:  syncer,
+		log:     log.NewHelper(logger),
+	}, nil
+}
+
+// Start runs the syncer until it is stopped.
+func (s *SyncServer) Start(ctx context.Context) error {
+	if !s.enabled {
+		return nil
+	}
+	s.lock.Lock()
+	if s.done != nil {
+		s.lock.Unlock()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	done := s.done
+	s.lock.Unlock()
+	defer close(done)
+
+	s.log.Info("[sync] server starting")
+	return s.syncer.Run(ctx)
+}
+
+// Stop stops the syncer, and waits for the batch in flight to be drained until
+// the context is done.
+func (s *SyncServer) Stop(ctx context.Context) error {
+	s.lock.Lock()
+	cancel, done := s.cancel, s.done
+	s.lock.Unlock()
+	if done == nil {
+		return nil
+	}
+	s.log.Info("[sync] server stopping")
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	pb "github.com/adshao/ordinals-indexer/api/admin/v1"
+	"github.com/adshao/ordinals-indexer/internal/ord"
+)
+
+type AdminService struct {
+	pb.UnimplementedAdminServer
+
+	syncer *ord.Syncer
+	log    *log.Helper
+}
+
+func NewAdminService(syncer *ord.Syncer, logger log.Logger) *AdminService {
+	return &AdminService{
+		syncer: syncer,
+		log:    log.NewHelper(logger),
+	}
+}
+
+func (s *AdminService) ReprocessInscription(ctx context.Context, req *pb.ReprocessInscriptionRequest) (*pb.ReprocessInscriptionReply, error) {
+	if req.Uid == "" {
+		return nil, pb.ErrorInvalidParameters("missing inscription uid")
+	}
+	res, err := s.syncer.Reprocess(ctx, req.Uid)
+	if errors.Is(err, ord.ErrNotLeader) {
+		return nil, pb.ErrorNotLeader("the inscription is re-processed by the leader of the syncers only")
+	}
+	if err != nil {
+		return nil, err
+	}
+	reply := &pb.ReprocessInscriptionReply{
+		Uid:           res.UID,
+		InscriptionId: res.InscriptionID,
+		Parser:        res.Parser,
+		Changed:       res.Changed,
+		SkipReason:    res.SkipReason,
+	}
+	if res.Collection != nil {
+		reply.Tick = res.Collection.Tick
+		reply.CollectionCreated = true
+	}
+	if res.Token != nil {
+		reply.Tick = res.Token.Tick
+		reply.TokenId = &res.Token.TokenID
+	}
+	return reply, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/log"
 	structpb "google.golang.org/protobuf/types/known/structpb"
@@ -17,13 +18,15 @@ type CollectionService struct {
 
 	p                 page.PageParser
 	collectionUsecase *biz.CollectionUsecase
+	traitUsecase      *biz.TraitUsecase
 	log               *log.Helper
 }
 
-func NewCollectionService(p page.PageParser, collectionUsecase *biz.CollectionUsecase, logger log.Logger) *CollectionService {
+func NewCollectionService(p page.PageParser, collectionUsecase *biz.CollectionUsecase, traitUsecase *biz.TraitUsecase, logger log.Logger) *CollectionService {
 	return &CollectionService{
 		p:                 p,
 		collectionUsecase: collectionUsecase,
+		traitUsecase:      traitUsecase,
 		log:               log.NewHelper(logger),
 	}
 }
@@ -87,6 +90,75 @@ func (s *CollectionService) ListCollections(ctx context.Context, req *pb.ListCol
 	}, nil
 }
 
+func (s *CollectionService) SearchCollections(ctx context.Context, req *pb.SearchCollectionsRequest) (*pb.ListCollectionReply, error) {
+	if strings.TrimSpace(req.Q) == "" {
+		return nil, pb.ErrorInvalidParameters("missing search query")
+	}
+	switch req.OrderBy {
+	case "", biz.CollectionSearchOrderRelevance, biz.CollectionSearchOrderSupply, biz.CollectionSearchOrderRecentMint:
+	default:
+		return nil, pb.ErrorInvalidParameters("invalid order_by: %s", req.OrderBy)
+	}
+	opt := &biz.CollectionSearchOption{
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+		P:      req.P,
+		Query:  req.Q,
+		Fuzzy:  req.Fuzzy,
+		Order:  req.OrderBy,
+	}
+	collections, err := s.collectionUsecase.SearchCollections(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	totalCount, err := s.collectionUsecase.CountSearchCollections(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	var data []*pb.CollectionMessage
+	for _, collection := range collections {
+		data = append(data, s.fromBizCollection(collection))
+	}
+	return &pb.ListCollectionReply{
+		Data: data,
+		Paging: &pb.Paging{
+			TotalCount: uint64(totalCount),
+			Count:      uint64(len(data)),
+		},
+	}, nil
+}
+
+func (s *CollectionService) GetCollectionTraits(ctx context.Context, req *pb.GetCollectionTraitsRequest) (*pb.GetCollectionTraitsReply, error) {
+	if req.P == "" {
+		req.P = biz.ProtocolTypeBRC721
+	}
+	collection, err := s.collectionUsecase.GetCollectionByTick(ctx, req.P, req.Tick)
+	if err != nil {
+		return nil, err
+	}
+	if collection == nil {
+		return nil, pb.ErrorCollectionNotFound("collection not found: %s", req.Tick)
+	}
+	stats, err := s.traitUsecase.GetCollectionTraits(ctx, req.P, req.Tick)
+	if err != nil {
+		return nil, err
+	}
+	reply := &pb.GetCollectionTraitsReply{
+		Data:   make([]*pb.TraitStat, 0, len(stats)),
+		Supply: collection.Supply,
+	}
+	for _, stat := range stats {
+		reply.Data = append(reply.Data, &pb.TraitStat{
+			TraitType:   stat.TraitType,
+			Value:       stat.Value,
+			Count:       uint64(stat.Count),
+			Frequency:   stat.Frequency,
+			RarityScore: stat.RarityScore,
+		})
+	}
+	return reply, nil
+}
+
 func (s *CollectionService) fromBizCollection(collection *biz.Collection) *pb.CollectionMessage {
 	m := &pb.CollectionMessage{
 		P:              collection.P,
@@ -103,6 +175,8 @@ func (s *CollectionService) fromBizCollection(collection *biz.Collection) *pb.Co
 		Address:        collection.Address,
 		InscriptionId:  collection.InscriptionID,
 		InscriptionUid: collection.InscriptionUID,
+		Network:        collection.Network,
+		ChildMints:     collection.ChildMints,
 	}
 	for _, attr := range collection.Attributes {
 		at, _ := structpb.NewStruct(attr)
@@ -116,6 +190,7 @@ func (s *CollectionService) fromBizCollection(collection *biz.Collection) *pb.Co
 		for _, v := range collection.Sig.Fields {
 			sig.Fields = append(sig.Fields, string(v))
 		}
+		m.Sig = sig
 	}
 	return m
 }
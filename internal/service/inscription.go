@@ -2,8 +2,14 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	nethttp "net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/adshao/ordinals-indexer/api/inscription/v1"
@@ -15,21 +21,37 @@ type InscriptionService struct {
 	pb.UnimplementedInscriptionServer
 
 	p           page.PageParser
+	network     *biz.Network
 	inscription *biz.InscriptionUsecase
+	content     *biz.ContentUsecase
 	log         *log.Helper
 }
 
-func NewInscriptionService(p page.PageParser, inscription *biz.InscriptionUsecase, logger log.Logger) *InscriptionService {
+func NewInscriptionService(p page.PageParser, network *biz.Network, inscription *biz.InscriptionUsecase, content *biz.ContentUsecase, logger log.Logger) *InscriptionService {
 	return &InscriptionService{
 		p:           p,
+		network:     network,
 		inscription: inscription,
+		content:     content,
 		log:         log.NewHelper(logger),
 	}
 }
 
+// checkNetwork checks the request is of the ord network, the only network the
+// ord server reads the inscriptions from.
+func (s *InscriptionService) checkNetwork(ctx context.Context) error {
+	if network, ok := biz.NetworkFromContext(ctx); ok && network != s.network.Name {
+		return pb.ErrorInvalidParameters("inscriptions of %s are not served", network)
+	}
+	return nil
+}
+
 func (s *InscriptionService) GetInscription(ctx context.Context, req *pb.GetInscriptionRequest) (*pb.GetInscriptionReply, error) {
+	if err := s.checkNetwork(ctx); err != nil {
+		return nil, err
+	}
 	inscriptionPage := page.NewInscriptionPage(req.InscriptionUid)
-	res, err := s.p.Parse(inscriptionPage)
+	res, err := s.p.Parse(ctx, inscriptionPage)
 	if err != nil {
 		return nil, err
 	}
@@ -50,56 +72,117 @@ func (s *InscriptionService) GetInscription(ctx context.Context, req *pb.GetInsc
 			Location:      inscription.Location,
 			Output:        inscription.Output,
 			Offset:        inscription.Offset,
+			Network:       s.network.Name,
+			Sat:           inscription.Sat,
+			SatRarity:     inscription.SatRarity,
+			Cursed:        inscription.Cursed,
+			ParentUid:     inscription.ParentUID,
 		},
 	}, nil
 }
 
+// ListInscription lists the indexed inscriptions from the database, the pages are
+// ordered by the inscription id and the cursors are stable while new inscriptions
+// are indexed.
 func (s *InscriptionService) ListInscription(ctx context.Context, req *pb.ListInscriptionRequest) (*pb.ListInscriptionReply, error) {
-	var inscriptionsPage *page.InscriptionsPage
-	if req.InscriptionId != nil {
-		inscriptionsPage = page.NewInscriptionsPage(*req.InscriptionId)
-	} else {
-		inscriptionsPage = page.NewInscriptionsPage()
+	opt := &biz.InscriptionListOption{
+		Limit:             int(req.Limit),
+		Address:           req.Address,
+		ContentType:       req.ContentType,
+		GenesisHeightFrom: req.GenesisHeightFrom,
+		GenesisHeightTo:   req.GenesisHeightTo,
+		GenesisFeeFrom:    req.GenesisFeeFrom,
+		GenesisFeeTo:      req.GenesisFeeTo,
+		Cursor:            req.InscriptionId,
+		SatFrom:           req.SatFrom,
+		SatTo:             req.SatTo,
+		Cursed:            req.Cursed,
+	}
+	for _, rarity := range req.SatRarity {
+		if _, err := biz.ParseSatRarity(rarity); err != nil {
+			return nil, pb.ErrorInvalidParameters("invalid sat_rarity: %s", rarity)
+		}
+		opt.SatRarity = append(opt.SatRarity, rarity)
+	}
+	if req.TimestampFrom != 0 {
+		opt.TimestampFrom = time.Unix(req.TimestampFrom, 0)
+	}
+	if req.TimestampTo != 0 {
+		opt.TimestampTo = time.Unix(req.TimestampTo, 0)
 	}
-	res, err := s.p.Parse(inscriptionsPage)
+	switch req.OrderBy {
+	case "", "-inscription_id":
+		opt.Desc = true
+	case "inscription_id":
+	default:
+		return nil, pb.ErrorInvalidParameters("invalid order_by: %s", req.OrderBy)
+	}
+	if req.Cursor != "" {
+		cursor, err := decodeInscriptionCursor(req.Cursor)
+		if err != nil {
+			return nil, pb.ErrorInvalidParameters("invalid cursor: %s", req.Cursor)
+		}
+		opt.Cursor = &cursor
+	}
+	return s.listInscriptionsPage(ctx, opt)
+}
+
+// ListInscriptionChildren lists the indexed children of the inscription in the order
+// of the inscription id.
+func (s *InscriptionService) ListInscriptionChildren(ctx context.Context, req *pb.ListInscriptionChildrenRequest) (*pb.ListInscriptionReply, error) {
+	parent, err := s.inscription.FindByUID(ctx, req.InscriptionUid)
 	if err != nil {
 		return nil, err
 	}
-	inscriptions := res.(*page.Inscriptions)
-	var data []*pb.InscriptionMessage
-	for _, uid := range inscriptions.UIDs {
-		inscriptionPage := page.NewInscriptionPage(uid)
-		res, err := s.p.Parse(inscriptionPage)
+	if parent == nil {
+		return nil, pb.ErrorInscriptionNotFound("inscription not found: %s", req.InscriptionUid)
+	}
+	opt := &biz.InscriptionListOption{
+		Limit:     int(req.Limit),
+		ParentUID: parent.UID,
+	}
+	if req.Cursor != "" {
+		cursor, err := decodeInscriptionCursor(req.Cursor)
 		if err != nil {
-			return nil, err
+			return nil, pb.ErrorInvalidParameters("invalid cursor: %s", req.Cursor)
 		}
-		inscription := res.(*page.Inscription)
-		data = append(data, &pb.InscriptionMessage{
-			Id:            inscription.ID,
-			InscriptionId: inscription.ID,
-			Uid:           inscription.UID,
-			Address:       inscription.Address,
-			OutputValue:   inscription.OutputValue,
-			ContentLength: inscription.ContentLength,
-			ContentType:   inscription.ContentType,
-			Timestamp:     timestamppb.New(inscription.Timestamp),
-			GenesisHeight: inscription.GenesisHeight,
-			GenesisFee:    inscription.GenesisFee,
-			GenesisTx:     inscription.GenesisTx,
-			Location:      inscription.Location,
-			Output:        inscription.Output,
-			Offset:        inscription.Offset,
-		})
+		opt.Cursor = &cursor
+	}
+	return s.listInscriptionsPage(ctx, opt)
+}
+
+func (s *InscriptionService) listInscriptionsPage(ctx context.Context, opt *biz.InscriptionListOption) (*pb.ListInscriptionReply, error) {
+	inscriptions, next, err := s.inscription.ListInscriptionsPage(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	var data []*pb.InscriptionMessage
+	for _, inscription := range inscriptions {
+		data = append(data, s.fromBizInscription(inscription))
+	}
+	paging := &pb.Paging{NextId: next}
+	if next != nil {
+		paging.NextCursor = encodeInscriptionCursor(*next)
 	}
 	return &pb.ListInscriptionReply{
-		Data: data,
-		Paging: &pb.Paging{
-			PrevId: inscriptions.PrevID,
-			NextId: inscriptions.NextID,
-		},
+		Data:   data,
+		Paging: paging,
 	}, nil
 }
 
+// encodeInscriptionCursor encodes the inscription id of the next page as an opaque cursor.
+func encodeInscriptionCursor(inscriptionID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(inscriptionID, 10)))
+}
+
+func decodeInscriptionCursor(cursor string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(b), 10, 64)
+}
+
 func (s *InscriptionService) fromBizInscription(inscription *biz.Inscription) *pb.InscriptionMessage {
 	return &pb.InscriptionMessage{
 		Id:            int64(inscription.ID),
@@ -111,10 +194,71 @@ func (s *InscriptionService) fromBizInscription(inscription *biz.Inscription) *p
 		ContentType:   inscription.ContentType,
 		Timestamp:     timestamppb.New(inscription.Timestamp),
 		GenesisHeight: inscription.GenesisHeight,
+		TxIndex:       inscription.TxIndex,
 		GenesisFee:    inscription.GenesisFee,
 		GenesisTx:     inscription.GenesisTx,
 		Location:      inscription.Location,
 		Output:        inscription.Output,
 		Offset:        inscription.Offset,
+		Network:       inscription.Network,
+		Sat:           inscription.Sat,
+		SatRarity:     inscription.SatRarity,
+		Cursed:        inscription.Cursed,
+		ParentUid:     inscription.ParentUID,
+	}
+}
+
+// contentSecurityPolicy keeps the content served from the origin of the api in a
+// sandbox like ord does, the content is loaded from itself or inline only. The scripts
+// of the content run in an opaque origin, without allow-same-origin, so they can't
+// read the api, eg: the admin routes, as the origin of the api.
+const contentSecurityPolicy = "sandbox allow-scripts; default-src 'self' 'unsafe-eval' 'unsafe-inline' data: blob:"
+
+// GetInscriptionContent serves the content of the indexed inscription from the ord
+// server with its content type, the ETag is the sha256 hash of the content, and the
+// range and conditional requests are supported. The content is immutable once inscribed.
+func (s *InscriptionService) GetInscriptionContent(ctx http.Context) error {
+	uid := ctx.Vars().Get("uid")
+	http.SetOperation(ctx, "/api.inscription.v1.Inscription/GetInscriptionContent")
+	h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := s.checkNetwork(ctx); err != nil {
+			return nil, err
+		}
+		inscription, err := s.inscription.FindByUID(ctx, req.(string))
+		if err != nil {
+			return nil, err
+		}
+		if inscription == nil {
+			return nil, pb.ErrorInscriptionNotFound("inscription not found: %s", req)
+		}
+		content, err := s.content.GetContent(ctx, inscription)
+		if errors.Is(err, biz.ErrContentTooLarge) {
+			return nil, pb.ErrorContentTooLarge("%v", err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			return nil, pb.ErrorInscriptionNotFound("inscription not found: %s", req)
+		}
+		return content, nil
+	})
+	out, err := h(ctx, uid)
+	if err != nil {
+		return err
+	}
+	content := out.(*biz.Content)
+	defer content.Body.Close()
+	w := ctx.Response()
+	contentType := content.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
 	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+content.Hash+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	nethttp.ServeContent(w, ctx.Request(), "", time.Time{}, content.Body)
+	return nil
 }
@@ -1,6 +1,22 @@
 package service
 
-import "github.com/google/wire"
+import (
+	"context"
+
+	"github.com/google/wire"
+
+	"github.com/adshao/ordinals-indexer/internal/biz"
+)
 
 // ProviderSet is service providers.
-var ProviderSet = wire.NewSet(NewTokenService, NewCollectionService, NewInscriptionService)
+var ProviderSet = wire.NewSet(NewTokenService, NewCollectionService, NewInscriptionService, NewAdminService)
+
+// validateAddress checks the address is of the network of the request.
+func validateAddress(ctx context.Context, address string) error {
+	name, _ := biz.NetworkFromContext(ctx)
+	network, err := biz.ParseNetwork(name)
+	if err != nil {
+		return err
+	}
+	return network.ValidateAddress(address)
+}
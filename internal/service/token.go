@@ -2,28 +2,41 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/adshao/ordinals-indexer/api/token/v1"
 	"github.com/adshao/ordinals-indexer/internal/biz"
+	"github.com/adshao/ordinals-indexer/internal/ord"
 	"github.com/adshao/ordinals-indexer/internal/ord/page"
 )
 
+// defaultHolderLimit is the max number of the holders in a page.
+const defaultHolderLimit = 1000
+
 type TokenService struct {
 	pb.UnimplementedTokenServer
 
-	p            page.PageParser
-	tokenUsecase *biz.TokenUsecase
-	log          *log.Helper
+	p                 page.PageParser
+	tokenUsecase      *biz.TokenUsecase
+	traitUsecase      *biz.TraitUsecase
+	collectionUsecase *biz.CollectionUsecase
+	syncer            *ord.Syncer
+	log               *log.Helper
 }
 
-func NewTokenService(p page.PageParser, tokenUsecase *biz.TokenUsecase, logger log.Logger) *TokenService {
+func NewTokenService(p page.PageParser, tokenUsecase *biz.TokenUsecase, traitUsecase *biz.TraitUsecase, collectionUsecase *biz.CollectionUsecase, syncer *ord.Syncer, logger log.Logger) *TokenService {
 	return &TokenService{
-		p:            p,
-		tokenUsecase: tokenUsecase,
-		log:          log.NewHelper(logger),
+		p:                 p,
+		tokenUsecase:      tokenUsecase,
+		traitUsecase:      traitUsecase,
+		collectionUsecase: collectionUsecase,
+		syncer:            syncer,
+		log:               log.NewHelper(logger),
 	}
 }
 
@@ -38,8 +51,12 @@ func (s *TokenService) GetToken(ctx context.Context, req *pb.GetTokenRequest) (*
 	if token == nil {
 		return nil, pb.ErrorTokenNotFound("token not found: %d", req.TokenId)
 	}
+	data, err := s.fromBizTokens(ctx, []*biz.Token{token})
+	if err != nil {
+		return nil, err
+	}
 	return &pb.TokenReply{
-		Data: s.fromBizToken(token),
+		Data: data[0],
 	}, nil
 }
 
@@ -51,18 +68,38 @@ func (s *TokenService) GetInscriptionToken(ctx context.Context, req *pb.GetInscr
 	if len(tokens) == 0 {
 		return nil, pb.ErrorTokenNotFound("token not found by inscription id: %d", req.InscriptionId)
 	}
+	data, err := s.fromBizTokens(ctx, tokens[:1])
+	if err != nil {
+		return nil, err
+	}
 	return &pb.TokenReply{
-		Data: s.fromBizToken(tokens[0]),
+		Data: data[0],
 	}, nil
 }
 
 func (s *TokenService) ListTokens(ctx context.Context, req *pb.ListTokenRequest) (*pb.ListTokenReply, error) {
+	traits, err := parseTraits(req.Traits)
+	if err != nil {
+		return nil, err
+	}
+	if req.SigReceiver != "" {
+		if err := validateAddress(ctx, req.SigReceiver); err != nil {
+			return nil, pb.ErrorInvalidParameters("invalid sig_receiver: %v", err)
+		}
+	}
 	opt := &biz.TokenListOption{
-		Limit:  int(req.Limit),
-		Offset: int(req.Offset),
-		P:      req.P,
-		Tick:   req.Tick,
-		Order:  req.OrderBy,
+		Limit:                int(req.Limit),
+		Offset:               int(req.Offset),
+		P:                    req.P,
+		Tick:                 req.Tick,
+		Order:                req.OrderBy,
+		Traits:               traits,
+		SigUID:               req.SigUid,
+		SigReceiver:          req.SigReceiver,
+		SigExpiredTimeFrom:   req.SigExptFrom,
+		SigExpiredTimeTo:     req.SigExptTo,
+		SigExpiredHeightFrom: req.SigExphFrom,
+		SigExpiredHeightTo:   req.SigExphTo,
 	}
 	tokens, err := s.tokenUsecase.ListTokens(ctx, opt)
 	if err != nil {
@@ -72,9 +109,9 @@ func (s *TokenService) ListTokens(ctx context.Context, req *pb.ListTokenRequest)
 	if err != nil {
 		return nil, err
 	}
-	var data []*pb.TokenMessage
-	for _, token := range tokens {
-		data = append(data, s.fromBizToken(token))
+	data, err := s.fromBizTokens(ctx, tokens)
+	if err != nil {
+		return nil, err
 	}
 	paging := &pb.Paging{
 		TotalCount: uint64(totalCount),
@@ -86,6 +123,157 @@ func (s *TokenService) ListTokens(ctx context.Context, req *pb.ListTokenRequest)
 	}, nil
 }
 
+func (s *TokenService) GetHoldersAtHeight(ctx context.Context, req *pb.GetHoldersAtHeightRequest) (*pb.GetHoldersAtHeightReply, error) {
+	// the holders are grouped in memory, they are listed by collection to bound it.
+	if req.Tick == "" {
+		return nil, pb.ErrorInvalidParameters("missing tick")
+	}
+	holders, err := s.syncer.ListHolders(ctx, &biz.HolderListOption{
+		P:        req.P,
+		Tick:     req.Tick,
+		Height:   req.BlockHeight,
+		MinCount: int(req.MinCount),
+	})
+	if errors.Is(err, ord.ErrTransfersNotIndexed) {
+		return nil, pb.ErrorTransfersNotIndexed("%v", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	totalCount := len(holders)
+	limit := int(req.Limit)
+	if limit <= 0 || limit > defaultHolderLimit {
+		limit = defaultHolderLimit
+	}
+	if req.Offset >= uint64(len(holders)) {
+		holders = nil
+	} else {
+		holders = holders[req.Offset:]
+	}
+	if len(holders) > limit {
+		holders = holders[:limit]
+	}
+	network, _ := biz.NetworkFromContext(ctx)
+	data := make([]*pb.HolderMessage, 0, len(holders))
+	for _, h := range holders {
+		data = append(data, &pb.HolderMessage{
+			P:        h.P,
+			Tick:     h.Tick,
+			Address:  h.Address,
+			Count:    uint64(h.Count),
+			TokenIds: h.TokenIDs,
+			Network:  network,
+		})
+	}
+	return &pb.GetHoldersAtHeightReply{
+		Data: data,
+		Paging: &pb.Paging{
+			TotalCount: uint64(totalCount),
+			Count:      uint64(len(data)),
+		},
+	}, nil
+}
+
+func (s *TokenService) VerifyMintSig(ctx context.Context, req *pb.VerifyMintSigRequest) (*pb.VerifyMintSigReply, error) {
+	mint := s.syncer.ParseMint([]byte(req.Mint))
+	if mint == nil {
+		return nil, pb.ErrorInvalidParameters("invalid mint: %s", req.Mint)
+	}
+	if req.Receiver == "" || req.BlockHeight == 0 {
+		return nil, pb.ErrorInvalidParameters("missing receiver or block_height")
+	}
+	if err := validateAddress(ctx, req.Receiver); err != nil {
+		return nil, pb.ErrorInvalidParameters("invalid receiver: %v", err)
+	}
+	blockTime := time.Now()
+	if req.BlockTime != 0 {
+		blockTime = time.Unix(req.BlockTime, 0)
+	}
+	res, err := s.syncer.VerifyMint(ctx, &ord.MintCandidate{
+		Mint:        mint,
+		Receiver:    req.Receiver,
+		BlockHeight: req.BlockHeight,
+		BlockTime:   blockTime,
+		ParentUID:   req.ParentUid,
+		Cursed:      req.Cursed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, pb.ErrorCollectionNotFound("collection not found: %s", mint.Tick)
+	}
+	report := res.Sig
+	reply := &pb.VerifyMintSigReply{
+		Required:    report.Required,
+		Valid:       report.Valid,
+		Eligible:    res.Eligible,
+		Verdicts:    fromBizVerdicts(report.Verdicts),
+		Eligibility: fromBizVerdicts(res.Eligibility),
+	}
+	if report.Sig != nil {
+		reply.Sig = &pb.MintSig{
+			S:    report.Sig.Signature,
+			Rec:  report.Sig.Receiver,
+			Uid:  report.Sig.Uid,
+			Expt: report.Sig.ExpiredTime,
+			Exph: report.Sig.ExpiredHeight,
+		}
+	}
+	return reply, nil
+}
+
+func fromBizVerdicts(verdicts []*biz.MintSigVerdict) []*pb.MintSigVerdict {
+	ret := make([]*pb.MintSigVerdict, 0, len(verdicts))
+	for _, v := range verdicts {
+		ret = append(ret, &pb.MintSigVerdict{
+			Field:  v.Field,
+			Valid:  v.Valid,
+			Reason: v.Reason,
+		})
+	}
+	return ret
+}
+
+// parseTraits parses the trait filters in trait_type:value format.
+func parseTraits(filters []string) ([]*biz.Trait, error) {
+	traits := make([]*biz.Trait, 0, len(filters))
+	for _, filter := range filters {
+		traitType, value, ok := strings.Cut(filter, ":")
+		if !ok || traitType == "" {
+			return nil, pb.ErrorInvalidParameters("invalid trait: %s, eg: eyes:blue", filter)
+		}
+		traits = append(traits, &biz.Trait{TraitType: traitType, Value: value})
+	}
+	return traits, nil
+}
+
+// fromBizTokens converts the tokens with their traits and rarity.
+func (s *TokenService) fromBizTokens(ctx context.Context, tokens []*biz.Token) ([]*pb.TokenMessage, error) {
+	rarities, err := s.traitUsecase.GetTokensRarity(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	var data []*pb.TokenMessage
+	for _, token := range tokens {
+		t := s.fromBizToken(token)
+		if rarity, ok := rarities[token.ID]; ok {
+			for _, stat := range rarity.Traits {
+				t.Traits = append(t.Traits, &pb.TraitStat{
+					TraitType:   stat.TraitType,
+					Value:       stat.Value,
+					Count:       uint64(stat.Count),
+					Frequency:   stat.Frequency,
+					RarityScore: stat.RarityScore,
+				})
+			}
+			t.RarityScore = rarity.RarityScore
+		}
+		data = append(data, t)
+	}
+	return data, nil
+}
+
 func (s *TokenService) fromBizToken(token *biz.Token) *pb.TokenMessage {
 	t := &pb.TokenMessage{
 		P:              token.P,
@@ -97,6 +285,7 @@ func (s *TokenService) fromBizToken(token *biz.Token) *pb.TokenMessage {
 		Address:        token.Address,
 		InscriptionId:  token.InscriptionID,
 		InscriptionUid: token.InscriptionUID,
+		Network:        token.Network,
 	}
 	if token.Sig.Signature != "" {
 		t.Sig = &pb.MintSig{